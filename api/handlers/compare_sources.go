@@ -0,0 +1,225 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"ness-to-odoo-golang-validation-api-tool/api/middleware"
+	"ness-to-odoo-golang-validation-api-tool/api/services"
+	"ness-to-odoo-golang-validation-api-tool/utils"
+)
+
+// CompareSources godoc
+// @Summary Compare emails across N labelled sources
+// @Description Upload two or more files and get a membership matrix showing which sources contain each email, compared on the key of the comparison strategy, with Venn-style counts for every combination of sources
+// @Tags emails
+// @Accept multipart/form-data
+// @Produce octet-stream
+// @Param files formData file true "File containing emails (CSV, TSV, Excel, JSON, NDJSON, text or ZIP); repeat the field once per source"
+// @Param labels formData string false "Source label, repeated in the same order as files (default: file name without extension)"
+// @Param columns formData string false "Email column per source, repeated in the same order as files: header name, letter or 1-based index"
+// @Param sheets formData string false "Worksheet per Excel source, repeated in the same order as files: name or 1-based index"
+// @Param jsonPaths formData string false "Path of the emails per JSON or NDJSON source, repeated in the same order as files, e.g. $.contacts[*].email"
+// @Param delimiters formData string false "Field delimiter per CSV or TSV source, repeated in the same order as files: a character or comma, semicolon, tab or pipe (default: detected)"
+// @Param quotes formData string false "Quote style per CSV or TSV source, repeated in the same order as files: double or none (default: detected)"
+// @Param encodings formData string false "Character encoding per CSV, TSV or text source, repeated in the same order as files, e.g. utf-8, utf-16le or windows-1258 (default: detected)"
+// @Param hasHeaders formData string false "Whether the first row of each CSV, TSV or Excel source (after skipped rows) is a header, repeated in the same order as files: true, false or auto (default: auto)"
+// @Param skipRows formData int false "Leading rows of each CSV, TSV or Excel source to ignore before the header, repeated in the same order as files"
+// @Param lenient formData bool false "Skip malformed rows of CSV and TSV sources and list them as parse errors instead of failing (default: false)"
+// @Param maxParseErrors formData int false "Malformed rows allowed per source in lenient mode before the comparison fails (default: server configuration)"
+// @Param outputFormat formData string false "Output format: csv, excel, json, ndjson, markdown, html, odoo-csv or odoo-xml (default: csv). The Odoo formats create the emails missing in the last source"
+// @Param odooMapping formData string false "Column mapping of the odoo-csv and odoo-xml formats, read from every source but the last, e.g. name=Full Name,phone=Mobile (default: server configuration)"
+// @Param comparison formData string false "Key the emails are compared on: raw, case-insensitive, normalized, domain or registrable-domain (default: normalized)"
+// @Param timeoutSeconds formData int false "Per-request deadline in seconds (capped by the server configuration)"
+// @Param callbackUrl formData string false "URL that receives a signed JSON webhook when the comparison completes or fails (default: the URL configured in API_KEY_WEBHOOKS for the X-API-Key header)"
+// @Success 200 {file} file
+// @Header 200 {string} X-Run-ID "ID of the recorded run, when history is enabled"
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 413 {object} middleware.ErrorResponse
+// @Failure 415 {object} middleware.ErrorResponse
+// @Failure 422 {object} middleware.ErrorResponse
+// @Failure 499 {object} middleware.ErrorResponse
+// @Failure 500 {object} middleware.ErrorResponse
+// @Failure 504 {object} middleware.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /compare-sources [post]
+func CompareSources(c *gin.Context) {
+	logger := utils.GetLogger()
+	defer utils.LogExecutionTime("CompareSources handler")()
+	logger.Info("Processing source comparison request")
+
+	form, err := c.MultipartForm()
+	if err != nil {
+		logger.Warn("Invalid multipart form: %v", err)
+		respondError(c, http.StatusBadRequest, services.CodeInvalidRequest, "A multipart form with files is required")
+		return
+	}
+
+	files := form.File["files"]
+	if len(files) < services.MinSources || len(files) > services.MaxSources {
+		logger.Warn("Invalid number of sources: %d", len(files))
+		respondError(c, http.StatusBadRequest, services.CodeInvalidRequest, fmt.Sprintf("Between %d and %d files are required", services.MinSources, services.MaxSources))
+		return
+	}
+
+	labels := form.Value["labels"]
+	columns := form.Value["columns"]
+	sheets := form.Value["sheets"]
+	jsonPaths := form.Value["jsonPaths"]
+	delimiters := form.Value["delimiters"]
+	quotes := form.Value["quotes"]
+	encodings := form.Value["encodings"]
+	hasHeaders := form.Value["hasHeaders"]
+	skipRows := form.Value["skipRows"]
+	for _, values := range [][]string{labels, columns, sheets, jsonPaths, delimiters, quotes, encodings, hasHeaders, skipRows} {
+		if len(values) > len(files) {
+			respondError(c, http.StatusBadRequest, services.CodeInvalidRequest, "labels, columns, sheets, jsonPaths, delimiters, quotes, encodings, hasHeaders and skipRows cannot have more values than files")
+			return
+		}
+	}
+
+	// Get output format (default to CSV)
+	outputFormat := c.DefaultPostForm("outputFormat", "csv")
+	if _, err := services.GetReporter(outputFormat); err != nil {
+		logger.Warn("Invalid output format: %s", outputFormat)
+		respondError(c, http.StatusBadRequest, services.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// An Odoo mapping given with the request replaces the configured one
+	var odooMapping services.OdooMapping
+	if spec := c.PostForm("odooMapping"); spec != "" {
+		if odooMapping, err = services.ParseOdooMapping(spec); err != nil {
+			logger.Warn("Invalid Odoo mapping: %v", err)
+			respondError(c, http.StatusBadRequest, services.CodeInvalidRequest, err.Error())
+			return
+		}
+	}
+
+	comparison, err := services.ParseComparisonStrategy(c.PostForm("comparison"))
+	if err != nil {
+		logger.Warn("Invalid comparison strategy: %v", err)
+		respondError(c, http.StatusBadRequest, services.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// A callback URL given with the request replaces the one configured for the API key
+	callbackURL, err := services.ParseCallbackURL(c.PostForm("callbackUrl"))
+	if err != nil {
+		logger.Warn("Invalid callback URL: %v", err)
+		respondError(c, http.StatusBadRequest, services.CodeInvalidRequest, err.Error())
+		return
+	}
+	if callbackURL == "" {
+		callbackURL = services.CallbackURLForAPIKey(c.GetHeader(middleware.APIKeyHeader))
+	}
+
+	// Apply the per-request deadline on top of the server-wide one
+	ctx, cancel, err := requestContext(c)
+	if err != nil {
+		logger.Warn("Invalid timeout: %v", err)
+		respondError(c, http.StatusBadRequest, services.CodeInvalidRequest, err.Error())
+		return
+	}
+	defer cancel()
+
+	// Check options and labels before saving anything; formats are checked when the files are read
+	seenLabels := make(map[string]bool, len(files))
+	sourceLabels := make([]string, len(files))
+	sourceOptions := make([]services.ExtractOptions, len(files))
+	for i, file := range files {
+		sourceOptions[i] = services.ExtractOptions{
+			Column:    valueAt(columns, i),
+			Sheet:     valueAt(sheets, i),
+			JSONPath:  valueAt(jsonPaths, i),
+			Delimiter: valueAt(delimiters, i),
+			Quote:     valueAt(quotes, i),
+			Encoding:  valueAt(encodings, i),
+		}
+		err := parseLenientOptions(c.PostForm("lenient"), c.PostForm("maxParseErrors"), &sourceOptions[i])
+		if err == nil {
+			err = parseTableOptions(&sourceOptions[i], valueAt(hasHeaders, i), valueAt(skipRows, i))
+		}
+		if err == nil {
+			err = services.ValidateDialectOptions(sourceOptions[i])
+		}
+		if err == nil {
+			err = services.ValidateJSONPath(sourceOptions[i].JSONPath)
+		}
+		if err != nil {
+			logger.Warn("Invalid options for %s: %v", file.Filename, err)
+			respondError(c, http.StatusBadRequest, services.CodeInvalidRequest, fmt.Sprintf("%s: %v", file.Filename, err))
+			return
+		}
+
+		label := strings.TrimSuffix(filepath.Base(file.Filename), filepath.Ext(file.Filename))
+		if value := strings.TrimSpace(valueAt(labels, i)); value != "" {
+			label = value
+		}
+		if seenLabels[label] {
+			respondError(c, http.StatusBadRequest, services.CodeInvalidRequest, fmt.Sprintf("Duplicate source label %q", label))
+			return
+		}
+		seenLabels[label] = true
+		sourceLabels[i] = label
+	}
+
+	uploadDir, err := createUploadDir()
+	if err != nil {
+		logger.Error("Failed to create upload directory: %v", err)
+		respondError(c, http.StatusInternalServerError, services.CodeInternal, "Failed to save uploaded files")
+		return
+	}
+	defer removeUploadDir(uploadDir)
+	sources := make([]services.Source, len(files))
+	for i, file := range files {
+		// One directory per source, as several sources may be uploaded with the same name
+		filePath := filepath.Join(uploadDir, fmt.Sprintf("source%d", i+1), filepath.Base(file.Filename))
+		startTime := time.Now()
+		if err := c.SaveUploadedFile(file, filePath); err != nil {
+			logger.Error("Failed to save file %s: %v", file.Filename, err)
+			respondError(c, http.StatusInternalServerError, services.CodeInternal, fmt.Sprintf("Failed to save file %s", file.Filename))
+			return
+		}
+		logger.Debug("File %s saved in %s", file.Filename, utils.FormatDuration(time.Since(startTime)))
+
+		sources[i] = services.Source{
+			Label:    sourceLabels[i],
+			Path:     filePath,
+			FileName: filepath.Base(file.Filename),
+			Options:  sourceOptions[i],
+		}
+	}
+
+	startTime := time.Now()
+	opts := services.MultiSourceOptions{
+		OutputFormat: outputFormat,
+		Comparison:   comparison,
+		OdooMapping:  odooMapping,
+		CallbackURL:  callbackURL,
+	}
+	result, err := services.CompareSources(ctx, sources, opts)
+	if err != nil {
+		respondOperationError(c, "Source comparison", err)
+		return
+	}
+	logger.Info("Source comparison completed in %s: %d unique emails across %d sources",
+		utils.FormatDuration(time.Since(startTime)), result.Summary.UniqueEmails, len(result.Sources))
+
+	if result.RunID != "" {
+		c.Header("X-Run-ID", result.RunID)
+	}
+	sendReportFile(c, result.FileName)
+}
+
+// valueAt returns values[i], or an empty string when the value was not provided
+func valueAt(values []string, i int) string {
+	if i < len(values) {
+		return values[i]
+	}
+	return ""
+}
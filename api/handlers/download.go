@@ -7,6 +7,8 @@ import (
 	"path/filepath"
 
 	"github.com/gin-gonic/gin"
+	"ness-to-odoo-golang-validation-api-tool/api/services"
+	"ness-to-odoo-golang-validation-api-tool/config"
 )
 
 // DownloadFile godoc
@@ -16,40 +18,104 @@ import (
 // @Produce octet-stream
 // @Param filename path string true "File name"
 // @Success 200 {file} file
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 404 {object} middleware.ErrorResponse
+// @Failure 500 {object} middleware.ErrorResponse
+// @Security ApiKeyAuth
 // @Router /download/{filename} [get]
 func DownloadFile(c *gin.Context) {
 	filename := c.Param("filename")
-	
+
 	// Validate filename to prevent directory traversal
 	if filepath.Base(filename) != filename {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid filename"})
+		respondError(c, http.StatusBadRequest, services.CodeInvalidRequest, "Invalid filename")
+		return
+	}
+
+	sendReportFile(c, filename)
+}
+
+// ViewReport godoc
+// @Summary View an HTML report
+// @Description Show an HTML report generated by the validation process in the browser instead of downloading it
+// @Tags files
+// @Produce html
+// @Param filename path string true "File name of an HTML report"
+// @Param expires query int false "Expiry of a signed view link, in Unix seconds"
+// @Param signature query string false "Signature of a signed view link, which replaces the API key"
+// @Success 200 {file} file
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 401 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /view/{filename} [get]
+func ViewReport(c *gin.Context) {
+	filename := c.Param("filename")
+	filePath, ok := viewableReport(c, filename)
+	if !ok {
+		return
+	}
+
+	c.Header("Content-Type", services.ReportContentType(filename))
+	c.File(filePath)
+}
+
+// CreateViewLink godoc
+// @Summary Create a view link for an HTML report
+// @Description Create a signed link that opens an HTML report in the browser without the X-API-Key header until it expires
+// @Tags files
+// @Produce json
+// @Param filename path string true "File name of an HTML report"
+// @Success 200 {object} services.ViewLink
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /view-links/{filename} [get]
+func CreateViewLink(c *gin.Context) {
+	filename := c.Param("filename")
+	if _, ok := viewableReport(c, filename); !ok {
 		return
 	}
-	
-	filePath := filepath.Join("./temp", filename)
-	
+
+	c.JSON(http.StatusOK, services.NewViewLink(filename))
+}
+
+// viewableReport returns the path of the HTML report filename, responding with an error and
+// returning false when there is no such report
+func viewableReport(c *gin.Context, filename string) (string, bool) {
+	// Validate filename to prevent directory traversal
+	if filepath.Base(filename) != filename {
+		respondError(c, http.StatusBadRequest, services.CodeInvalidRequest, "Invalid filename")
+		return "", false
+	}
+	if format, _ := services.ReportFormatForExtension(filepath.Ext(filename)); format != "html" {
+		respondError(c, http.StatusBadRequest, services.CodeInvalidRequest, "Only HTML reports can be viewed")
+		return "", false
+	}
+
+	filePath := filepath.Join(config.Get().TempDir, filename)
+	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+		respondError(c, http.StatusNotFound, services.CodeNotFound, "File not found")
+		return "", false
+	}
+	return filePath, true
+}
+
+// sendReportFile sends a generated file from the temp directory as an attachment
+func sendReportFile(c *gin.Context, filename string) {
+	sendFile(c, filepath.Join(config.Get().TempDir, filename), filename)
+}
+
+// sendFile sends the file at filePath as an attachment named filename
+func sendFile(c *gin.Context, filePath, filename string) {
 	// Check if file exists
 	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+		respondError(c, http.StatusNotFound, services.CodeNotFound, "File not found")
 		return
 	}
-	
-	// Set appropriate content type based on file extension
-	ext := filepath.Ext(filename)
-	contentType := "application/octet-stream"
-	
-	switch ext {
-	case ".csv":
-		contentType = "text/csv"
-	case ".xlsx", ".xls":
-		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
-	}
-	
+
 	c.Header("Content-Description", "File Transfer")
 	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
-	c.Header("Content-Type", contentType)
-	
+	c.Header("Content-Type", services.ReportContentType(filename))
+
 	c.File(filePath)
 }
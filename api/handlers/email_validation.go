@@ -1,16 +1,24 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"ness-to-odoo-golang-validation-api-tool/api/middleware"
 	"ness-to-odoo-golang-validation-api-tool/api/services"
+	"ness-to-odoo-golang-validation-api-tool/config"
 	"ness-to-odoo-golang-validation-api-tool/utils"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
 	"time"
 )
 
+// statusClientClosedRequest is the non-standard status used when the client went away
+const statusClientClosedRequest = 499
+
 // ValidateEmailsRequest represents the request structure for email validation
 type ValidateEmailsRequest struct {
 	// No body parameters as we're using multipart form
@@ -21,8 +29,12 @@ type ValidationResult struct {
 	MatchingEmails      []string                   `json:"matchingEmails"`
 	MissingInFirstFile  []string                   `json:"missingInFirstFile"`
 	MissingInSecondFile []string                   `json:"missingInSecondFile"`
+	KeyMatches          []services.KeyMatch        `json:"keyMatches"`
+	ProbableMatches     []services.ProbableMatch   `json:"probableMatches"`
 	OutputFileURL       string                     `json:"outputFileURL"`
 	Summary             services.ValidationSummary `json:"summary"`
+	Inputs              []services.InputFile       `json:"inputs"`
+	ParseErrors         []services.ParseError      `json:"parseErrors"`
 }
 
 // ValidateEmails godoc
@@ -31,12 +43,43 @@ type ValidationResult struct {
 // @Tags emails
 // @Accept multipart/form-data
 // @Produce json
-// @Param firstFile formData file true "First CSV/Excel file containing emails"
-// @Param secondFile formData file true "Second CSV/Excel file containing emails"
-// @Param outputFormat formData string false "Output format (csv or excel, default: csv)"
+// @Param firstFile formData file true "First file containing emails: CSV, TSV, Excel, JSON, NDJSON, text or a ZIP archive of those"
+// @Param secondFile formData file true "Second file containing emails, in any of the formats of firstFile"
+// @Param outputFormat formData string false "Output format: csv, excel, json, ndjson, markdown, html, odoo-csv or odoo-xml (default: csv)"
+// @Param odooMapping formData string false "Column mapping of the odoo-csv and odoo-xml formats, e.g. name=Full Name,phone=Mobile (default: server configuration)"
+// @Param firstColumn formData string false "Email column of the first file: header name, letter or 1-based index (default: first column)"
+// @Param firstSheet formData string false "Worksheet of the first file when it is an Excel file: name or 1-based index (default: first sheet)"
+// @Param secondColumn formData string false "Email column of the second file: header name, letter or 1-based index (default: first column)"
+// @Param secondSheet formData string false "Worksheet of the second file when it is an Excel file: name or 1-based index (default: first sheet)"
+// @Param firstJsonPath formData string false "Path of the emails in the first file when it is a JSON or NDJSON file, e.g. $.contacts[*].email (default: the firstColumn key, else every string containing @)"
+// @Param secondJsonPath formData string false "Path of the emails in the second file when it is a JSON or NDJSON file"
+// @Param firstDelimiter formData string false "Field delimiter of the first file when it is a CSV or TSV file: a character or comma, semicolon, tab or pipe (default: detected)"
+// @Param firstQuote formData string false "Quote style of the first file when it is a CSV or TSV file: double or none (default: detected)"
+// @Param firstEncoding formData string false "Character encoding of the first file when it is a CSV, TSV or text file, e.g. utf-8, utf-16le or windows-1258 (default: detected)"
+// @Param secondDelimiter formData string false "Field delimiter of the second file when it is a CSV or TSV file (default: detected)"
+// @Param secondQuote formData string false "Quote style of the second file when it is a CSV or TSV file (default: detected)"
+// @Param secondEncoding formData string false "Character encoding of the second file when it is a CSV, TSV or text file (default: detected)"
+// @Param firstHasHeader formData string false "Whether the first row of the first file (after skipped rows) is a header: true, false or auto (default: auto, a header unless the row has an email)"
+// @Param firstSkipRows formData int false "Leading rows of the first file to ignore before the header, e.g. a title banner (CSV, TSV and Excel files)"
+// @Param secondHasHeader formData string false "Whether the first row of the second file (after skipped rows) is a header: true, false or auto"
+// @Param secondSkipRows formData int false "Leading rows of the second file to ignore before the header"
+// @Param lenient formData bool false "Skip malformed rows of CSV and TSV files, such as stray quotes or a wrong number of fields, and list them as parse errors instead of failing (default: false)"
+// @Param maxParseErrors formData int false "Malformed rows allowed per file in lenient mode before the run fails (default: server configuration)"
+// @Param comparison formData string false "Key the emails are compared on: raw, case-insensitive, normalized, domain or registrable-domain (default: normalized)"
+// @Param matchKeys formData string false "Secondary keys that pair records whose email changed, tried in order on the emails that did not match, e.g. Full Name+Phone;Customer Code=ref. Columns are joined with +, keys separated by ; and a column named differently in the second file is given as first=second"
+// @Param probableMatchThreshold formData number false "Similarity from 0 to 1 from which emails missing in either file are paired as probable matches, comparing local parts within the same registrable domain; 0 disables (default: server configuration)"
+// @Param timeoutSeconds formData int false "Per-request deadline in seconds (capped by the server configuration)"
+// @Param callbackUrl formData string false "URL that receives a signed JSON webhook when the run completes or fails (default: the URL configured in API_KEY_WEBHOOKS for the X-API-Key header)"
 // @Success 200 {file} file
-// @Failure 400 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Header 200 {string} X-Run-ID "ID of the recorded run, when history is enabled"
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 413 {object} middleware.ErrorResponse
+// @Failure 415 {object} middleware.ErrorResponse
+// @Failure 422 {object} middleware.ErrorResponse
+// @Failure 499 {object} middleware.ErrorResponse
+// @Failure 500 {object} middleware.ErrorResponse
+// @Failure 504 {object} middleware.ErrorResponse
+// @Security ApiKeyAuth
 // @Router /validate-emails [post]
 func ValidateEmails(c *gin.Context) {
 	logger := utils.GetLogger()
@@ -47,7 +90,7 @@ func ValidateEmails(c *gin.Context) {
 	firstFile, err := c.FormFile("firstFile")
 	if err != nil {
 		logger.Warn("First file is missing from request")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "First file is required"})
+		respondError(c, http.StatusBadRequest, services.CodeInvalidRequest, "First file is required")
 		return
 	}
 	logger.Info("Received first file: %s (size: %.2f MB)", firstFile.Filename, float64(firstFile.Size)/(1024*1024))
@@ -55,7 +98,7 @@ func ValidateEmails(c *gin.Context) {
 	secondFile, err := c.FormFile("secondFile")
 	if err != nil {
 		logger.Warn("Second file is missing from request")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Second file is required"})
+		respondError(c, http.StatusBadRequest, services.CodeInvalidRequest, "Second file is required")
 		return
 	}
 	logger.Info("Received second file: %s (size: %.2f MB)", secondFile.Filename, float64(secondFile.Size)/(1024*1024))
@@ -63,40 +106,120 @@ func ValidateEmails(c *gin.Context) {
 	// Get output format (default to CSV)
 	outputFormat := c.DefaultPostForm("outputFormat", "csv")
 	logger.Info("Output format: %s", outputFormat)
-	if outputFormat != "csv" && outputFormat != "excel" {
+	if _, err := services.GetReporter(outputFormat); err != nil {
 		logger.Warn("Invalid output format: %s", outputFormat)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Output format must be 'csv' or 'excel'"})
+		respondError(c, http.StatusBadRequest, services.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// An Odoo mapping given with the request replaces the configured one
+	var odooMapping services.OdooMapping
+	if spec := c.PostForm("odooMapping"); spec != "" {
+		if odooMapping, err = services.ParseOdooMapping(spec); err != nil {
+			logger.Warn("Invalid Odoo mapping: %v", err)
+			respondError(c, http.StatusBadRequest, services.CodeInvalidRequest, err.Error())
+			return
+		}
+	}
+
+	comparison, err := services.ParseComparisonStrategy(c.PostForm("comparison"))
+	if err != nil {
+		logger.Warn("Invalid comparison strategy: %v", err)
+		respondError(c, http.StatusBadRequest, services.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	matchKeys, err := services.ParseMatchKeys(c.PostForm("matchKeys"))
+	if err != nil {
+		logger.Warn("Invalid match keys: %v", err)
+		respondError(c, http.StatusBadRequest, services.CodeInvalidRequest, err.Error())
 		return
 	}
 
-	// Validate file extensions
-	firstFileExt := filepath.Ext(firstFile.Filename)
-	secondFileExt := filepath.Ext(secondFile.Filename)
-	logger.Debug("File extensions: %s, %s", firstFileExt, secondFileExt)
+	probableMatchThreshold, err := services.ParseProbableMatchThreshold(c.PostForm("probableMatchThreshold"))
+	if err != nil {
+		logger.Warn("Invalid probable match threshold: %v", err)
+		respondError(c, http.StatusBadRequest, services.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// A callback URL given with the request replaces the one configured for the API key
+	callbackURL, err := services.ParseCallbackURL(c.PostForm("callbackUrl"))
+	if err != nil {
+		logger.Warn("Invalid callback URL: %v", err)
+		respondError(c, http.StatusBadRequest, services.CodeInvalidRequest, err.Error())
+		return
+	}
+	if callbackURL == "" {
+		callbackURL = services.CallbackURLForAPIKey(c.GetHeader(middleware.APIKeyHeader))
+	}
 
-	validExts := map[string]bool{
-		".csv":  true,
-		".xlsx": true,
-		".xls":  true,
+	// Read options of each file; the dialect options are checked before the files are saved
+	firstOpts := services.ExtractOptions{
+		Column:    c.PostForm("firstColumn"),
+		Sheet:     c.PostForm("firstSheet"),
+		JSONPath:  c.PostForm("firstJsonPath"),
+		Delimiter: c.PostForm("firstDelimiter"),
+		Quote:     c.PostForm("firstQuote"),
+		Encoding:  c.PostForm("firstEncoding"),
+	}
+	secondOpts := services.ExtractOptions{
+		Column:    c.PostForm("secondColumn"),
+		Sheet:     c.PostForm("secondSheet"),
+		JSONPath:  c.PostForm("secondJsonPath"),
+		Delimiter: c.PostForm("secondDelimiter"),
+		Quote:     c.PostForm("secondQuote"),
+		Encoding:  c.PostForm("secondEncoding"),
+	}
+	if err := parseLenientOptions(c.PostForm("lenient"), c.PostForm("maxParseErrors"), &firstOpts, &secondOpts); err != nil {
+		logger.Warn("Invalid lenient parsing options: %v", err)
+		respondError(c, http.StatusBadRequest, services.CodeInvalidRequest, err.Error())
+		return
+	}
+	for _, file := range []struct {
+		prefix string
+		opts   *services.ExtractOptions
+	}{{"first", &firstOpts}, {"second", &secondOpts}} {
+		err := parseTableOptions(file.opts, c.PostForm(file.prefix+"HasHeader"), c.PostForm(file.prefix+"SkipRows"))
+		if err == nil {
+			err = services.ValidateDialectOptions(*file.opts)
+		}
+		if err == nil {
+			err = services.ValidateJSONPath(file.opts.JSONPath)
+		}
+		if err != nil {
+			logger.Warn("Invalid options of the %s file: %v", file.prefix, err)
+			respondError(c, http.StatusBadRequest, services.CodeInvalidRequest, err.Error())
+			return
+		}
 	}
 
-	if !validExts[firstFileExt] || !validExts[secondFileExt] {
-		logger.Warn("Invalid file format: %s, %s", firstFileExt, secondFileExt)
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid file format. Only CSV and Excel files are supported",
-		})
+	// Apply the per-request deadline on top of the server-wide one
+	ctx, cancel, err := requestContext(c)
+	if err != nil {
+		logger.Warn("Invalid timeout: %v", err)
+		respondError(c, http.StatusBadRequest, services.CodeInvalidRequest, err.Error())
 		return
 	}
+	defer cancel()
 
-	// Save uploaded files temporarily
-	firstFilePath := fmt.Sprintf("./temp/%s", firstFile.Filename)
-	secondFilePath := fmt.Sprintf("./temp/%s", secondFile.Filename)
+	// Save uploaded files temporarily, under their own names in a directory of this request. Their
+	// format is checked when they are read, by extension or else by sniffing the content.
+	uploadDir, err := createUploadDir()
+	if err != nil {
+		logger.Error("Failed to create upload directory: %v", err)
+		respondError(c, http.StatusInternalServerError, services.CodeInternal, "Failed to save uploaded files")
+		return
+	}
+	defer removeUploadDir(uploadDir)
+	firstFilePath := filepath.Join(uploadDir, "first", filepath.Base(firstFile.Filename))
+	secondFilePath := filepath.Join(uploadDir, "second", filepath.Base(secondFile.Filename))
 	logger.Debug("Saving files to: %s, %s", firstFilePath, secondFilePath)
 
 	startTime := time.Now()
 	if err := c.SaveUploadedFile(firstFile, firstFilePath); err != nil {
 		logger.Error("Failed to save first file: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save first file"})
+		respondError(c, http.StatusInternalServerError, services.CodeInternal, "Failed to save first file")
 		return
 	}
 	logger.Debug("First file saved in %s", utils.FormatDuration(time.Since(startTime)))
@@ -104,7 +227,7 @@ func ValidateEmails(c *gin.Context) {
 	startTime = time.Now()
 	if err := c.SaveUploadedFile(secondFile, secondFilePath); err != nil {
 		logger.Error("Failed to save second file: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save second file"})
+		respondError(c, http.StatusInternalServerError, services.CodeInternal, "Failed to save second file")
 		return
 	}
 	logger.Debug("Second file saved in %s", utils.FormatDuration(time.Since(startTime)))
@@ -112,10 +235,19 @@ func ValidateEmails(c *gin.Context) {
 	// Process files and validate emails
 	logger.Info("Starting email validation process")
 	startTime = time.Now()
-	result, err := services.ValidateEmails(firstFilePath, secondFilePath, outputFormat)
+	opts := services.ValidationOptions{
+		OutputFormat:           outputFormat,
+		FirstFile:              firstOpts,
+		SecondFile:             secondOpts,
+		OdooMapping:            odooMapping,
+		Comparison:             comparison,
+		MatchKeys:              matchKeys,
+		ProbableMatchThreshold: probableMatchThreshold,
+		CallbackURL:            callbackURL,
+	}
+	result, err := services.ValidateEmails(ctx, firstFilePath, secondFilePath, opts)
 	if err != nil {
-		logger.Error("Email validation failed: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondRunError(c, err)
 		return
 	}
 	logger.Info("Email validation completed in %s", utils.FormatDuration(time.Since(startTime)))
@@ -123,30 +255,172 @@ func ValidateEmails(c *gin.Context) {
 	logger.Info("Returning validation result: %d matching, %d missing in first, %d missing in second",
 		len(result.MatchingEmails), len(result.MissingInFirstFile), len(result.MissingInSecondFile))
 
-	filePath := filepath.Join("./temp", result.FileName)
+	if result.RunID != "" {
+		c.Header("X-Run-ID", result.RunID)
+	}
+	sendReportFile(c, result.FileName)
+
+	//c.JSON(http.StatusOK, result)
+}
+
+// parseTableOptions sets the header options of a file from the hasHeader and skipRows form values
+func parseTableOptions(opts *services.ExtractOptions, hasHeader, skipRows string) error {
+	var err error
+	if opts.HasHeader, err = services.ParseHasHeader(hasHeader); err != nil {
+		return err
+	}
+	if skipRows != "" {
+		if opts.SkipRows, err = strconv.Atoi(skipRows); err != nil {
+			return fmt.Errorf("skipRows must be a number")
+		}
+	}
+	return services.ValidateTableOptions(*opts)
+}
+
+// parseLenientOptions sets the lenient parsing options of every file from the lenient and
+// maxParseErrors form values
+func parseLenientOptions(lenient, maxParseErrors string, opts ...*services.ExtractOptions) error {
+	var enabled bool
+	var budget int
+	var err error
+	if lenient != "" {
+		if enabled, err = strconv.ParseBool(lenient); err != nil {
+			return fmt.Errorf("lenient must be true or false")
+		}
+	}
+	if maxParseErrors != "" {
+		if budget, err = strconv.Atoi(maxParseErrors); err != nil {
+			return fmt.Errorf("maxParseErrors must be a number")
+		}
+	}
+	for _, o := range opts {
+		o.Lenient = enabled
+		o.MaxParseErrors = budget
+	}
+	return nil
+}
+
+// respondError writes an error response in the envelope shared by every endpoint
+func respondError(c *gin.Context, status int, code, message string) {
+	middleware.AbortWithError(c, status, code, message, nil)
+}
+
+// respondRunError writes the error response for a failed validation run
+func respondRunError(c *gin.Context, err error) {
+	respondOperationError(c, "Email validation", err)
+}
 
-	// Check if file exists
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+// respondOperationError writes the error response for a failed operation, such as "Source
+// comparison", naming it in the logs and the timeout message
+func respondOperationError(c *gin.Context, operation string, err error) {
+	logger := utils.GetLogger()
+	if coded, ok := services.AsCodedError(err); ok {
+		logger.Warn("%s rejected the input: %v", operation, err)
+		middleware.AbortWithError(c, inputErrorStatus(coded.Code()), coded.Code(), err.Error(), coded.Details())
 		return
 	}
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		logger.Warn("%s timed out: %v", operation, err)
+		respondError(c, http.StatusGatewayTimeout, services.CodeTimeout, operation+" did not finish before the deadline")
+	case errors.Is(err, context.Canceled):
+		logger.Warn("%s cancelled: %v", operation, err)
+		respondError(c, statusClientClosedRequest, services.CodeCancelled, operation+" was cancelled")
+	case services.IsMalformedInput(err):
+		logger.Warn("%s failed on a malformed input file: %v", operation, err)
+		respondError(c, http.StatusUnprocessableEntity, services.CodeParseError, err.Error())
+	default:
+		logger.Error("%s failed (request %s): %v", operation, middleware.GetRequestID(c), err)
+		respondError(c, http.StatusInternalServerError, services.CodeInternal, err.Error())
+	}
+}
+
+// inputErrorStatus returns the HTTP status of a service error caused by the input
+func inputErrorStatus(code string) int {
+	switch code {
+	case services.CodeInvalidRequest:
+		return http.StatusBadRequest
+	case services.CodeUnsupportedFormat:
+		return http.StatusUnsupportedMediaType
+	case services.CodeTooLarge:
+		return http.StatusRequestEntityTooLarge
+	default:
+		return http.StatusUnprocessableEntity
+	}
+}
+
+// requestContext derives the validation context from the request, applying the optional
+// timeoutSeconds form field. The server-wide deadline set by middleware still applies.
+func requestContext(c *gin.Context) (context.Context, context.CancelFunc, error) {
+	return requestContextWithTimeout(c, c.PostForm("timeoutSeconds"))
+}
+
+// requestContextWithTimeout derives the validation context from the request, applying a
+// timeoutSeconds value read from wherever the endpoint takes it
+func requestContextWithTimeout(c *gin.Context, value string) (context.Context, context.CancelFunc, error) {
+	ctx := c.Request.Context()
+
+	if value == "" {
+		ctx, cancel := context.WithCancel(ctx)
+		return ctx, cancel, nil
+	}
 
-	// Set appropriate content type based on file extension
-	ext := filepath.Ext(result.FileName)
-	contentType := "application/octet-stream"
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds <= 0 {
+		return nil, nil, fmt.Errorf("timeoutSeconds must be a positive integer")
+	}
 
-	switch ext {
-	case ".csv":
-		contentType = "text/csv"
-	case ".xlsx", ".xls":
-		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	timeout := time.Duration(seconds) * time.Second
+	if maxTimeout := config.Get().MaxRequestTimeout; maxTimeout > 0 && timeout > maxTimeout {
+		timeout = maxTimeout
 	}
 
-	c.Header("Content-Description", "File Transfer")
-	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", result.FileName))
-	c.Header("Content-Type", contentType)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	return ctx, cancel, nil
+}
 
-	c.File(filePath)
+// createTempPath creates an empty file with a unique name in the temp directory, where pattern
+// is a name whose last "*" is replaced by a random string, and returns its path
+func createTempPath(pattern string) (string, error) {
+	tempDir := config.Get().TempDir
+	if err := os.MkdirAll(tempDir, os.ModePerm); err != nil {
+		return "", err
+	}
+	file, err := os.CreateTemp(tempDir, pattern)
+	if err != nil {
+		return "", err
+	}
+	return file.Name(), file.Close()
+}
 
-	//c.JSON(http.StatusOK, result)
+// createUploadDir creates a directory with a unique name in the temp directory for the uploads
+// of a request, so that uploads of concurrent requests never overwrite each other even when
+// the files have the same name. Remove it with removeUploadDir once the request is done.
+func createUploadDir() (string, error) {
+	tempDir := config.Get().TempDir
+	if err := os.MkdirAll(tempDir, os.ModePerm); err != nil {
+		return "", err
+	}
+	return os.MkdirTemp(tempDir, "upload_*")
+}
+
+// removeUploadDir deletes the upload directory of a request with the files in it
+func removeUploadDir(dir string) {
+	if err := os.RemoveAll(dir); err != nil {
+		utils.GetLogger().Warn("Failed to remove upload directory %s: %v", dir, err)
+		return
+	}
+	utils.GetLogger().Debug("Removed upload directory %s", dir)
+}
+
+// removeTempFiles deletes temporary files
+func removeTempFiles(paths ...string) {
+	logger := utils.GetLogger()
+	for _, path := range paths {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			logger.Warn("Failed to remove temporary file %s: %v", path, err)
+			continue
+		}
+		logger.Debug("Removed temporary file %s", path)
+	}
 }
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"ness-to-odoo-golang-validation-api-tool/api/services"
+	"ness-to-odoo-golang-validation-api-tool/utils"
+)
+
+// ValidateEmailList godoc
+// @Summary Validate a list of emails
+// @Description Validate a JSON array of emails without uploading a file, with the same checks as file validations. Returns the detailed result of each email, in order, and aggregate counts.
+// @Tags emails
+// @Accept json
+// @Produce json
+// @Param emails body []string true "Emails to validate (at most MAX_BATCH_EMAILS)"
+// @Param timeoutSeconds query int false "Per-request deadline in seconds (capped by the server configuration)"
+// @Success 200 {object} services.EmailListResult
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 413 {object} middleware.ErrorResponse
+// @Failure 500 {object} middleware.ErrorResponse
+// @Failure 504 {object} middleware.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /emails/validate [post]
+func ValidateEmailList(c *gin.Context) {
+	logger := utils.GetLogger()
+	defer utils.LogExecutionTime("ValidateEmailList handler")()
+
+	var emails []string
+	if err := c.ShouldBindJSON(&emails); err != nil {
+		logger.Warn("Invalid email list: %v", err)
+		respondError(c, http.StatusBadRequest, services.CodeInvalidRequest, "The body must be a JSON array of emails")
+		return
+	}
+	logger.Info("Processing list validation request of %d emails", len(emails))
+
+	ctx, cancel, err := requestContextWithTimeout(c, c.Query("timeoutSeconds"))
+	if err != nil {
+		logger.Warn("Invalid timeout: %v", err)
+		respondError(c, http.StatusBadRequest, services.CodeInvalidRequest, err.Error())
+		return
+	}
+	defer cancel()
+
+	startTime := time.Now()
+	result, err := services.ValidateEmailList(ctx, emails)
+	if err != nil {
+		respondRunError(c, err)
+		return
+	}
+	logger.Info("List validation completed in %s", utils.FormatDuration(time.Since(startTime)))
+
+	c.JSON(http.StatusOK, result)
+}
+
+// LookupEmail godoc
+// @Summary Explain the validation of an email
+// @Description Validate a single email with the same checks as file validations, and explain the result: the normalization rules applied, the checks performed with their outcomes, and likely fixes such as typos of common mail domains
+// @Tags emails
+// @Produce json
+// @Param email path string true "Email to look up, URL-encoded"
+// @Success 200 {object} services.EmailLookup
+// @Security ApiKeyAuth
+// @Router /emails/{email} [get]
+func LookupEmail(c *gin.Context) {
+	lookup := services.LookupEmail(c.Param("email"))
+	utils.GetLogger().Info("Looked up email %s: valid=%t", lookup.Result.Email, lookup.Result.IsValid)
+	c.JSON(http.StatusOK, lookup)
+}
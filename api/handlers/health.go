@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"ness-to-odoo-golang-validation-api-tool/api/services"
+	"ness-to-odoo-golang-validation-api-tool/config"
+	"ness-to-odoo-golang-validation-api-tool/utils"
+)
+
+// shuttingDown is set once the server has started a graceful shutdown
+var shuttingDown atomic.Bool
+
+// readinessCheckTimeout bounds how long the DNS readiness check may take
+const readinessCheckTimeout = 2 * time.Second
+
+// CheckResult describes the outcome of a single readiness check
+type CheckResult struct {
+	Name    string `json:"name"`
+	OK      bool   `json:"ok"`
+	Message string `json:"message,omitempty"`
+}
+
+// MarkShuttingDown makes the readiness endpoint report the server as not ready
+func MarkShuttingDown() {
+	shuttingDown.Store(true)
+}
+
+// Healthz reports that the process is alive
+func Healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Readyz reports whether the server can accept validation work. It checks that the
+// temp, log and (when history is enabled) data directories are writable, that there
+// is enough free disk space and, when domain checks are enabled, that the DNS resolver answers.
+func Readyz(c *gin.Context) {
+	cfg := config.Get()
+
+	if shuttingDown.Load() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"status":     "shutting down",
+			"activeJobs": services.ActiveJobs(),
+		})
+		return
+	}
+
+	checks := []CheckResult{
+		checkWritable("tempDir", cfg.TempDir),
+		checkWritable("logDir", cfg.LogDir),
+		checkFreeDisk("diskSpace", cfg.TempDir, cfg.MinFreeDiskMB),
+	}
+	if cfg.HistoryEnabled {
+		checks = append(checks, checkWritable("dataDir", cfg.DataDir))
+	}
+	if cfg.DNSCheckEnabled {
+		checks = append(checks, checkResolver(c.Request.Context(), "dnsResolver", cfg.DNSCheckHost))
+	}
+
+	status := http.StatusOK
+	statusText := "ready"
+	for _, check := range checks {
+		if !check.OK {
+			status = http.StatusServiceUnavailable
+			statusText = "not ready"
+			utils.GetLogger().Warn("Readiness check %s failed: %s", check.Name, check.Message)
+		}
+	}
+
+	c.JSON(status, gin.H{
+		"status":     statusText,
+		"activeJobs": services.ActiveJobs(),
+		"checks":     checks,
+	})
+}
+
+// checkWritable verifies that a file can be created in dir
+func checkWritable(name, dir string) CheckResult {
+	file, err := os.CreateTemp(dir, ".readyz-*")
+	if err != nil {
+		return CheckResult{Name: name, Message: fmt.Sprintf("%s is not writable: %v", dir, err)}
+	}
+	file.Close()
+	os.Remove(file.Name())
+	return CheckResult{Name: name, OK: true}
+}
+
+// checkFreeDisk verifies that the filesystem holding dir has at least minFreeMB available
+func checkFreeDisk(name, dir string, minFreeMB int) CheckResult {
+	free, err := utils.FreeDiskSpace(dir)
+	if err != nil {
+		return CheckResult{Name: name, Message: fmt.Sprintf("failed to read free space: %v", err)}
+	}
+
+	freeMB := free / (1024 * 1024)
+	if freeMB < uint64(minFreeMB) {
+		return CheckResult{Name: name, Message: fmt.Sprintf("%d MB free, %d MB required", freeMB, minFreeMB)}
+	}
+	return CheckResult{Name: name, OK: true, Message: fmt.Sprintf("%d MB free", freeMB)}
+}
+
+// checkResolver verifies that the DNS resolver can resolve host
+func checkResolver(ctx context.Context, name, host string) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, readinessCheckTimeout)
+	defer cancel()
+
+	if _, err := net.DefaultResolver.LookupHost(ctx, host); err != nil {
+		return CheckResult{Name: name, Message: fmt.Sprintf("failed to resolve %s: %v", host, err)}
+	}
+	return CheckResult{Name: name, OK: true}
+}
@@ -0,0 +1,333 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"ness-to-odoo-golang-validation-api-tool/api/middleware"
+	"ness-to-odoo-golang-validation-api-tool/api/services"
+	"ness-to-odoo-golang-validation-api-tool/utils"
+)
+
+// RunDetails is the response of GetRun
+type RunDetails struct {
+	services.Run
+	Entries []services.RunEntry `json:"entries,omitempty"`
+}
+
+// ListRuns godoc
+// @Summary List past validation runs
+// @Description List recorded validation runs, newest first
+// @Tags runs
+// @Produce json
+// @Param from query string false "Only runs started at or after this time (RFC 3339 or YYYY-MM-DD)"
+// @Param to query string false "Only runs started at or before this time (RFC 3339 or YYYY-MM-DD)"
+// @Param status query string false "Only runs with this status (completed, failed or cancelled)"
+// @Param fileName query string false "Only runs with an input file whose name contains this text"
+// @Param sha256 query string false "Only runs with an input file with this SHA-256 hash"
+// @Param limit query int false "Maximum number of runs to return (default 50, max 500)"
+// @Success 200 {array} services.Run
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 503 {object} middleware.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /runs [get]
+func ListRuns(c *gin.Context) {
+	filter := services.RunFilter{
+		Status:   c.Query("status"),
+		FileName: c.Query("fileName"),
+		SHA256:   c.Query("sha256"),
+	}
+
+	var err error
+	if filter.From, err = parseTimeQuery(c.Query("from"), false); err != nil {
+		respondError(c, http.StatusBadRequest, services.CodeInvalidRequest, "from must be an RFC 3339 time or a YYYY-MM-DD date")
+		return
+	}
+	if filter.To, err = parseTimeQuery(c.Query("to"), true); err != nil {
+		respondError(c, http.StatusBadRequest, services.CodeInvalidRequest, "to must be an RFC 3339 time or a YYYY-MM-DD date")
+		return
+	}
+	if value := c.Query("limit"); value != "" {
+		if filter.Limit, err = strconv.Atoi(value); err != nil || filter.Limit <= 0 {
+			respondError(c, http.StatusBadRequest, services.CodeInvalidRequest, "limit must be a positive integer")
+			return
+		}
+	}
+
+	runs, err := services.ListRuns(filter)
+	if err != nil {
+		respondHistoryError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, runs)
+}
+
+// GetRun godoc
+// @Summary Get a past validation run
+// @Description Get a recorded validation run with its per-entry results
+// @Tags runs
+// @Produce json
+// @Param id path string true "Run ID"
+// @Param category query string false "Only entries of this category (Matching, Missing in First File or Missing in Second File)"
+// @Param entries query bool false "Include per-entry results (default true)"
+// @Success 200 {object} RunDetails
+// @Failure 404 {object} middleware.ErrorResponse
+// @Failure 503 {object} middleware.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /runs/{id} [get]
+func GetRun(c *gin.Context) {
+	run, err := services.GetRun(c.Param("id"))
+	if err != nil {
+		respondHistoryError(c, err)
+		return
+	}
+
+	details := RunDetails{Run: *run}
+	if c.DefaultQuery("entries", "true") != "false" {
+		entries, err := services.GetRunEntries(run.ID)
+		if err != nil {
+			respondHistoryError(c, err)
+			return
+		}
+
+		category := c.Query("category")
+		for _, entry := range entries {
+			if category == "" || strings.EqualFold(entry.Category, category) {
+				details.Entries = append(details.Entries, entry)
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, details)
+}
+
+// RunDomains godoc
+// @Summary Get the domain breakdown of a past run
+// @Description Get the counts of a recorded run by registrable domain (per the Public Suffix List), e.g. to find
+// @Description which customer companies are missing from Odoo
+// @Tags runs
+// @Produce json
+// @Param id path string true "Run ID"
+// @Param domain query string false "Only domains that contain this text"
+// @Param missing query string false "Only domains with emails missing in the first file, the second file or either (first, second or any)"
+// @Param sort query string false "Sort by total (default), valid, matching, missingInFirst, missingInSecond, keyMatches, probableMatches, duplicates or domain"
+// @Param limit query int false "Maximum number of domains to return"
+// @Success 200 {array} services.DomainStats
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Failure 503 {object} middleware.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /runs/{id}/domains [get]
+func RunDomains(c *gin.Context) {
+	filter := services.DomainFilter{
+		Domain:  c.Query("domain"),
+		Missing: c.Query("missing"),
+		Sort:    c.Query("sort"),
+	}
+	if err := filter.Validate(); err != nil {
+		respondError(c, http.StatusBadRequest, services.CodeInvalidRequest, err.Error())
+		return
+	}
+	if value := c.Query("limit"); value != "" {
+		var err error
+		if filter.Limit, err = strconv.Atoi(value); err != nil || filter.Limit <= 0 {
+			respondError(c, http.StatusBadRequest, services.CodeInvalidRequest, "limit must be a positive integer")
+			return
+		}
+	}
+
+	domains, err := services.GetRunDomains(c.Param("id"), filter)
+	if err != nil {
+		respondHistoryError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, domains)
+}
+
+// RunWebhooks godoc
+// @Summary Get the webhook deliveries of a past run
+// @Description Get the log of the completion webhooks sent for a recorded run, with every attempt and its outcome
+// @Tags runs
+// @Produce json
+// @Param id path string true "Run ID"
+// @Success 200 {array} services.WebhookDelivery
+// @Failure 404 {object} middleware.ErrorResponse
+// @Failure 503 {object} middleware.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /runs/{id}/webhooks [get]
+func RunWebhooks(c *gin.Context) {
+	deliveries, err := services.GetRunWebhooks(c.Param("id"))
+	if err != nil {
+		respondHistoryError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// DownloadRunReport godoc
+// @Summary Download the report of a past run
+// @Description Download the report file kept for a recorded validation run
+// @Tags runs
+// @Produce octet-stream
+// @Param id path string true "Run ID"
+// @Success 200 {file} file
+// @Failure 404 {object} middleware.ErrorResponse
+// @Failure 503 {object} middleware.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /runs/{id}/report [get]
+func DownloadRunReport(c *gin.Context) {
+	run, err := services.GetRun(c.Param("id"))
+	if err != nil {
+		respondHistoryError(c, err)
+		return
+	}
+
+	reportPath, err := services.RunReportPath(run)
+	if err != nil {
+		respondError(c, http.StatusNotFound, services.CodeNotFound, "Run has no report")
+		return
+	}
+	sendFile(c, reportPath, run.ReportFile)
+}
+
+// RunDelta godoc
+// @Summary Compare a run with a previous run
+// @Description Compare a recorded run with a previous run or an uploaded previous report, listing the emails
+// @Description whose category or validity changed, e.g. "missing in second → matching" or "became invalid"
+// @Tags runs
+// @Accept multipart/form-data
+// @Produce json
+// @Produce octet-stream
+// @Param id path string true "Run ID"
+// @Param previous query string false "ID of the previous run (default: the most recent completed run before this one of the same kind and comparison strategy)"
+// @Param previousReport formData file false "Previous CSV/Excel validation report to compare with instead of a recorded run"
+// @Param outputFormat query string false "json (default, returned in the response), csv or excel (returned as a file)"
+// @Success 200 {object} services.DeltaResult
+// @Failure 400 {object} middleware.ErrorResponse
+// @Failure 404 {object} middleware.ErrorResponse
+// @Failure 409 {object} middleware.ErrorResponse
+// @Failure 415 {object} middleware.ErrorResponse
+// @Failure 422 {object} middleware.ErrorResponse
+// @Failure 503 {object} middleware.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /runs/{id}/delta [get]
+// @Router /runs/{id}/delta [post]
+func RunDelta(c *gin.Context) {
+	logger := utils.GetLogger()
+
+	outputFormat := formValue(c, "outputFormat")
+	if outputFormat != "" && outputFormat != "json" && outputFormat != "csv" && outputFormat != "excel" {
+		respondError(c, http.StatusBadRequest, services.CodeInvalidRequest, "Output format must be 'json', 'csv' or 'excel'")
+		return
+	}
+
+	current, err := services.RunSnapshot(c.Param("id"))
+	if err != nil {
+		respondHistoryError(c, err)
+		return
+	}
+
+	var previous services.Snapshot
+	if reportFile, err := c.FormFile("previousReport"); err == nil {
+		// A unique name keeps concurrent uploads of reports with the same name apart. Reports
+		// with another extension than CSV or Excel are sniffed by ReportSnapshot.
+		reportPath, err := createTempPath("previous_*" + strings.ToLower(filepath.Ext(reportFile.Filename)))
+		if err == nil {
+			defer removeTempFiles(reportPath)
+			err = c.SaveUploadedFile(reportFile, reportPath)
+		}
+		if err != nil {
+			logger.Error("Failed to save previous report: %v", err)
+			respondError(c, http.StatusInternalServerError, services.CodeInternal, "Failed to save previous report")
+			return
+		}
+
+		if previous, err = services.ReportSnapshot(c.Request.Context(), reportPath); err != nil {
+			logger.Warn("Invalid previous report: %v", err)
+			// Name the report as it was uploaded rather than by its temporary name
+			message := strings.ReplaceAll(err.Error(), filepath.Base(reportPath), filepath.Base(reportFile.Filename))
+			var formatErr *services.UnsupportedFormatError
+			if errors.As(err, &formatErr) {
+				middleware.AbortWithError(c, http.StatusUnsupportedMediaType, services.CodeUnsupportedFormat, message,
+					map[string]interface{}{"file": filepath.Base(reportFile.Filename), "supportedFormats": []string{".csv", ".xlsx"}})
+				return
+			}
+			respondError(c, http.StatusBadRequest, services.CodeInvalidRequest, message)
+			return
+		}
+		previous.Name = filepath.Base(reportFile.Filename)
+	} else {
+		previousID := formValue(c, "previous")
+		if previousID == "" {
+			previousID = services.PreviousRun
+		}
+		if previous, err = services.PreviousRunSnapshot(current.Name, previousID); err != nil {
+			respondHistoryError(c, err)
+			return
+		}
+	}
+
+	opts := services.DeltaOptions{}
+	if outputFormat == "csv" || outputFormat == "excel" {
+		opts.OutputFormat = outputFormat
+	}
+	result, err := services.CompareSnapshots(c.Request.Context(), previous, current, opts)
+	if err != nil {
+		respondOperationError(c, "Run comparison", err)
+		return
+	}
+
+	if result.FileName != "" {
+		sendReportFile(c, result.FileName)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// respondHistoryError writes the error response for a failed history lookup
+func respondHistoryError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrRunNotFound):
+		respondError(c, http.StatusNotFound, services.CodeNotFound, "Run not found")
+	case errors.Is(err, services.ErrRunIncomplete):
+		respondError(c, http.StatusConflict, services.CodeConflict, err.Error())
+	case errors.Is(err, services.ErrHistoryDisabled):
+		respondError(c, http.StatusServiceUnavailable, services.CodeHistoryDisabled, "Validation history is disabled")
+	default:
+		utils.GetLogger().Error("History lookup failed (request %s): %v", middleware.GetRequestID(c), err)
+		respondError(c, http.StatusInternalServerError, services.CodeInternal, err.Error())
+	}
+}
+
+// formValue returns a multipart form field, falling back to the query string
+func formValue(c *gin.Context, key string) string {
+	if value, ok := c.GetPostForm(key); ok {
+		return value
+	}
+	return c.Query(key)
+}
+
+// parseTimeQuery parses an RFC 3339 time or a YYYY-MM-DD date. A date used as an upper
+// bound covers the whole day.
+func parseTimeQuery(value string, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+	return t, nil
+}
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"ness-to-odoo-golang-validation-api-tool/api/services"
+	"ness-to-odoo-golang-validation-api-tool/config"
+	"ness-to-odoo-golang-validation-api-tool/utils"
+)
+
+// APIKeyHeader is the header that carries the API key of a request
+const APIKeyHeader = "X-API-Key"
+
+// AuthRequired reports whether API_KEYS is set, so that requests need a key
+func AuthRequired() bool {
+	return strings.TrimSpace(config.Get().APIKeys) != ""
+}
+
+// ValidAPIKey reports whether key is one of API_KEYS. Any key, even none, is valid when
+// API_KEYS is not set. The REST and gRPC servers share this check.
+func ValidAPIKey(key string) bool {
+	if !AuthRequired() {
+		return true
+	}
+	if key == "" {
+		return false
+	}
+	valid := false
+	for _, allowed := range strings.Split(config.Get().APIKeys, ",") {
+		allowed = strings.TrimSpace(allowed)
+		// Compare every key in constant time so the answer does not leak which one is close
+		if allowed != "" && subtle.ConstantTimeCompare([]byte(allowed), []byte(key)) == 1 {
+			valid = true
+		}
+	}
+	return valid
+}
+
+// APIKeyAuth is a middleware that rejects requests without a valid X-API-Key header when
+// API_KEYS is set
+func APIKeyAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !ValidAPIKey(c.GetHeader(APIKeyHeader)) {
+			utils.GetLogger().Warn("Rejected %s %s: missing or invalid API key", c.Request.Method, c.Request.URL.Path)
+			AbortWithError(c, http.StatusUnauthorized, services.CodeUnauthorized, "A valid API key is required in the X-API-Key header", nil)
+			return
+		}
+		c.Next()
+	}
+}
+
+// ViewLinkAuth is APIKeyAuth for the view route: browsers cannot send the X-API-Key header, so a
+// request may carry a signed, unexpired view link in its query instead
+func ViewLinkAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if services.ValidViewLink(c.Param("filename"), c.Query(services.ViewLinkExpiresParam), c.Query(services.ViewLinkSignatureParam)) {
+			c.Next()
+			return
+		}
+		if !ValidAPIKey(c.GetHeader(APIKeyHeader)) {
+			utils.GetLogger().Warn("Rejected %s %s: missing or invalid API key or view link", c.Request.Method, c.Request.URL.Path)
+			AbortWithError(c, http.StatusUnauthorized, services.CodeUnauthorized, "A valid API key in the X-API-Key header or an unexpired view link is required", nil)
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"ness-to-odoo-golang-validation-api-tool/api/services"
+	"ness-to-odoo-golang-validation-api-tool/utils"
+)
+
+// ErrorResponse is the body of every error response of the API
+type ErrorResponse struct {
+	// Code is a stable identifier of the error, e.g. parse_error
+	Code    string `json:"code" example:"parse_error"`
+	Message string `json:"message" example:"cannot parse contacts.csv on line 3, column 7: bare \" in non-quoted-field"`
+	// Details help fixing the request, e.g. the file and location of a parse error
+	Details map[string]interface{} `json:"details,omitempty"`
+	// RequestID matches the X-Request-ID header, for finding the request in the logs
+	RequestID string `json:"requestId" example:"4f9c2a7e1b3d4c5a8e6f0b1c2d3e4f5a"`
+}
+
+// AbortWithError writes an error response with the request ID and stops the request
+func AbortWithError(c *gin.Context, status int, code, message string, details map[string]interface{}) {
+	c.AbortWithStatusJSON(status, ErrorResponse{
+		Code:      code,
+		Message:   message,
+		Details:   details,
+		RequestID: GetRequestID(c),
+	})
+}
+
+// Recovery is a middleware that turns panics into internal error responses
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecovery(func(c *gin.Context, recovered interface{}) {
+		utils.GetLogger().Error("Recovered from panic in %s %s (request %s): %v",
+			c.Request.Method, c.Request.URL.Path, GetRequestID(c), recovered)
+		AbortWithError(c, http.StatusInternalServerError, services.CodeInternal, "Internal server error", nil)
+	})
+}
@@ -23,6 +23,9 @@ func Logger() gin.HandlerFunc {
 		for _, param := range c.Params {
 			params[param.Key] = param.Value
 		}
+		if id := GetRequestID(c); id != "" {
+			params["requestId"] = id
+		}
 		utils.LogRequest(method, path, params)
 
 		// Process request
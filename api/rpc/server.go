@@ -0,0 +1,165 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"ness-to-odoo-golang-validation-api-tool/api/middleware"
+	"ness-to-odoo-golang-validation-api-tool/api/rpc/validationpb"
+	"ness-to-odoo-golang-validation-api-tool/api/services"
+	"ness-to-odoo-golang-validation-api-tool/config"
+	"ness-to-odoo-golang-validation-api-tool/utils"
+)
+
+// apiKeyMetadata is the metadata key that carries the API key of a call
+const apiKeyMetadata = "x-api-key"
+
+// Server implements the gRPC validation service on the same services as the REST API
+type Server struct {
+	validationpb.UnimplementedValidationServiceServer
+}
+
+// NewServer creates the gRPC server with the validation service registered. Calls need a valid
+// API key when API_KEYS is set, and run under the server-wide VALIDATION_TIMEOUT deadline.
+func NewServer() *grpc.Server {
+	srv := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(logUnary, authUnary, deadlineUnary),
+		grpc.ChainStreamInterceptor(logStream, authStream, deadlineStream),
+	)
+	validationpb.RegisterValidationServiceServer(srv, &Server{})
+	return srv
+}
+
+// logUnary logs unary calls and their outcome
+func logUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	logCall(info.FullMethod, start, err)
+	return resp, err
+}
+
+// logStream logs streaming calls and their outcome
+func logStream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	err := handler(srv, ss)
+	logCall(info.FullMethod, start, err)
+	return err
+}
+
+// logCall logs a finished call like the REST request logger
+func logCall(method string, start time.Time, err error) {
+	utils.GetLogger().Info("gRPC %s completed with %s in %s", method, status.Code(err), utils.FormatDuration(time.Since(start)))
+}
+
+// authUnary rejects unary calls without a valid API key
+func authUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := checkAPIKey(ctx, info.FullMethod); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// authStream rejects streaming calls without a valid API key
+func authStream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := checkAPIKey(ss.Context(), info.FullMethod); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
+
+// checkAPIKey checks the API key of a call with the same rule as the REST API
+func checkAPIKey(ctx context.Context, method string) error {
+	if !middleware.ValidAPIKey(apiKey(ctx)) {
+		utils.GetLogger().Warn("Rejected gRPC %s: missing or invalid API key", method)
+		return status.Error(codes.Unauthenticated, "a valid API key is required in the x-api-key metadata")
+	}
+	return nil
+}
+
+// apiKey returns the API key of a call, or an empty string
+func apiKey(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get(apiKeyMetadata); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// deadlineUnary applies the server-wide deadline to unary calls
+func deadlineUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, cancel := serverDeadline(ctx)
+	defer cancel()
+	return handler(ctx, req)
+}
+
+// deadlineStream applies the server-wide deadline to streaming calls
+func deadlineStream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, cancel := serverDeadline(ss.Context())
+	defer cancel()
+	return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+}
+
+// serverDeadline derives a context with the VALIDATION_TIMEOUT deadline; a zero timeout disables it
+func serverDeadline(ctx context.Context) (context.Context, context.CancelFunc) {
+	if timeout := config.Get().ValidationTimeout; timeout > 0 {
+		return context.WithTimeout(ctx, timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// contextStream is a server stream with a replaced context
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns the replaced context
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
+
+// requestTimeout applies an optional per-request deadline in seconds, capped by MAX_REQUEST_TIMEOUT
+func requestTimeout(ctx context.Context, seconds int32) (context.Context, context.CancelFunc, error) {
+	if seconds == 0 {
+		ctx, cancel := context.WithCancel(ctx)
+		return ctx, cancel, nil
+	}
+	if seconds < 0 {
+		return nil, nil, status.Error(codes.InvalidArgument, "timeout_seconds must be a positive integer")
+	}
+	timeout := time.Duration(seconds) * time.Second
+	if maxTimeout := config.Get().MaxRequestTimeout; maxTimeout > 0 && timeout > maxTimeout {
+		timeout = maxTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	return ctx, cancel, nil
+}
+
+// toStatus maps a service error to the gRPC status matching the REST status of the error
+func toStatus(err error) error {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, "validation did not finish before the deadline")
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case services.IsMalformedInput(err), errors.As(err, new(*services.InvalidOptionError)):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.As(err, new(*services.TooLargeError)):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, services.ErrRunNotFound):
+		return status.Error(codes.NotFound, "run not found")
+	case errors.Is(err, services.ErrRunIncomplete):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, services.ErrHistoryDisabled):
+		return status.Error(codes.Unavailable, "validation history is disabled")
+	default:
+		utils.GetLogger().Error("gRPC call failed: %v", err)
+		return status.Error(codes.Internal, err.Error())
+	}
+}
@@ -0,0 +1,339 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"ness-to-odoo-golang-validation-api-tool/api/rpc/validationpb"
+	"ness-to-odoo-golang-validation-api-tool/api/services"
+	"ness-to-odoo-golang-validation-api-tool/config"
+	"ness-to-odoo-golang-validation-api-tool/utils"
+)
+
+// reportChunkSize is the size of the chunks of a streamed report
+const reportChunkSize = 64 * 1024
+
+// ValidateBatch validates a list of emails
+func (s *Server) ValidateBatch(ctx context.Context, req *validationpb.ValidateBatchRequest) (*validationpb.ValidateBatchResponse, error) {
+	ctx, cancel, err := requestTimeout(ctx, req.GetTimeoutSeconds())
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	result, err := services.ValidateEmailList(ctx, req.GetEmails())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	resp := &validationpb.ValidateBatchResponse{
+		Results: make([]*validationpb.EmailResult, len(result.Results)),
+		Summary: &validationpb.BatchSummary{
+			Total:                 int32(result.Summary.Total),
+			Valid:                 int32(result.Summary.Valid),
+			Invalid:               int32(result.Summary.Invalid),
+			Disposable:            int32(result.Summary.Disposable),
+			Unique:                int32(result.Summary.Unique),
+			Reasons:               make(map[string]int32, len(result.Summary.Reasons)),
+			DomainCheck:           result.Summary.DomainCheck,
+			ProcessingTimeSeconds: result.Summary.ProcessingTimeSeconds,
+		},
+	}
+	for i, r := range result.Results {
+		resp.Results[i] = &validationpb.EmailResult{
+			Email:           r.Email,
+			IsValid:         r.IsValid,
+			IsDisposable:    r.IsDisposable,
+			NormalizedEmail: r.NormalizedEmail,
+			Reason:          r.Reason,
+			ReasonCode:      r.ReasonCode,
+		}
+	}
+	for code, count := range result.Summary.Reasons {
+		resp.Summary.Reasons[code] = int32(count)
+	}
+	return resp, nil
+}
+
+// UploadFiles receives the options and the two files of a validation, runs it and returns its result
+func (s *Server) UploadFiles(stream validationpb.ValidationService_UploadFilesServer) error {
+	logger := utils.GetLogger()
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	options := first.GetOptions()
+	if options == nil {
+		return status.Error(codes.InvalidArgument, "the first message must carry the options")
+	}
+	opts, err := validationOptions(stream.Context(), options)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx, cancel, err := requestTimeout(stream.Context(), options.GetTimeoutSeconds())
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	// Each upload has its own directory, so files keep their names without overwriting others
+	uploadDir, err := os.MkdirTemp(config.Get().TempDir, "grpc_upload_")
+	if err != nil {
+		logger.Error("Failed to create upload directory: %v", err)
+		return status.Error(codes.Internal, "failed to save the files")
+	}
+	defer removeUploadDir(uploadDir)
+
+	paths, err := receiveFiles(stream, uploadDir)
+	if err != nil {
+		return err
+	}
+	logger.Info("Received files over gRPC: %s, %s", filepath.Base(paths[0]), filepath.Base(paths[1]))
+
+	result, err := services.ValidateEmails(ctx, paths[0], paths[1], opts)
+	if err != nil {
+		return toStatus(err)
+	}
+
+	return stream.SendAndClose(&validationpb.ValidationResult{
+		RunId:               result.RunID,
+		FileName:            result.FileName,
+		DownloadUrl:         result.OutputFileURL,
+		Summary:             toSummary(result.Summary),
+		MatchingEmails:      result.MatchingEmails,
+		MissingInFirstFile:  result.MissingInFirstFile,
+		MissingInSecondFile: result.MissingInSecondFile,
+	})
+}
+
+// validationOptions converts the upload options, with the same parsing and defaults as the
+// form fields of POST /api/v1/validate-emails
+func validationOptions(ctx context.Context, options *validationpb.UploadOptions) (services.ValidationOptions, error) {
+	opts := services.ValidationOptions{OutputFormat: options.GetOutputFormat()}
+	if opts.OutputFormat == "" {
+		opts.OutputFormat = "csv"
+	}
+	if _, err := services.GetReporter(opts.OutputFormat); err != nil {
+		return opts, err
+	}
+
+	var err error
+	if spec := options.GetOdooMapping(); spec != "" {
+		if opts.OdooMapping, err = services.ParseOdooMapping(spec); err != nil {
+			return opts, err
+		}
+	}
+	if opts.Comparison, err = services.ParseComparisonStrategy(options.GetComparison()); err != nil {
+		return opts, err
+	}
+	if opts.MatchKeys, err = services.ParseMatchKeys(options.GetMatchKeys()); err != nil {
+		return opts, err
+	}
+	if opts.ProbableMatchThreshold, err = services.ParseProbableMatchThreshold(options.GetProbableMatchThreshold()); err != nil {
+		return opts, err
+	}
+	if opts.CallbackURL, err = services.ParseCallbackURL(options.GetCallbackUrl()); err != nil {
+		return opts, err
+	}
+	if opts.CallbackURL == "" {
+		opts.CallbackURL = services.CallbackURLForAPIKey(apiKey(ctx))
+	}
+
+	for _, file := range []struct {
+		name    string
+		options *validationpb.FileOptions
+		target  *services.ExtractOptions
+	}{{"first", options.GetFirstFile(), &opts.FirstFile}, {"second", options.GetSecondFile(), &opts.SecondFile}} {
+		if *file.target, err = extractOptions(file.options, options.GetLenient(), options.GetMaxParseErrors()); err != nil {
+			return opts, fmt.Errorf("invalid options of the %s file: %w", file.name, err)
+		}
+	}
+	return opts, nil
+}
+
+// extractOptions converts the options of an input file
+func extractOptions(options *validationpb.FileOptions, lenient bool, maxParseErrors int32) (services.ExtractOptions, error) {
+	opts := services.ExtractOptions{
+		Column:         options.GetColumn(),
+		Sheet:          options.GetSheet(),
+		JSONPath:       options.GetJsonPath(),
+		Delimiter:      options.GetDelimiter(),
+		Quote:          options.GetQuote(),
+		Encoding:       options.GetEncoding(),
+		SkipRows:       int(options.GetSkipRows()),
+		Lenient:        lenient,
+		MaxParseErrors: int(maxParseErrors),
+	}
+	var err error
+	if opts.HasHeader, err = services.ParseHasHeader(options.GetHasHeader()); err != nil {
+		return opts, err
+	}
+	if err := services.ValidateTableOptions(opts); err != nil {
+		return opts, err
+	}
+	if err := services.ValidateDialectOptions(opts); err != nil {
+		return opts, err
+	}
+	return opts, services.ValidateJSONPath(opts.JSONPath)
+}
+
+// receiveFiles writes the file chunks of an upload to dir, and returns the paths of the first
+// and second file
+func receiveFiles(stream validationpb.ValidationService_UploadFilesServer, dir string) ([]string, error) {
+	files := make(map[validationpb.FileRole]*os.File, 2)
+	paths := make(map[validationpb.FileRole]string, 2)
+	defer func() {
+		for _, file := range files {
+			file.Close()
+		}
+	}()
+
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		chunk := req.GetChunk()
+		if chunk == nil {
+			return nil, status.Error(codes.InvalidArgument, "only the first message may carry the options")
+		}
+		role := chunk.GetRole()
+		if role != validationpb.FileRole_FILE_ROLE_FIRST && role != validationpb.FileRole_FILE_ROLE_SECOND {
+			return nil, status.Error(codes.InvalidArgument, "every chunk needs the role of its file")
+		}
+
+		file := files[role]
+		if file == nil {
+			name := filepath.Base(chunk.GetFileName())
+			if chunk.GetFileName() == "" {
+				return nil, status.Error(codes.InvalidArgument, "the first chunk of a file needs its file name")
+			}
+			path := filepath.Join(dir, name)
+			for _, other := range paths {
+				if other == path {
+					// Both files have the same name; keep them apart
+					path = filepath.Join(dir, fmt.Sprintf("%d_%s", role, name))
+				}
+			}
+			if file, err = os.Create(path); err != nil {
+				return nil, status.Errorf(codes.Internal, "failed to save %s", name)
+			}
+			files[role], paths[role] = file, path
+		}
+		if _, err := file.Write(chunk.GetData()); err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to save %s", filepath.Base(paths[role]))
+		}
+	}
+
+	if files[validationpb.FileRole_FILE_ROLE_FIRST] == nil || files[validationpb.FileRole_FILE_ROLE_SECOND] == nil {
+		return nil, status.Error(codes.InvalidArgument, "both the first and the second file are required")
+	}
+	return []string{paths[validationpb.FileRole_FILE_ROLE_FIRST], paths[validationpb.FileRole_FILE_ROLE_SECOND]}, nil
+}
+
+// GetJob returns the status of a recorded run
+func (s *Server) GetJob(ctx context.Context, req *validationpb.GetJobRequest) (*validationpb.Job, error) {
+	run, err := services.GetRun(req.GetRunId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	job := &validationpb.Job{
+		RunId:      run.ID,
+		Status:     run.Status,
+		Error:      run.Error,
+		StartedAt:  run.StartedAt.Format(time.RFC3339),
+		ReportFile: run.ReportFile,
+	}
+	if !run.FinishedAt.IsZero() {
+		job.FinishedAt = run.FinishedAt.Format(time.RFC3339)
+	}
+	if run.Status == services.RunStatusCompleted {
+		job.Summary = toSummary(run.Summary)
+	}
+	return job, nil
+}
+
+// DownloadReport streams the kept report of a recorded run
+func (s *Server) DownloadReport(req *validationpb.DownloadReportRequest, stream validationpb.ValidationService_DownloadReportServer) error {
+	run, err := services.GetRun(req.GetRunId())
+	if err != nil {
+		return toStatus(err)
+	}
+	reportPath, err := services.RunReportPath(run)
+	if err != nil {
+		return status.Error(codes.NotFound, "run has no report")
+	}
+	file, err := os.Open(reportPath)
+	if err != nil {
+		return status.Error(codes.NotFound, "report file not found")
+	}
+	defer file.Close()
+
+	chunk := &validationpb.ReportChunk{
+		FileName:    run.ReportFile,
+		ContentType: services.ReportContentType(run.ReportFile),
+	}
+	buf := make([]byte, reportChunkSize)
+	for {
+		n, err := file.Read(buf)
+		if n > 0 {
+			chunk.Data = buf[:n]
+			if err := stream.Send(chunk); err != nil {
+				return err
+			}
+			chunk = &validationpb.ReportChunk{}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return status.Errorf(codes.Internal, "failed to read the report: %v", err)
+		}
+		if err := stream.Context().Err(); err != nil {
+			return toStatus(err)
+		}
+	}
+}
+
+// toSummary converts the summary of a run
+func toSummary(summary services.ValidationSummary) *validationpb.Summary {
+	return &validationpb.Summary{
+		TotalEmailsFirstFile:   int32(summary.TotalEmailsFirstFile),
+		TotalEmailsSecondFile:  int32(summary.TotalEmailsSecondFile),
+		ValidEmailsFirstFile:   int32(summary.ValidEmailsFirstFile),
+		ValidEmailsSecondFile:  int32(summary.ValidEmailsSecondFile),
+		MatchingCount:          int32(summary.MatchingCount),
+		MissingInFirstCount:    int32(summary.MissingInFirstCount),
+		MissingInSecondCount:   int32(summary.MissingInSecondCount),
+		ComparisonStrategy:     string(summary.ComparisonStrategy),
+		KeyMatchCount:          int32(summary.KeyMatchCount),
+		ProbableMatchCount:     int32(summary.ProbableMatchCount),
+		ProbableMatchThreshold: summary.ProbableMatchThreshold,
+		DisposableEmailsCount:  int32(summary.DisposableEmailsCount),
+		SkippedRowsFirstFile:   int32(summary.SkippedRowsFirstFile),
+		SkippedRowsSecondFile:  int32(summary.SkippedRowsSecondFile),
+		ParseErrorsFirstFile:   int32(summary.ParseErrorsFirstFile),
+		ParseErrorsSecondFile:  int32(summary.ParseErrorsSecondFile),
+		ProcessingTimeSeconds:  summary.ProcessingTimeSeconds,
+	}
+}
+
+// removeUploadDir deletes the files of an upload once its run is over. The report is written
+// to the temp directory and the history keeps its own copy, so nothing refers to them.
+func removeUploadDir(dir string) {
+	if err := os.RemoveAll(dir); err != nil {
+		utils.GetLogger().Warn("Failed to remove upload directory %s: %v", dir, err)
+	}
+}
@@ -0,0 +1,294 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v4.25.3
+// source: validationpb/validation.proto
+
+package validationpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	ValidationService_ValidateBatch_FullMethodName  = "/validation.v1.ValidationService/ValidateBatch"
+	ValidationService_UploadFiles_FullMethodName    = "/validation.v1.ValidationService/UploadFiles"
+	ValidationService_GetJob_FullMethodName         = "/validation.v1.ValidationService/GetJob"
+	ValidationService_DownloadReport_FullMethodName = "/validation.v1.ValidationService/DownloadReport"
+)
+
+// ValidationServiceClient is the client API for ValidationService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ValidationServiceClient interface {
+	// ValidateBatch validates a list of emails, like POST /api/v1/emails/validate
+	ValidateBatch(ctx context.Context, in *ValidateBatchRequest, opts ...grpc.CallOption) (*ValidateBatchResponse, error)
+	// UploadFiles streams the two files of a validation and returns its result once the run
+	// finishes, like POST /api/v1/validate-emails. The first message carries the options, the
+	// following ones the chunks of the files.
+	UploadFiles(ctx context.Context, opts ...grpc.CallOption) (ValidationService_UploadFilesClient, error)
+	// GetJob returns the status of a recorded run, like GET /api/v1/runs/{id}
+	GetJob(ctx context.Context, in *GetJobRequest, opts ...grpc.CallOption) (*Job, error)
+	// DownloadReport streams the report of a recorded run, like GET /api/v1/runs/{id}/report
+	DownloadReport(ctx context.Context, in *DownloadReportRequest, opts ...grpc.CallOption) (ValidationService_DownloadReportClient, error)
+}
+
+type validationServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewValidationServiceClient(cc grpc.ClientConnInterface) ValidationServiceClient {
+	return &validationServiceClient{cc}
+}
+
+func (c *validationServiceClient) ValidateBatch(ctx context.Context, in *ValidateBatchRequest, opts ...grpc.CallOption) (*ValidateBatchResponse, error) {
+	out := new(ValidateBatchResponse)
+	err := c.cc.Invoke(ctx, ValidationService_ValidateBatch_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *validationServiceClient) UploadFiles(ctx context.Context, opts ...grpc.CallOption) (ValidationService_UploadFilesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ValidationService_ServiceDesc.Streams[0], ValidationService_UploadFiles_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &validationServiceUploadFilesClient{stream}
+	return x, nil
+}
+
+type ValidationService_UploadFilesClient interface {
+	Send(*UploadFilesRequest) error
+	CloseAndRecv() (*ValidationResult, error)
+	grpc.ClientStream
+}
+
+type validationServiceUploadFilesClient struct {
+	grpc.ClientStream
+}
+
+func (x *validationServiceUploadFilesClient) Send(m *UploadFilesRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *validationServiceUploadFilesClient) CloseAndRecv() (*ValidationResult, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(ValidationResult)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *validationServiceClient) GetJob(ctx context.Context, in *GetJobRequest, opts ...grpc.CallOption) (*Job, error) {
+	out := new(Job)
+	err := c.cc.Invoke(ctx, ValidationService_GetJob_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *validationServiceClient) DownloadReport(ctx context.Context, in *DownloadReportRequest, opts ...grpc.CallOption) (ValidationService_DownloadReportClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ValidationService_ServiceDesc.Streams[1], ValidationService_DownloadReport_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &validationServiceDownloadReportClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ValidationService_DownloadReportClient interface {
+	Recv() (*ReportChunk, error)
+	grpc.ClientStream
+}
+
+type validationServiceDownloadReportClient struct {
+	grpc.ClientStream
+}
+
+func (x *validationServiceDownloadReportClient) Recv() (*ReportChunk, error) {
+	m := new(ReportChunk)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ValidationServiceServer is the server API for ValidationService service.
+// All implementations must embed UnimplementedValidationServiceServer
+// for forward compatibility
+type ValidationServiceServer interface {
+	// ValidateBatch validates a list of emails, like POST /api/v1/emails/validate
+	ValidateBatch(context.Context, *ValidateBatchRequest) (*ValidateBatchResponse, error)
+	// UploadFiles streams the two files of a validation and returns its result once the run
+	// finishes, like POST /api/v1/validate-emails. The first message carries the options, the
+	// following ones the chunks of the files.
+	UploadFiles(ValidationService_UploadFilesServer) error
+	// GetJob returns the status of a recorded run, like GET /api/v1/runs/{id}
+	GetJob(context.Context, *GetJobRequest) (*Job, error)
+	// DownloadReport streams the report of a recorded run, like GET /api/v1/runs/{id}/report
+	DownloadReport(*DownloadReportRequest, ValidationService_DownloadReportServer) error
+	mustEmbedUnimplementedValidationServiceServer()
+}
+
+// UnimplementedValidationServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedValidationServiceServer struct {
+}
+
+func (UnimplementedValidationServiceServer) ValidateBatch(context.Context, *ValidateBatchRequest) (*ValidateBatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ValidateBatch not implemented")
+}
+func (UnimplementedValidationServiceServer) UploadFiles(ValidationService_UploadFilesServer) error {
+	return status.Errorf(codes.Unimplemented, "method UploadFiles not implemented")
+}
+func (UnimplementedValidationServiceServer) GetJob(context.Context, *GetJobRequest) (*Job, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetJob not implemented")
+}
+func (UnimplementedValidationServiceServer) DownloadReport(*DownloadReportRequest, ValidationService_DownloadReportServer) error {
+	return status.Errorf(codes.Unimplemented, "method DownloadReport not implemented")
+}
+func (UnimplementedValidationServiceServer) mustEmbedUnimplementedValidationServiceServer() {}
+
+// UnsafeValidationServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ValidationServiceServer will
+// result in compilation errors.
+type UnsafeValidationServiceServer interface {
+	mustEmbedUnimplementedValidationServiceServer()
+}
+
+func RegisterValidationServiceServer(s grpc.ServiceRegistrar, srv ValidationServiceServer) {
+	s.RegisterService(&ValidationService_ServiceDesc, srv)
+}
+
+func _ValidationService_ValidateBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ValidateBatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ValidationServiceServer).ValidateBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ValidationService_ValidateBatch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ValidationServiceServer).ValidateBatch(ctx, req.(*ValidateBatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ValidationService_UploadFiles_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ValidationServiceServer).UploadFiles(&validationServiceUploadFilesServer{stream})
+}
+
+type ValidationService_UploadFilesServer interface {
+	SendAndClose(*ValidationResult) error
+	Recv() (*UploadFilesRequest, error)
+	grpc.ServerStream
+}
+
+type validationServiceUploadFilesServer struct {
+	grpc.ServerStream
+}
+
+func (x *validationServiceUploadFilesServer) SendAndClose(m *ValidationResult) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *validationServiceUploadFilesServer) Recv() (*UploadFilesRequest, error) {
+	m := new(UploadFilesRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _ValidationService_GetJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ValidationServiceServer).GetJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ValidationService_GetJob_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ValidationServiceServer).GetJob(ctx, req.(*GetJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ValidationService_DownloadReport_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(DownloadReportRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ValidationServiceServer).DownloadReport(m, &validationServiceDownloadReportServer{stream})
+}
+
+type ValidationService_DownloadReportServer interface {
+	Send(*ReportChunk) error
+	grpc.ServerStream
+}
+
+type validationServiceDownloadReportServer struct {
+	grpc.ServerStream
+}
+
+func (x *validationServiceDownloadReportServer) Send(m *ReportChunk) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// ValidationService_ServiceDesc is the grpc.ServiceDesc for ValidationService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ValidationService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "validation.v1.ValidationService",
+	HandlerType: (*ValidationServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ValidateBatch",
+			Handler:    _ValidationService_ValidateBatch_Handler,
+		},
+		{
+			MethodName: "GetJob",
+			Handler:    _ValidationService_GetJob_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "UploadFiles",
+			Handler:       _ValidationService_UploadFiles_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "DownloadReport",
+			Handler:       _ValidationService_DownloadReport_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "validationpb/validation.proto",
+}
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"net/mail"
+	"net/url"
+	"strings"
+)
+
+// mailtoPrefix is the URI scheme of email links, as found in cells copied from web pages
+const mailtoPrefix = "mailto:"
+
+// parsedAddress is a mailbox of an address list
+type parsedAddress struct {
+	// Name is the display name, e.g. "Nguyen Van A", or empty
+	Name    string
+	Address string
+}
+
+// parseAddressList splits a cell holding one or more mailboxes, such as
+// `"Nguyen Van A" <a@x.vn>; b@y.vn` or `mailto:a@x.vn`, into its addresses. Mailboxes are
+// separated by ";", "," or line breaks outside quotes and angle brackets, following the
+// RFC 5322 mailbox syntax. Parts that cannot be parsed are kept as they are, so validation
+// reports them, and parts without "@" are dropped like cells without "@".
+func parseAddressList(cell string) []parsedAddress {
+	var addresses []parsedAddress
+	pending := ""
+	for _, part := range splitAddressList(cell) {
+		// An unquoted comma in a display name, as in "Doe, John <j@x.com>", splits the
+		// name from its address; the name is joined back to the address that follows
+		if !strings.Contains(stripMailto(strings.TrimSpace(part)), "@") {
+			pending = part
+			continue
+		}
+		if pending != "" && strings.Contains(part, "<") && !strings.HasPrefix(strings.TrimSpace(part), "<") {
+			part = pending + ", " + part
+		}
+		pending = ""
+		addresses = append(addresses, parseMailbox(part))
+	}
+	return addresses
+}
+
+// splitAddressList splits a cell on the mailbox separators outside quoted strings, angle
+// brackets and comments, and returns the non-empty trimmed parts
+func splitAddressList(cell string) []string {
+	var parts []string
+	var current strings.Builder
+	quoted, escaped := false, false
+	angle, comment := 0, 0
+	flush := func() {
+		if part := strings.TrimSpace(current.String()); part != "" {
+			parts = append(parts, part)
+		}
+		current.Reset()
+	}
+
+	for _, r := range cell {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\' && (quoted || comment > 0):
+			escaped = true
+		case r == '"' && comment == 0:
+			quoted = !quoted
+		case quoted:
+		case r == '(':
+			comment++
+		case r == ')' && comment > 0:
+			comment--
+		case comment > 0:
+		case r == '<':
+			angle++
+		case r == '>' && angle > 0:
+			angle--
+		case angle == 0 && (r == ';' || r == ',' || r == '\n' || r == '\r'):
+			flush()
+			continue
+		}
+		current.WriteRune(r)
+	}
+	flush()
+	return parts
+}
+
+// parseMailbox parses a single mailbox: a bare address, a name-addr such as
+// "Nguyen Van A <a@x.vn>", or either of them with a mailto: prefix
+func parseMailbox(part string) parsedAddress {
+	part = stripMailto(strings.TrimSpace(part))
+	if address, err := mail.ParseAddress(part); err == nil {
+		return parsedAddress{Name: address.Name, Address: stripMailto(address.Address)}
+	}
+
+	// Fall back to a lenient name-addr split, so "Name <bad@>" is validated as "bad@"
+	open, close := strings.LastIndexByte(part, '<'), strings.LastIndexByte(part, '>')
+	if open >= 0 && close > open {
+		return parsedAddress{
+			Name:    unquoteDisplayName(part[:open]),
+			Address: stripMailto(strings.TrimSpace(part[open+1 : close])),
+		}
+	}
+	return parsedAddress{Address: part}
+}
+
+// stripMailto removes a mailto: prefix and the query of a mailto URI, e.g.
+// "mailto:a%40x.vn?subject=Hi" becomes "a@x.vn"
+func stripMailto(value string) string {
+	if len(value) < len(mailtoPrefix) || !strings.EqualFold(value[:len(mailtoPrefix)], mailtoPrefix) {
+		return value
+	}
+	value = value[len(mailtoPrefix):]
+	if i := strings.IndexByte(value, '?'); i >= 0 {
+		value = value[:i]
+	}
+	if unescaped, err := url.PathUnescape(value); err == nil {
+		value = unescaped
+	}
+	return strings.TrimSpace(value)
+}
+
+// unquoteDisplayName trims a display name and removes its enclosing quotes
+func unquoteDisplayName(name string) string {
+	name = strings.TrimSpace(name)
+	if len(name) >= 2 && name[0] == '"' && name[len(name)-1] == '"' {
+		name = strings.ReplaceAll(name[1:len(name)-1], `\"`, `"`)
+	}
+	return strings.TrimSpace(name)
+}
+
+// expandAddressLists replaces every extracted cell by the addresses it holds. The addresses
+// of a cell share its location and fields. Expanding addresses again keeps their display names.
+func expandAddressLists(extracted []ExtractedEmail) []ExtractedEmail {
+	expanded := make([]ExtractedEmail, 0, len(extracted))
+	for _, email := range extracted {
+		for _, address := range parseAddressList(email.Email) {
+			item := email
+			item.Email = address.Address
+			if address.Name != "" {
+				item.DisplayName = address.Name
+			}
+			expanded = append(expanded, item)
+		}
+	}
+	return expanded
+}
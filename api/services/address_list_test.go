@@ -0,0 +1,83 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseAddressList(t *testing.T) {
+	tests := []struct {
+		name string
+		cell string
+		want []parsedAddress
+	}{
+		{
+			name: "bare address",
+			cell: "a@x.vn",
+			want: []parsedAddress{{Address: "a@x.vn"}},
+		},
+		{
+			name: "display name",
+			cell: "Nguyen Van A <a@x.vn>",
+			want: []parsedAddress{{Name: "Nguyen Van A", Address: "a@x.vn"}},
+		},
+		{
+			name: "quoted display name with separators",
+			cell: `"Doe; John, Jr." <j@x.com>`,
+			want: []parsedAddress{{Name: "Doe; John, Jr.", Address: "j@x.com"}},
+		},
+		{
+			name: "unquoted comma in display name",
+			cell: "Doe, John <j@x.com>",
+			want: []parsedAddress{{Name: "Doe, John", Address: "j@x.com"}},
+		},
+		{
+			name: "list with mixed separators",
+			cell: "a@x.vn; B <b@y.vn>, c@z.vn\nd@w.vn",
+			want: []parsedAddress{{Address: "a@x.vn"}, {Name: "B", Address: "b@y.vn"}, {Address: "c@z.vn"}, {Address: "d@w.vn"}},
+		},
+		{
+			name: "mailto link",
+			cell: "mailto:a%40x.vn?subject=Hi",
+			want: []parsedAddress{{Address: "a@x.vn"}},
+		},
+		{
+			name: "mailto inside angle brackets",
+			cell: "A <mailto:a@x.vn>",
+			want: []parsedAddress{{Name: "A", Address: "a@x.vn"}},
+		},
+		{
+			name: "comment with separators",
+			cell: "a@x.vn (work; main), b@y.vn",
+			want: []parsedAddress{{Name: "work; main", Address: "a@x.vn"}, {Address: "b@y.vn"}},
+		},
+		{
+			name: "invalid address inside angle brackets",
+			cell: `"A" <bad@>`,
+			want: []parsedAddress{{Name: "A", Address: "bad@"}},
+		},
+		{
+			name: "unparsable part is kept",
+			cell: "a@@x.vn",
+			want: []parsedAddress{{Address: "a@@x.vn"}},
+		},
+		{
+			name: "parts without @ are dropped",
+			cell: "n/a; a@x.vn;;",
+			want: []parsedAddress{{Address: "a@x.vn"}},
+		},
+		{
+			name: "empty cell",
+			cell: " ; ",
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseAddressList(tt.cell); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseAddressList(%q) = %+v, want %+v", tt.cell, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"ness-to-odoo-golang-validation-api-tool/utils"
+)
+
+// CheckSummary contains summary statistics of a single-file check
+type CheckSummary struct {
+	TotalEmails      int `json:"totalEmails"`
+	ValidEmails      int `json:"validEmails"`
+	InvalidEmails    int `json:"invalidEmails"`
+	DisposableEmails int `json:"disposableEmails"`
+	DuplicateEmails  int `json:"duplicateEmails"`
+	// SkippedRows counts the leading rows that were not read as data: rows skipped with skipRows and the header
+	SkippedRows int `json:"skippedRows"`
+	// ParseErrors counts the malformed rows skipped by lenient parsing
+	ParseErrors           int     `json:"parseErrors"`
+	ProcessingTimeSeconds float64 `json:"processingTimeSeconds"`
+}
+
+// CheckResult represents the result of validating the emails of a single file
+type CheckResult struct {
+	FileName       string       `json:"fileName"`
+	Inputs         []InputFile  `json:"inputs"`
+	ParseErrors    []ParseError `json:"parseErrors"`
+	InvalidEntries []EmailEntry `json:"invalidEntries"`
+	Summary        CheckSummary `json:"summary"`
+}
+
+// CheckFile extracts and validates the emails of a single file without comparing it to another source
+func CheckFile(ctx context.Context, filePath string, opts ExtractOptions) (*CheckResult, error) {
+	logger := utils.GetLogger()
+	logger.Info("Starting email check for file: %s", filePath)
+	defer trackJob()()
+	startTime := time.Now()
+
+	extraction, err := ExtractEmails(ctx, filePath, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract emails: %w", err)
+	}
+
+	entries, err := validateEmailList(ctx, extraction.Emails, filepath.Base(filePath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to validate emails: %w", err)
+	}
+
+	result := &CheckResult{
+		FileName:       filepath.Base(filePath),
+		Inputs:         extraction.inputs(""),
+		ParseErrors:    extraction.parseErrors(""),
+		InvalidEntries: make([]EmailEntry, 0),
+		Summary: CheckSummary{
+			TotalEmails: len(entries),
+			SkippedRows: extraction.skippedRows(),
+			ParseErrors: len(extraction.ParseErrors),
+		},
+	}
+
+	// Count each normalized address once; later occurrences are duplicates
+	seen := make(map[string]struct{}, len(entries))
+	for i, entry := range entries {
+		if err := checkCancelled(ctx, i); err != nil {
+			return nil, err
+		}
+
+		if entry.IsValid {
+			result.Summary.ValidEmails++
+		} else {
+			result.Summary.InvalidEmails++
+			result.InvalidEntries = append(result.InvalidEntries, entry)
+		}
+		if entry.IsDisposable {
+			result.Summary.DisposableEmails++
+		}
+
+		if _, exists := seen[entry.NormalizedEmail]; exists {
+			result.Summary.DuplicateEmails++
+		} else {
+			seen[entry.NormalizedEmail] = struct{}{}
+		}
+	}
+
+	result.Summary.ProcessingTimeSeconds = time.Since(startTime).Seconds()
+	logger.Info("Email check completed in %s: %d/%d valid, %d duplicates",
+		utils.FormatDuration(time.Since(startTime)),
+		result.Summary.ValidEmails, result.Summary.TotalEmails, result.Summary.DuplicateEmails)
+
+	return result, nil
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ComparisonStrategy selects the key on which the emails of both files are compared
+type ComparisonStrategy string
+
+// Comparison strategies, from the strictest to the loosest
+const (
+	// ComparisonRaw compares the emails exactly as read
+	ComparisonRaw ComparisonStrategy = "raw"
+	// ComparisonCaseInsensitive compares the emails ignoring case only
+	ComparisonCaseInsensitive ComparisonStrategy = "case-insensitive"
+	// ComparisonNormalized compares the normalized emails, the default
+	ComparisonNormalized ComparisonStrategy = "normalized"
+	// ComparisonDomain compares the domains of the emails, for B2B checks
+	ComparisonDomain ComparisonStrategy = "domain"
+	// ComparisonRegistrableDomain compares the domains under their public suffix, so
+	// "mail.corp.vn" and "corp.vn" are the same company
+	ComparisonRegistrableDomain ComparisonStrategy = "registrable-domain"
+)
+
+// ComparisonStrategies returns the supported comparison strategies
+func ComparisonStrategies() []ComparisonStrategy {
+	return []ComparisonStrategy{
+		ComparisonRaw,
+		ComparisonCaseInsensitive,
+		ComparisonNormalized,
+		ComparisonDomain,
+		ComparisonRegistrableDomain,
+	}
+}
+
+// ParseComparisonStrategy parses a comparison strategy option; empty selects ComparisonNormalized
+func ParseComparisonStrategy(value string) (ComparisonStrategy, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" {
+		return ComparisonNormalized, nil
+	}
+	names := make([]string, 0, len(ComparisonStrategies()))
+	for _, strategy := range ComparisonStrategies() {
+		if string(strategy) == value {
+			return strategy, nil
+		}
+		names = append(names, string(strategy))
+	}
+	return "", fmt.Errorf("unsupported comparison strategy %q: use %s", value, strings.Join(names, ", "))
+}
+
+// orDefault returns the strategy, or ComparisonNormalized when it is not set
+func (s ComparisonStrategy) orDefault() ComparisonStrategy {
+	if s == "" {
+		return ComparisonNormalized
+	}
+	return s
+}
+
+// domainLevel reports whether the strategy compares domains rather than emails
+func (s ComparisonStrategy) domainLevel() bool {
+	return s == ComparisonDomain || s == ComparisonRegistrableDomain
+}
+
+// key returns the value on which an entry is compared
+func (s ComparisonStrategy) key(entry EmailEntry) string {
+	switch s {
+	case ComparisonRaw:
+		return entry.Email
+	case ComparisonCaseInsensitive:
+		return strings.ToLower(entry.Email)
+	case ComparisonDomain:
+		return emailDomain(strings.ToLower(entry.NormalizedEmail))
+	case ComparisonRegistrableDomain:
+		return registrableDomain(emailDomain(strings.ToLower(entry.NormalizedEmail)))
+	default:
+		return entry.NormalizedEmail
+	}
+}
@@ -0,0 +1,620 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+	"ness-to-odoo-golang-validation-api-tool/utils"
+)
+
+// PreviousRun selects the most recent completed run before the current one as the previous side of a delta
+const PreviousRun = "previous"
+
+// stateAbsent is the state of an email that is not part of a run
+const stateAbsent = "absent"
+
+// Validity changes of a delta entry
+const (
+	ValidityBecameInvalid = "became invalid"
+	ValidityBecameValid   = "became valid"
+)
+
+// DeltaOptions controls how a delta report is written
+type DeltaOptions struct {
+	// OutputFormat is the report format: "json", "csv" or "excel". No report file is written when it is empty.
+	OutputFormat string `json:"outputFormat,omitempty"`
+	// OutputPath overrides the report location. By default a timestamped file is created in the temp directory.
+	OutputPath string `json:"outputPath,omitempty"`
+}
+
+// Snapshot is the per-email state of a validation run, either recorded in the history or read from a report
+type Snapshot struct {
+	// Name identifies the snapshot in the delta report: a run ID or a report file name
+	Name    string
+	Entries []RunEntry
+}
+
+// DeltaEntry describes how a single email changed between two runs
+type DeltaEntry struct {
+	Email            string `json:"email"`
+	NormalizedEmail  string `json:"normalizedEmail"`
+	Transition       string `json:"transition"`
+	PreviousCategory string `json:"previousCategory,omitempty"`
+	CurrentCategory  string `json:"currentCategory,omitempty"`
+	PreviousValid    bool   `json:"previousValid"`
+	CurrentValid     bool   `json:"currentValid"`
+	ValidityChange   string `json:"validityChange,omitempty"`
+	Reason           string `json:"reason,omitempty"`
+}
+
+// TransitionCount is the number of emails with a given transition
+type TransitionCount struct {
+	Transition string `json:"transition"`
+	Count      int    `json:"count"`
+}
+
+// DeltaSummary contains the counts of a delta report
+type DeltaSummary struct {
+	PreviousTotal    int               `json:"previousTotal"`
+	CurrentTotal     int               `json:"currentTotal"`
+	NewlyMatching    int               `json:"newlyMatching"`
+	NoLongerMatching int               `json:"noLongerMatching"`
+	BecameInvalid    int               `json:"becameInvalid"`
+	BecameValid      int               `json:"becameValid"`
+	Added            int               `json:"added"`
+	Removed          int               `json:"removed"`
+	Unchanged        int               `json:"unchanged"`
+	Transitions      []TransitionCount `json:"transitions"`
+}
+
+// DeltaResult is the difference between a previous and a current validation run
+type DeltaResult struct {
+	Previous      string       `json:"previous"`
+	Current       string       `json:"current"`
+	Entries       []DeltaEntry `json:"entries"`
+	OutputFileURL string       `json:"outputFileURL,omitempty"`
+	FileName      string       `json:"fileName,omitempty"`
+	Summary       DeltaSummary `json:"summary"`
+}
+
+// ResultSnapshot returns the snapshot of a validation result
+func ResultSnapshot(result *ValidationResult) Snapshot {
+	name := result.RunID
+	if name == "" {
+		name = result.FileName
+	}
+	return Snapshot{Name: name, Entries: result.Entries}
+}
+
+// RunSnapshot returns the snapshot of a recorded run
+func RunSnapshot(id string) (Snapshot, error) {
+	run, err := GetRun(id)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	if run.Status != RunStatusCompleted {
+		return Snapshot{}, fmt.Errorf("run %s has status %s: %w", id, run.Status, ErrRunIncomplete)
+	}
+
+	entries, err := GetRunEntries(id)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	return Snapshot{Name: id, Entries: entries}, nil
+}
+
+// PreviousRunSnapshot returns the snapshot of previousID, or of the most recent completed
+// run before currentID when previousID is PreviousRun
+func PreviousRunSnapshot(currentID, previousID string) (Snapshot, error) {
+	if previousID == PreviousRun {
+		if currentID == "" {
+			return Snapshot{}, fmt.Errorf("the current run was not recorded, so it has no previous run: %w", ErrHistoryDisabled)
+		}
+		var err error
+		if previousID, err = PreviousRunID(currentID); err != nil {
+			return Snapshot{}, err
+		}
+	}
+	return RunSnapshot(previousID)
+}
+
+// ReportSnapshot reads the snapshot of a run from a CSV or Excel validation report. The format
+// is chosen by the file extension, or by sniffing the content for other extensions.
+func ReportSnapshot(ctx context.Context, reportPath string) (Snapshot, error) {
+	var rows [][]string
+	var err error
+
+	ext := strings.ToLower(filepath.Ext(reportPath))
+	if ext != ".csv" && ext != ".xlsx" {
+		if ext, err = sniffInputFormat(reportPath); err != nil {
+			return Snapshot{}, err
+		}
+	}
+	switch ext {
+	case ".csv", ".txt":
+		rows, err = readCSVReportRows(reportPath)
+	case ".xlsx":
+		rows, err = readExcelReportRows(reportPath)
+	default:
+		return Snapshot{}, &UnsupportedFormatError{File: filepath.Base(reportPath), Reason: "reports are CSV or Excel files"}
+	}
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("failed to read report %s: %w", filepath.Base(reportPath), err)
+	}
+
+	entries, err := parseReportRows(ctx, rows)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("failed to read report %s: %w", filepath.Base(reportPath), err)
+	}
+	return Snapshot{Name: filepath.Base(reportPath), Entries: entries}, nil
+}
+
+// readCSVReportRows reads all records of a CSV report
+func readCSVReportRows(path string) ([][]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	// The summary section has fewer fields than the results section
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+
+	var rows [][]string
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, record)
+	}
+}
+
+// readExcelReportRows reads the entries of an Excel report: the rows of its category sheets
+// (see excelCategorySheets), or of the single results sheet of reports written by earlier
+// versions
+func readExcelReportRows(path string) ([][]string, error) {
+	f, err := excelize.OpenFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if index, err := f.GetSheetIndex("Validation Results"); err == nil && index >= 0 {
+		return f.GetRows("Validation Results")
+	}
+
+	var rows [][]string
+	for _, sheet := range excelCategorySheets {
+		// Reports written before key and probable matching lack their sheets
+		if index, err := f.GetSheetIndex(sheet.name); err != nil || index < 0 {
+			continue
+		}
+		sheetRows, err := f.GetRows(sheet.name)
+		if err != nil {
+			return nil, err
+		}
+		if len(rows) > 0 && len(sheetRows) > 0 {
+			// Keep the header of the first sheet only
+			sheetRows = sheetRows[1:]
+		}
+		rows = append(rows, sheetRows...)
+	}
+	return rows, nil
+}
+
+// parseReportRows converts the results section of a validation report to run entries.
+// The section ends at the first row without a status, which precedes the summary section.
+func parseReportRows(ctx context.Context, rows [][]string) ([]RunEntry, error) {
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("report is empty")
+	}
+
+	header := rows[0]
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.TrimSpace(name)] = i
+	}
+	for _, name := range []string{"Email", "Normalized Email", "Status", "Valid", "Reason"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("report has no %q column; is it a validation report?", name)
+		}
+	}
+
+	entries := make([]RunEntry, 0, len(rows)-1)
+	for i, row := range rows[1:] {
+		if err := checkCancelled(ctx, i); err != nil {
+			return nil, err
+		}
+		// Excel rows omit trailing empty cells
+		for len(row) < len(header) {
+			row = append(row, "")
+		}
+		if row[columns["Status"]] == "" {
+			break
+		}
+
+		entry := RunEntry{
+			Email:           row[columns["Email"]],
+			NormalizedEmail: row[columns["Normalized Email"]],
+			Category:        row[columns["Status"]],
+			IsValid:         row[columns["Valid"]] == fmtBool(true),
+			Reason:          row[columns["Reason"]],
+		}
+		if entry.NormalizedEmail == "" {
+			entry.NormalizedEmail = strings.ToLower(strings.TrimSpace(entry.Email))
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// CompareSnapshots compares the per-email state of two runs and writes the delta report
+// when opts.OutputFormat is set
+func CompareSnapshots(ctx context.Context, previous, current Snapshot, opts DeltaOptions) (*DeltaResult, error) {
+	logger := utils.GetLogger()
+	defer utils.LogExecutionTime("CompareSnapshots")()
+	logger.Info("Comparing run %s with previous run %s", current.Name, previous.Name)
+
+	entries, summary, err := compareSnapshots(ctx, previous.Entries, current.Entries)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare runs: %w", err)
+	}
+
+	result := &DeltaResult{
+		Previous: previous.Name,
+		Current:  current.Name,
+		Entries:  entries,
+		Summary:  summary,
+	}
+	if opts.OutputFormat == "" {
+		return result, nil
+	}
+
+	outputFilePath, outputFileName, outputFileURL := outputLocation("delta_result", opts.OutputFormat, opts.OutputPath)
+	logger.Info("Generating delta report: %s", outputFilePath)
+	if err := generateDeltaOutputFile(ctx, outputFilePath, result); err != nil {
+		logger.Error("Failed to generate delta report: %v", err)
+		// Do not leave a partially written report behind
+		if removeErr := os.Remove(outputFilePath); removeErr != nil && !os.IsNotExist(removeErr) {
+			logger.Warn("Failed to remove partial delta report %s: %v", outputFilePath, removeErr)
+		}
+		return nil, fmt.Errorf("failed to generate delta report: %w", err)
+	}
+	result.FileName = outputFileName
+	result.OutputFileURL = outputFileURL
+
+	logger.Info("Delta completed: %d newly matching, %d no longer matching, %d became invalid, %d unchanged",
+		summary.NewlyMatching, summary.NoLongerMatching, summary.BecameInvalid, summary.Unchanged)
+	return result, nil
+}
+
+// compareSnapshots returns the entries whose category or validity changed between two runs,
+// keyed by normalized email, sorted by transition and email
+func compareSnapshots(ctx context.Context, previous, current []RunEntry) ([]DeltaEntry, DeltaSummary, error) {
+	previousByEmail := indexRunEntries(previous)
+	currentByEmail := indexRunEntries(current)
+	summary := DeltaSummary{
+		PreviousTotal: len(previousByEmail),
+		CurrentTotal:  len(currentByEmail),
+	}
+
+	// Visit every email of either run once
+	emails := make([]string, 0, len(currentByEmail)+len(previousByEmail))
+	for email := range currentByEmail {
+		emails = append(emails, email)
+	}
+	for email := range previousByEmail {
+		if _, ok := currentByEmail[email]; !ok {
+			emails = append(emails, email)
+		}
+	}
+
+	entries := make([]DeltaEntry, 0)
+	transitionCounts := make(map[string]int)
+	for i, email := range emails {
+		if err := checkCancelled(ctx, i); err != nil {
+			return nil, summary, err
+		}
+
+		before, wasPresent := previousByEmail[email]
+		after, isPresent := currentByEmail[email]
+		previousState, currentState := categoryState(before.Category, wasPresent), categoryState(after.Category, isPresent)
+
+		entry := DeltaEntry{
+			NormalizedEmail:  email,
+			Email:            after.Email,
+			PreviousCategory: before.Category,
+			CurrentCategory:  after.Category,
+			PreviousValid:    before.IsValid,
+			CurrentValid:     after.IsValid,
+			Reason:           after.Reason,
+		}
+		if !isPresent {
+			entry.Email = before.Email
+			entry.Reason = before.Reason
+		}
+		if wasPresent && isPresent && before.IsValid != after.IsValid {
+			entry.ValidityChange = ValidityBecameValid
+			if before.IsValid {
+				entry.ValidityChange = ValidityBecameInvalid
+			}
+		}
+
+		switch {
+		case previousState != currentState:
+			entry.Transition = previousState + " → " + currentState
+		case entry.ValidityChange != "":
+			entry.Transition = entry.ValidityChange
+		default:
+			summary.Unchanged++
+			continue
+		}
+
+		switch {
+		case !wasPresent:
+			summary.Added++
+		case !isPresent:
+			summary.Removed++
+		}
+		if currentState == stateOf(CategoryMatching) && previousState != currentState {
+			summary.NewlyMatching++
+		}
+		if previousState == stateOf(CategoryMatching) && previousState != currentState {
+			summary.NoLongerMatching++
+		}
+		switch entry.ValidityChange {
+		case ValidityBecameInvalid:
+			summary.BecameInvalid++
+		case ValidityBecameValid:
+			summary.BecameValid++
+		}
+
+		transitionCounts[entry.Transition]++
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Transition != entries[j].Transition {
+			return entries[i].Transition < entries[j].Transition
+		}
+		return entries[i].NormalizedEmail < entries[j].NormalizedEmail
+	})
+
+	summary.Transitions = make([]TransitionCount, 0, len(transitionCounts))
+	for transition, count := range transitionCounts {
+		summary.Transitions = append(summary.Transitions, TransitionCount{Transition: transition, Count: count})
+	}
+	sort.Slice(summary.Transitions, func(i, j int) bool {
+		if summary.Transitions[i].Count != summary.Transitions[j].Count {
+			return summary.Transitions[i].Count > summary.Transitions[j].Count
+		}
+		return summary.Transitions[i].Transition < summary.Transitions[j].Transition
+	})
+
+	return entries, summary, nil
+}
+
+// indexRunEntries maps normalized emails to the first entry recorded for them
+func indexRunEntries(entries []RunEntry) map[string]RunEntry {
+	byEmail := make(map[string]RunEntry, len(entries))
+	for _, entry := range entries {
+		if _, ok := byEmail[entry.NormalizedEmail]; !ok {
+			byEmail[entry.NormalizedEmail] = entry
+		}
+	}
+	return byEmail
+}
+
+// categoryState returns the short state name used in transitions
+func categoryState(category string, present bool) string {
+	if !present {
+		return stateAbsent
+	}
+	return stateOf(category)
+}
+
+// stateOf returns the short state name of a category, e.g. "missing in second" for "Missing in Second File"
+func stateOf(category string) string {
+	return strings.TrimSuffix(strings.ToLower(category), " file")
+}
+
+// generateDeltaOutputFile writes the delta report in the format given by the file extension
+func generateDeltaOutputFile(ctx context.Context, outputPath string, result *DeltaResult) error {
+	ext := strings.ToLower(filepath.Ext(outputPath))
+
+	switch ext {
+	case ".json":
+		return generateDeltaJSONOutput(outputPath, result)
+	case ".csv":
+		return generateDeltaCSVOutput(ctx, outputPath, result)
+	case ".xlsx", ".xls":
+		return generateDeltaExcelOutput(ctx, outputPath, result)
+	default:
+		return fmt.Errorf("unsupported output format: %s", ext)
+	}
+}
+
+// generateDeltaJSONOutput writes the delta result as indented JSON
+func generateDeltaJSONOutput(outputPath string, result *DeltaResult) error {
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(result)
+}
+
+// deltaHeaders are the column headers of the delta entries in CSV and Excel reports
+var deltaHeaders = []string{
+	"Email",
+	"Normalized Email",
+	"Transition",
+	"Previous Status",
+	"Current Status",
+	"Previously Valid",
+	"Valid",
+	"Reason",
+}
+
+// deltaRecord returns the report columns of a delta entry
+func deltaRecord(entry DeltaEntry) []string {
+	previousValid, currentValid := fmtBool(entry.PreviousValid), fmtBool(entry.CurrentValid)
+	if entry.PreviousCategory == "" {
+		previousValid = ""
+	}
+	if entry.CurrentCategory == "" {
+		currentValid = ""
+	}
+	return []string{
+		entry.Email,
+		entry.NormalizedEmail,
+		entry.Transition,
+		entry.PreviousCategory,
+		entry.CurrentCategory,
+		previousValid,
+		currentValid,
+		entry.Reason,
+	}
+}
+
+// deltaSummaryRows returns the metrics of the delta summary section
+func deltaSummaryRows(result *DeltaResult) [][]interface{} {
+	summary := result.Summary
+	rows := [][]interface{}{
+		{"Previous Run", result.Previous},
+		{"Current Run", result.Current},
+		{"Emails in Previous Run", summary.PreviousTotal},
+		{"Emails in Current Run", summary.CurrentTotal},
+		{"Newly Matching", summary.NewlyMatching},
+		{"No Longer Matching", summary.NoLongerMatching},
+		{"Became Invalid", summary.BecameInvalid},
+		{"Became Valid", summary.BecameValid},
+		{"Added", summary.Added},
+		{"Removed", summary.Removed},
+		{"Unchanged", summary.Unchanged},
+	}
+	for _, transition := range summary.Transitions {
+		rows = append(rows, []interface{}{transition.Transition, transition.Count})
+	}
+	return rows
+}
+
+// generateDeltaCSVOutput writes the delta entries followed by the summary as CSV
+func generateDeltaCSVOutput(ctx context.Context, outputPath string, result *DeltaResult) error {
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	defer writer.Flush()
+
+	if err := writer.Write(deltaHeaders); err != nil {
+		return err
+	}
+	for i, entry := range result.Entries {
+		if err := checkCancelled(ctx, i); err != nil {
+			return err
+		}
+		if err := writer.Write(deltaRecord(entry)); err != nil {
+			return err
+		}
+	}
+
+	rows := [][]string{{""}, {"Summary"}, {"Metric", "Value"}}
+	for _, row := range deltaSummaryRows(result) {
+		rows = append(rows, []string{fmt.Sprint(row[0]), fmt.Sprint(row[1])})
+	}
+	return writer.WriteAll(rows)
+}
+
+// generateDeltaExcelOutput writes the delta entries and the summary as an Excel workbook
+func generateDeltaExcelOutput(ctx context.Context, outputPath string, result *DeltaResult) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	headerStyle, err := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true},
+		Fill: excelize.Fill{Type: "pattern", Color: []string{"#DDEBF7"}, Pattern: 1},
+		Border: []excelize.Border{
+			{Type: "bottom", Color: "#000000", Style: 1},
+		},
+		Alignment: &excelize.Alignment{Horizontal: "center"},
+	})
+	if err != nil {
+		return err
+	}
+
+	// Delta entries sheet
+	deltaSheet := "Delta"
+	index, err := f.NewSheet(deltaSheet)
+	if err != nil {
+		return err
+	}
+	f.SetActiveSheet(index)
+
+	headers := deltaHeaders
+	if err := f.SetSheetRow(deltaSheet, "A1", &headers); err != nil {
+		return err
+	}
+	lastColumn, err := excelize.ColumnNumberToName(len(headers))
+	if err != nil {
+		return err
+	}
+	f.SetCellStyle(deltaSheet, "A1", lastColumn+"1", headerStyle)
+
+	for i, entry := range result.Entries {
+		if err := checkCancelled(ctx, i); err != nil {
+			return err
+		}
+		record := deltaRecord(entry)
+		if err := f.SetSheetRow(deltaSheet, fmt.Sprintf("A%d", i+2), &record); err != nil {
+			return err
+		}
+	}
+	f.SetColWidth(deltaSheet, "A", "B", 30)
+	f.SetColWidth(deltaSheet, "C", "C", 35)
+	f.SetColWidth(deltaSheet, "D", "E", 22)
+	f.SetColWidth(deltaSheet, "F", "G", 15)
+	f.SetColWidth(deltaSheet, "H", "H", 30)
+
+	// Summary sheet
+	summarySheet := "Summary"
+	if _, err := f.NewSheet(summarySheet); err != nil {
+		return err
+	}
+	f.SetCellValue(summarySheet, "A1", "Metric")
+	f.SetCellValue(summarySheet, "B1", "Value")
+	f.SetCellStyle(summarySheet, "A1", "B1", headerStyle)
+	for i, row := range deltaSummaryRows(result) {
+		if err := f.SetSheetRow(summarySheet, fmt.Sprintf("A%d", i+2), &row); err != nil {
+			return err
+		}
+	}
+	f.SetColWidth(summarySheet, "A", "A", 40)
+	f.SetColWidth(summarySheet, "B", "B", 30)
+
+	f.DeleteSheet("Sheet1")
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return f.SaveAs(outputPath)
+}
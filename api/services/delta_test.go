@@ -0,0 +1,180 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestCompareSnapshotsTransitions(t *testing.T) {
+	entry := func(email, category string, valid bool) RunEntry {
+		return RunEntry{Email: email, NormalizedEmail: email, Category: category, IsValid: valid}
+	}
+
+	tests := []struct {
+		name           string
+		previous       []RunEntry
+		current        []RunEntry
+		wantTransition string
+		wantValidity   string
+		want           DeltaSummary
+	}{
+		{
+			name:           "newly matching",
+			previous:       []RunEntry{entry("a@x.com", CategoryMissingInSecond, true)},
+			current:        []RunEntry{entry("a@x.com", CategoryMatching, true)},
+			wantTransition: "missing in second → matching",
+			want:           DeltaSummary{PreviousTotal: 1, CurrentTotal: 1, NewlyMatching: 1},
+		},
+		{
+			name:           "no longer matching",
+			previous:       []RunEntry{entry("a@x.com", CategoryMatching, true)},
+			current:        []RunEntry{entry("a@x.com", CategoryMissingInFirst, true)},
+			wantTransition: "matching → missing in first",
+			want:           DeltaSummary{PreviousTotal: 1, CurrentTotal: 1, NoLongerMatching: 1},
+		},
+		{
+			name:           "added",
+			current:        []RunEntry{entry("a@x.com", CategoryMatching, true)},
+			wantTransition: "absent → matching",
+			want:           DeltaSummary{CurrentTotal: 1, NewlyMatching: 1, Added: 1},
+		},
+		{
+			name:           "removed",
+			previous:       []RunEntry{entry("a@x.com", CategoryMatching, true)},
+			wantTransition: "matching → absent",
+			want:           DeltaSummary{PreviousTotal: 1, NoLongerMatching: 1, Removed: 1},
+		},
+		{
+			name:           "became invalid in the same category",
+			previous:       []RunEntry{entry("a@x.com", CategoryMatching, true)},
+			current:        []RunEntry{entry("a@x.com", CategoryMatching, false)},
+			wantTransition: ValidityBecameInvalid,
+			wantValidity:   ValidityBecameInvalid,
+			want:           DeltaSummary{PreviousTotal: 1, CurrentTotal: 1, BecameInvalid: 1},
+		},
+		{
+			name:           "category change wins over validity change",
+			previous:       []RunEntry{entry("a@x.com", CategoryMissingInSecond, false)},
+			current:        []RunEntry{entry("a@x.com", CategoryMatching, true)},
+			wantTransition: "missing in second → matching",
+			wantValidity:   ValidityBecameValid,
+			want:           DeltaSummary{PreviousTotal: 1, CurrentTotal: 1, NewlyMatching: 1, BecameValid: 1},
+		},
+		{
+			name:     "unchanged",
+			previous: []RunEntry{entry("a@x.com", CategoryMatching, true)},
+			current:  []RunEntry{entry("a@x.com", CategoryMatching, true)},
+			want:     DeltaSummary{PreviousTotal: 1, CurrentTotal: 1, Unchanged: 1},
+		},
+		{
+			name:     "first entry of a repeated email counts",
+			previous: []RunEntry{entry("a@x.com", CategoryMatching, true), entry("a@x.com", CategoryMissingInFirst, true)},
+			current:  []RunEntry{entry("a@x.com", CategoryMatching, true)},
+			want:     DeltaSummary{PreviousTotal: 1, CurrentTotal: 1, Unchanged: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, summary, err := compareSnapshots(context.Background(), tt.previous, tt.current)
+			if err != nil {
+				t.Fatalf("compareSnapshots() error = %v", err)
+			}
+
+			if tt.wantTransition == "" {
+				if len(entries) != 0 {
+					t.Fatalf("compareSnapshots() entries = %+v, want none", entries)
+				}
+			} else {
+				if len(entries) != 1 {
+					t.Fatalf("compareSnapshots() returned %d entries, want 1", len(entries))
+				}
+				if entries[0].Transition != tt.wantTransition {
+					t.Errorf("Transition = %q, want %q", entries[0].Transition, tt.wantTransition)
+				}
+				if entries[0].ValidityChange != tt.wantValidity {
+					t.Errorf("ValidityChange = %q, want %q", entries[0].ValidityChange, tt.wantValidity)
+				}
+				if len(summary.Transitions) != 1 || summary.Transitions[0].Count != 1 {
+					t.Errorf("Transitions = %+v, want one transition counted once", summary.Transitions)
+				}
+			}
+
+			summary.Transitions = nil
+			if !reflect.DeepEqual(summary, tt.want) {
+				t.Errorf("summary = %+v, want %+v", summary, tt.want)
+			}
+		})
+	}
+}
+
+func TestReportSnapshotExcelRoundTrip(t *testing.T) {
+	entry := func(email string) EmailEntry {
+		return EmailEntry{Email: email, NormalizedEmail: email, IsValid: true}
+	}
+	report := &ReportData{
+		Matching:        []EmailEntry{entry("a@x.com"), entry("b@x.com")},
+		MissingInFirst:  []EmailEntry{entry("c@x.com")},
+		MissingInSecond: []EmailEntry{entry("d@x.com")},
+		KeyMatches:      []KeyMatch{{First: entry("e@x.com"), Second: entry("e@y.com"), Key: "phone"}},
+		ProbableMatches: []ProbableMatch{{First: entry("nguyen.vana@x.com"), Second: entry("nguyenvana@x.com"), Similarity: 0.91}},
+	}
+
+	var buffer bytes.Buffer
+	if err := writeExcelReport(context.Background(), &buffer, report); err != nil {
+		t.Fatalf("writeExcelReport() error = %v", err)
+	}
+	path := writeInput(t, "report.xlsx", buffer.String())
+
+	snapshot, err := ReportSnapshot(context.Background(), path)
+	if err != nil {
+		t.Fatalf("ReportSnapshot() error = %v", err)
+	}
+	got := make(map[string]int)
+	for _, entry := range snapshot.Entries {
+		got[entry.Category]++
+	}
+	want := map[string]int{
+		CategoryMatching:        2,
+		CategoryMissingInFirst:  1,
+		CategoryMissingInSecond: 1,
+		CategoryKeyMatch:        1,
+		CategoryProbableMatch:   1,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("entries by category = %v, want %v", got, want)
+	}
+	if len(snapshot.Entries) != 6 {
+		t.Errorf("len(entries) = %d, want 6", len(snapshot.Entries))
+	}
+}
+
+func TestComparableRuns(t *testing.T) {
+	twoFile := func(strategy ComparisonStrategy) *Run {
+		return &Run{Options: ValidationOptions{Comparison: strategy}}
+	}
+	nWay := func(strategy ComparisonStrategy) *Run {
+		return &Run{Options: ValidationOptions{Comparison: strategy}, Sources: &MultiSourceSummary{}}
+	}
+
+	tests := []struct {
+		name string
+		a, b *Run
+		want bool
+	}{
+		{name: "two-file runs", a: twoFile(""), b: twoFile(ComparisonNormalized), want: true},
+		{name: "N-way runs", a: nWay(ComparisonRaw), b: nWay(ComparisonRaw), want: true},
+		{name: "two-file and N-way", a: twoFile(""), b: nWay("")},
+		{name: "other strategy", a: twoFile(""), b: twoFile(ComparisonRaw)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := comparableRuns(tt.a, tt.b); got != tt.want {
+				t.Errorf("comparableRuns() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,377 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/transform"
+)
+
+// Quote styles of a CSVDialect
+const (
+	// QuoteDouble is the RFC 4180 style: fields may be enclosed in double quotes
+	QuoteDouble = `"`
+	// QuoteNone keeps quotes as data, for exports that do not quote fields properly
+	QuoteNone = "none"
+)
+
+// encodingUTF8 is the name of the default encoding
+const encodingUTF8 = "utf-8"
+
+// CSVDialect describes how a delimited text file is encoded and split into fields
+type CSVDialect struct {
+	// Delimiter is the field separator, e.g. ",", ";" or "\t"
+	Delimiter string `json:"delimiter"`
+	// Quote is the quote style, QuoteDouble or QuoteNone
+	Quote string `json:"quote"`
+	// Encoding is the character encoding, e.g. "utf-8", "utf-16le" or "windows-1258"
+	Encoding string `json:"encoding"`
+	// BOM reports whether the file starts with a byte order mark
+	BOM bool `json:"bom"`
+	// Detected lists the settings that were detected rather than given as options
+	Detected []string `json:"detected,omitempty"`
+}
+
+// String formats the dialect for logs and reports, e.g. `delimiter ";", quote ", encoding windows-1252`
+func (d CSVDialect) String() string {
+	s := fmt.Sprintf("delimiter %q, quote %s, encoding %s", d.Delimiter, d.Quote, d.Encoding)
+	if d.BOM {
+		s += " with BOM"
+	}
+	return s
+}
+
+// delimiterNames are the names accepted for delimiters besides the character itself
+var delimiterNames = map[string]rune{
+	"comma":     ',',
+	"semicolon": ';',
+	"tab":       '\t',
+	`\t`:        '\t',
+	"pipe":      '|',
+}
+
+// delimiterCandidates are the delimiters tried by detection, in order of preference on ties
+var delimiterCandidates = []rune{',', ';', '\t', '|'}
+
+// ParseDelimiter parses a delimiter option: a single character or one of comma, semicolon, tab and pipe
+func ParseDelimiter(spec string) (rune, error) {
+	if r, ok := delimiterNames[strings.ToLower(spec)]; ok {
+		return r, nil
+	}
+	r, size := utf8.DecodeRuneInString(spec)
+	if size == 0 || size != len(spec) || r == utf8.RuneError || r == '"' || r == '\r' || r == '\n' {
+		return 0, fmt.Errorf("invalid delimiter %q: use a single character or comma, semicolon, tab or pipe", spec)
+	}
+	return r, nil
+}
+
+// parseQuote parses a quote style option
+func parseQuote(spec string) (string, error) {
+	switch strings.ToLower(spec) {
+	case `"`, "double":
+		return QuoteDouble, nil
+	case "none":
+		return QuoteNone, nil
+	}
+	return "", fmt.Errorf(`invalid quote style %q: use " (or double) or none`, spec)
+}
+
+// lookupEncoding returns the encoding with the given name or label, e.g. "windows-1252",
+// "latin1" or "utf-16le", and its canonical name. UTF-8 has a nil encoding.
+func lookupEncoding(name string) (encoding.Encoding, string, error) {
+	enc, err := htmlindex.Get(strings.TrimSpace(name))
+	if err != nil {
+		return nil, "", fmt.Errorf("unknown encoding %q", name)
+	}
+	canonical, err := htmlindex.Name(enc)
+	if err != nil {
+		return nil, "", fmt.Errorf("unknown encoding %q", name)
+	}
+	if canonical == encodingUTF8 {
+		return nil, canonical, nil
+	}
+	return enc, canonical, nil
+}
+
+// ValidateDialectOptions checks the delimiter, quote and encoding options
+func ValidateDialectOptions(opts ExtractOptions) error {
+	if opts.Delimiter != "" {
+		if _, err := ParseDelimiter(opts.Delimiter); err != nil {
+			return err
+		}
+	}
+	if opts.Quote != "" {
+		if _, err := parseQuote(opts.Quote); err != nil {
+			return err
+		}
+	}
+	if opts.Encoding != "" {
+		if _, _, err := lookupEncoding(opts.Encoding); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// openText determines the encoding of a text file from the encoding option, else its BOM, else
+// its first bytes, and returns a reader of the file content as UTF-8 without BOM, together
+// with the decoded first bytes, whether they are only the start of the content, and the
+// dialect with the encoding and BOM set. Line-based and delimited files are read through it
+// alike.
+func openText(r io.Reader, opts ExtractOptions) (io.Reader, []byte, bool, *CSVDialect, error) {
+	buffered := bufio.NewReaderSize(r, 2*sniffSize)
+	head, err := buffered.Peek(sniffSize)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, nil, false, nil, err
+	}
+	truncated := len(head) == sniffSize
+	sample := append([]byte(nil), head...)
+
+	dialect := &CSVDialect{}
+	bomEncoding, bomLength := detectBOM(sample)
+	if bomLength > 0 {
+		dialect.BOM = true
+		sample = sample[bomLength:]
+		if _, err := buffered.Discard(bomLength); err != nil {
+			return nil, nil, false, nil, err
+		}
+	}
+
+	// Encoding: the option, else the BOM, else the content
+	var enc encoding.Encoding
+	switch {
+	case opts.Encoding != "":
+		enc, dialect.Encoding, err = lookupEncoding(opts.Encoding)
+	case bomLength > 0:
+		enc, dialect.Encoding, err = lookupEncoding(bomEncoding)
+	default:
+		enc, dialect.Encoding, err = lookupEncoding(detectEncoding(sample))
+		dialect.Detected = append(dialect.Detected, "encoding")
+	}
+	if err != nil {
+		return nil, nil, false, nil, err
+	}
+
+	var input io.Reader = buffered
+	text := sample
+	if enc != nil {
+		input = transform.NewReader(buffered, enc.NewDecoder())
+		if strings.HasPrefix(dialect.Encoding, "utf-16") {
+			text = text[:len(text)&^1]
+		}
+		if text, err = enc.NewDecoder().Bytes(text); err != nil {
+			return nil, nil, false, nil, &FileParseError{Err: fmt.Errorf("cannot decode file as %s: %w", dialect.Encoding, err)}
+		}
+	}
+	return input, text, truncated, dialect, nil
+}
+
+// openDialect determines the dialect of a delimited file from the options and its first bytes,
+// and returns a reader of the file content as UTF-8 without BOM. A defaultDelimiter of 0
+// detects the delimiter; otherwise it is used when no delimiter option is given.
+func openDialect(r io.Reader, opts ExtractOptions, defaultDelimiter rune) (io.Reader, *CSVDialect, error) {
+	input, text, truncated, dialect, err := openText(r, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Only complete lines of a truncated sample are analysed
+	sampleText := string(text)
+	if truncated {
+		if i := strings.LastIndexByte(sampleText, '\n'); i >= 0 {
+			sampleText = sampleText[:i+1]
+		}
+	}
+
+	delimiter := defaultDelimiter
+	switch {
+	case opts.Delimiter != "":
+		if delimiter, err = ParseDelimiter(opts.Delimiter); err != nil {
+			return nil, nil, err
+		}
+	case delimiter == 0:
+		delimiter = detectDelimiter(sampleText)
+		dialect.Detected = append(dialect.Detected, "delimiter")
+	}
+	dialect.Delimiter = string(delimiter)
+
+	switch {
+	case opts.Quote != "":
+		if dialect.Quote, err = parseQuote(opts.Quote); err != nil {
+			return nil, nil, err
+		}
+	case delimiter == '\t' && defaultDelimiter == '\t':
+		// Tab-separated dumps rarely quote fields properly, so stray quotes are tolerated
+		dialect.Quote = QuoteNone
+	default:
+		dialect.Quote = detectQuote(sampleText, delimiter, truncated)
+		dialect.Detected = append(dialect.Detected, "quote")
+	}
+
+	return input, dialect, nil
+}
+
+// apply configures a CSV reader for the dialect
+func (d CSVDialect) apply(reader *csv.Reader) {
+	reader.Comma, _ = utf8.DecodeRuneInString(d.Delimiter)
+	reader.LazyQuotes = d.Quote == QuoteNone
+}
+
+// detectBOM returns the encoding announced by a byte order mark at the start of b, and its length
+func detectBOM(b []byte) (string, int) {
+	switch {
+	case bytes.HasPrefix(b, []byte(utf8BOM)):
+		return encodingUTF8, len(utf8BOM)
+	case bytes.HasPrefix(b, []byte{0xFF, 0xFE}):
+		return "utf-16le", 2
+	case bytes.HasPrefix(b, []byte{0xFE, 0xFF}):
+		return "utf-16be", 2
+	}
+	return "", 0
+}
+
+// detectEncoding guesses the encoding of text without a BOM: UTF-16 when every other byte is
+// zero, UTF-8 when the bytes are valid UTF-8, else a Windows code page
+func detectEncoding(sample []byte) string {
+	if name, ok := detectUTF16(sample); ok {
+		return name
+	}
+	if validUTF8Prefix(sample) {
+		return encodingUTF8
+	}
+	return detectCodePage(sample)
+}
+
+// detectUTF16 recognises UTF-16 text without BOM by the zero high bytes of ASCII characters
+func detectUTF16(sample []byte) (string, bool) {
+	if len(sample) < 4 {
+		return "", false
+	}
+	evenZeros, oddZeros := 0, 0
+	for i, b := range sample {
+		if b == 0 {
+			if i%2 == 0 {
+				evenZeros++
+			} else {
+				oddZeros++
+			}
+		}
+	}
+	half := len(sample) / 2
+	switch {
+	case oddZeros > half/2 && evenZeros < oddZeros/10:
+		return "utf-16le", true
+	case evenZeros > half/2 && oddZeros < evenZeros/10:
+		return "utf-16be", true
+	}
+	return "", false
+}
+
+// validUTF8Prefix reports whether b is valid UTF-8, allowing a character cut off at the end
+func validUTF8Prefix(b []byte) bool {
+	if utf8.Valid(b) {
+		return true
+	}
+	for cut := 1; cut <= utf8.UTFMax-1 && cut < len(b); cut++ {
+		if utf8.Valid(b[:len(b)-cut]) && !utf8.FullRune(b[len(b)-cut:]) {
+			return true
+		}
+	}
+	return false
+}
+
+// vietnameseBytes are the windows-1258 bytes of letters and tone marks specific to Vietnamese
+// (đ, ư, ơ and the combining tone marks). In windows-1252 they are letters rarely used in
+// Western European names, such as ð, ý, õ and ì.
+var vietnameseBytes = map[byte]bool{
+	0xD0: true, 0xF0: true, // Đ đ
+	0xDD: true, 0xFD: true, // Ư ư
+	0xD5: true, 0xF5: true, // Ơ ơ
+	0xCC: true, 0xEC: true, 0xD2: true, 0xDE: true, 0xF2: true, // combining grave, acute, hook, tilde, dot below
+}
+
+// detectCodePage picks windows-1258 for text dominated by Vietnamese-specific bytes and
+// windows-1252 otherwise
+func detectCodePage(sample []byte) string {
+	vietnamese, other := 0, 0
+	for _, b := range sample {
+		switch {
+		case b < 0x80:
+		case vietnameseBytes[b]:
+			vietnamese++
+		default:
+			other++
+		}
+	}
+	if vietnamese > other {
+		return "windows-1258"
+	}
+	return "windows-1252"
+}
+
+// detectDelimiter picks the candidate delimiter that splits most rows of the sample into the
+// same number of fields, preferring more fields and then the order of delimiterCandidates.
+// Files with a single column default to a comma.
+func detectDelimiter(sample string) rune {
+	best, bestRows, bestFields := ',', 0, 0
+	for _, candidate := range delimiterCandidates {
+		reader := csv.NewReader(strings.NewReader(sample))
+		reader.Comma = candidate
+		reader.FieldsPerRecord = -1
+		reader.LazyQuotes = true
+
+		counts := make(map[int]int)
+		for {
+			record, err := reader.Read()
+			if err != nil {
+				break
+			}
+			counts[len(record)]++
+		}
+
+		// The most common field count, and how many rows have it
+		fields, rows := 0, 0
+		for n, count := range counts {
+			if count > rows || count == rows && n > fields {
+				fields, rows = n, count
+			}
+		}
+		if fields < 2 {
+			continue
+		}
+		if rows > bestRows || rows == bestRows && fields > bestFields {
+			best, bestRows, bestFields = candidate, rows, fields
+		}
+	}
+	return best
+}
+
+// detectQuote returns QuoteNone when the sample has quotes that are not valid RFC 4180 quoting.
+// In a truncated sample, a quoted field left open on the last line is not an error.
+func detectQuote(sample string, delimiter rune, truncated bool) string {
+	reader := csv.NewReader(strings.NewReader(sample))
+	reader.Comma = delimiter
+	reader.FieldsPerRecord = -1
+	for {
+		_, err := reader.Read()
+		if err == nil {
+			continue
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) && (errors.Is(err, csv.ErrBareQuote) || errors.Is(err, csv.ErrQuote)) {
+			if truncated && errors.Is(err, csv.ErrQuote) && parseErr.Line >= strings.Count(sample, "\n") {
+				return QuoteDouble
+			}
+			return QuoteNone
+		}
+		return QuoteDouble
+	}
+}
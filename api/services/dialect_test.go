@@ -0,0 +1,179 @@
+package services
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+// utf16LE encodes s as UTF-16 little endian without BOM
+func utf16LE(s string) []byte {
+	var b []byte
+	for _, u := range utf16.Encode([]rune(s)) {
+		b = append(b, byte(u), byte(u>>8))
+	}
+	return b
+}
+
+func TestDetectDelimiter(t *testing.T) {
+	tests := []struct {
+		name   string
+		sample string
+		want   rune
+	}{
+		{name: "comma", sample: "email,name\na@x.com,Ann\n", want: ','},
+		{name: "semicolon", sample: "email;name\na@x.com;Ann\n", want: ';'},
+		{name: "tab", sample: "email\tname\na@x.com\tAnn\n", want: '\t'},
+		{name: "pipe", sample: "email|name\na@x.com|Ann\n", want: '|'},
+		{name: "single column defaults to comma", sample: "email\na@x.com\n", want: ','},
+		{name: "consistent rows win over more fields", sample: "email;name\na@x.com;Doe, Ann\nb@x.com;Bo\n", want: ';'},
+		{name: "ties prefer more fields", sample: "a,b;c;d\ne,f;g;h\n", want: ';'},
+		{name: "quoted delimiters are ignored", sample: "name;email\n\"Doe; Ann\";a@x.com\n", want: ';'},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectDelimiter(tt.sample); got != tt.want {
+				t.Errorf("detectDelimiter() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectQuote(t *testing.T) {
+	tests := []struct {
+		name      string
+		sample    string
+		truncated bool
+		want      string
+	}{
+		{name: "quoted fields", sample: "\"Doe, Ann\",a@x.com\n", want: QuoteDouble},
+		{name: "no quotes", sample: "Ann,a@x.com\n", want: QuoteDouble},
+		{name: "bare quote", sample: "Ann \"Jo\" Doe,a@x.com\n", want: QuoteNone},
+		{name: "unterminated quote", sample: "\"Ann,a@x.com\nBo,b@x.com\n", want: QuoteNone},
+		{name: "open quote at the end of a truncated sample", sample: "Ann,a@x.com\n\"Bo\nDoe", truncated: true, want: QuoteDouble},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectQuote(tt.sample, ',', tt.truncated); got != tt.want {
+				t.Errorf("detectQuote() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectEncoding(t *testing.T) {
+	tests := []struct {
+		name   string
+		sample []byte
+		want   string
+	}{
+		{name: "ascii", sample: []byte("email\na@x.com\n"), want: encodingUTF8},
+		{name: "utf-8", sample: []byte("name\nNguyễn Văn Đức\n"), want: encodingUTF8},
+		{name: "utf-8 cut off mid character", sample: []byte("Đức")[:4], want: encodingUTF8},
+		{name: "utf-16le without BOM", sample: utf16LE("email\na@x.com\n"), want: "utf-16le"},
+		{name: "windows-1252", sample: []byte("name\nJos\xe9 M\xfcller\n"), want: "windows-1252"},
+		{name: "windows-1258", sample: []byte("name\nNguy\xean V\xe3n \xd0\xf4\xf2\n\xd0\xf0\xfd\xf5\n"), want: "windows-1258"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectEncoding(tt.sample); got != tt.want {
+				t.Errorf("detectEncoding() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenDialect(t *testing.T) {
+	tests := []struct {
+		name             string
+		content          []byte
+		opts             ExtractOptions
+		defaultDelimiter rune
+		want             CSVDialect
+		wantText         string
+	}{
+		{
+			name:     "detected",
+			content:  []byte("email;name\na@x.com;Ann\n"),
+			want:     CSVDialect{Delimiter: ";", Quote: QuoteDouble, Encoding: encodingUTF8, Detected: []string{"encoding", "delimiter", "quote"}},
+			wantText: "email;name\na@x.com;Ann\n",
+		},
+		{
+			name:     "utf-8 BOM is stripped",
+			content:  []byte("\xef\xbb\xbfemail,name\na@x.com,Ann\n"),
+			want:     CSVDialect{Delimiter: ",", Quote: QuoteDouble, Encoding: encodingUTF8, BOM: true, Detected: []string{"delimiter", "quote"}},
+			wantText: "email,name\na@x.com,Ann\n",
+		},
+		{
+			name:     "utf-16le BOM is decoded",
+			content:  append([]byte{0xFF, 0xFE}, utf16LE("email\tname\na@x.com\tAnn\n")...),
+			want:     CSVDialect{Delimiter: "\t", Quote: QuoteDouble, Encoding: "utf-16le", BOM: true, Detected: []string{"delimiter", "quote"}},
+			wantText: "email\tname\na@x.com\tAnn\n",
+		},
+		{
+			name:     "options override detection",
+			content:  []byte("email;name\na@x.com;Jos\xe9\n"),
+			opts:     ExtractOptions{Delimiter: "comma", Quote: "none", Encoding: "latin1"},
+			want:     CSVDialect{Delimiter: ",", Quote: QuoteNone, Encoding: "windows-1252"},
+			wantText: "email;name\na@x.com;José\n",
+		},
+		{
+			name:             "tab-separated files keep stray quotes",
+			content:          []byte("email\tname\na@x.com\tAnn \"Jo\"\n"),
+			defaultDelimiter: '\t',
+			want:             CSVDialect{Delimiter: "\t", Quote: QuoteNone, Encoding: encodingUTF8, Detected: []string{"encoding"}},
+			wantText:         "email\tname\na@x.com\tAnn \"Jo\"\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input, dialect, err := openDialect(strings.NewReader(string(tt.content)), tt.opts, tt.defaultDelimiter)
+			if err != nil {
+				t.Fatalf("openDialect() error = %v", err)
+			}
+			if !reflect.DeepEqual(*dialect, tt.want) {
+				t.Errorf("dialect = %+v, want %+v", *dialect, tt.want)
+			}
+			text, err := io.ReadAll(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(text) != tt.wantText {
+				t.Errorf("text = %q, want %q", text, tt.wantText)
+			}
+		})
+	}
+}
+
+func TestValidateDialectOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    ExtractOptions
+		wantErr bool
+	}{
+		{name: "none"},
+		{name: "named delimiter", opts: ExtractOptions{Delimiter: "Semicolon"}},
+		{name: "character delimiter", opts: ExtractOptions{Delimiter: "#"}},
+		{name: "escaped tab", opts: ExtractOptions{Delimiter: `\t`}},
+		{name: "several characters", opts: ExtractOptions{Delimiter: ",;"}, wantErr: true},
+		{name: "quote as delimiter", opts: ExtractOptions{Delimiter: `"`}, wantErr: true},
+		{name: "double quote", opts: ExtractOptions{Quote: "double"}},
+		{name: "unknown quote", opts: ExtractOptions{Quote: "'"}, wantErr: true},
+		{name: "encoding label", opts: ExtractOptions{Encoding: "latin1"}},
+		{name: "unknown encoding", opts: ExtractOptions{Encoding: "klingon"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateDialectOptions(tt.opts); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateDialectOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
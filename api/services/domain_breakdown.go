@@ -0,0 +1,196 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"ness-to-odoo-golang-validation-api-tool/store"
+)
+
+// runDomainsBucket keeps the domain breakdown of every completed run
+const runDomainsBucket = "run_domains"
+
+// DomainStats are the counts of the emails of one registrable domain across both files
+type DomainStats struct {
+	// Domain is the registrable domain per the Public Suffix List, e.g. "corp.vn" for both
+	// "corp.vn" and "mail.corp.vn", or "(none)" for emails without a domain
+	Domain string `json:"domain"`
+	// Total counts the compared emails of the domain, so it is the sum of Matching,
+	// MissingInFirst, MissingInSecond, KeyMatches and ProbableMatches; Valid counts the valid ones
+	Total           int `json:"total"`
+	Valid           int `json:"valid"`
+	Matching        int `json:"matching"`
+	MissingInFirst  int `json:"missingInFirst"`
+	MissingInSecond int `json:"missingInSecond"`
+	KeyMatches      int `json:"keyMatches"`
+	ProbableMatches int `json:"probableMatches"`
+	// Duplicates counts the repeated occurrences of an email in the same file
+	Duplicates int `json:"duplicates"`
+}
+
+// domainStatsColumns are the column titles of the domain breakdown in the reports
+var domainStatsColumns = []string{"Domain", "Total", "Valid", "Matching", "Missing in First File", "Missing in Second File",
+	"Matched by Other Key", "Probable Matches", "Duplicates"}
+
+// row returns the cells of the stats in the order of domainStatsColumns
+func (s DomainStats) row() []interface{} {
+	return []interface{}{s.Domain, s.Total, s.Valid, s.Matching, s.MissingInFirst, s.MissingInSecond,
+		s.KeyMatches, s.ProbableMatches, s.Duplicates}
+}
+
+// DomainFilter selects and orders the domains of a run in GetRunDomains. Zero values do not filter.
+type DomainFilter struct {
+	// Domain matches the domains that contain it, case-insensitively
+	Domain string
+	// Missing keeps the domains with emails missing in the "first" file, the "second" file, or "any"
+	Missing string
+	// Sort is the field to sort by, largest first except for "domain" (default: "total")
+	Sort  string
+	Limit int
+}
+
+// domainSortFields maps the sortable fields of the stats to their values
+var domainSortFields = map[string]func(DomainStats) int{
+	"total":           func(s DomainStats) int { return s.Total },
+	"valid":           func(s DomainStats) int { return s.Valid },
+	"matching":        func(s DomainStats) int { return s.Matching },
+	"missingInFirst":  func(s DomainStats) int { return s.MissingInFirst },
+	"missingInSecond": func(s DomainStats) int { return s.MissingInSecond },
+	"keyMatches":      func(s DomainStats) int { return s.KeyMatches },
+	"probableMatches": func(s DomainStats) int { return s.ProbableMatches },
+	"duplicates":      func(s DomainStats) int { return s.Duplicates },
+}
+
+// Validate checks the missing and sort options of the filter
+func (f DomainFilter) Validate() error {
+	switch f.Missing {
+	case "", "first", "second", "any":
+	default:
+		return fmt.Errorf("missing must be first, second or any, got %q", f.Missing)
+	}
+	if _, ok := domainSortFields[f.Sort]; !ok && f.Sort != "" && f.Sort != "domain" {
+		return fmt.Errorf("unsupported sort field %q", f.Sort)
+	}
+	return nil
+}
+
+// domainBreakdown counts the emails of a report by registrable domain, largest domains first.
+// Every compared email is counted in the category it ended up in, and key and probable matches
+// by the email of the first file.
+func domainBreakdown(ctx context.Context, report *ReportData) ([]DomainStats, error) {
+	stats := make(map[string]*DomainStats)
+	domainOf := func(entry EmailEntry) *DomainStats {
+		domain := emailDomain(strings.ToLower(entry.NormalizedEmail))
+		if domain != "(none)" {
+			domain = registrableDomain(domain)
+		}
+		if stats[domain] == nil {
+			stats[domain] = &DomainStats{Domain: domain}
+		}
+		return stats[domain]
+	}
+
+	for _, entries := range [][]EmailEntry{report.FirstEntries, report.SecondEntries} {
+		seen := make(map[string]struct{}, len(entries))
+		for i, entry := range entries {
+			if err := checkCancelled(ctx, i); err != nil {
+				return nil, err
+			}
+			if _, ok := seen[entry.NormalizedEmail]; ok {
+				domainOf(entry).Duplicates++
+			}
+			seen[entry.NormalizedEmail] = struct{}{}
+		}
+	}
+	categories := []struct {
+		entries []EmailEntry
+		field   func(*DomainStats) *int
+	}{
+		{report.Matching, func(s *DomainStats) *int { return &s.Matching }},
+		{report.MissingInFirst, func(s *DomainStats) *int { return &s.MissingInFirst }},
+		{report.MissingInSecond, func(s *DomainStats) *int { return &s.MissingInSecond }},
+		{keyMatchEntries(report.KeyMatches), func(s *DomainStats) *int { return &s.KeyMatches }},
+		{probableEntries(report.ProbableMatches), func(s *DomainStats) *int { return &s.ProbableMatches }},
+	}
+	for _, category := range categories {
+		for i, entry := range category.entries {
+			if err := checkCancelled(ctx, i); err != nil {
+				return nil, err
+			}
+			domain := domainOf(entry)
+			*category.field(domain)++
+			domain.Total++
+			if entry.IsValid {
+				domain.Valid++
+			}
+		}
+	}
+
+	domains := make([]DomainStats, 0, len(stats))
+	for _, s := range stats {
+		domains = append(domains, *s)
+	}
+	sortDomains(domains, "total")
+	return domains, nil
+}
+
+// sortDomains sorts domains by a field of domainSortFields, largest first, or by name for
+// "domain". Ties are sorted by name.
+func sortDomains(domains []DomainStats, field string) {
+	value, ok := domainSortFields[field]
+	sort.Slice(domains, func(i, j int) bool {
+		if ok && value(domains[i]) != value(domains[j]) {
+			return value(domains[i]) > value(domains[j])
+		}
+		return domains[i].Domain < domains[j].Domain
+	})
+}
+
+// GetRunDomains returns the domain breakdown of a stored run, filtered and sorted by filter.
+// Runs that did not complete, or were recorded before domains were kept, have none.
+func GetRunDomains(id string, filter DomainFilter) ([]DomainStats, error) {
+	if _, err := GetRun(id); err != nil {
+		return nil, err
+	}
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	stored := make([]DomainStats, 0)
+	if err := store.Get().Load(runDomainsBucket, id, &stored); err != nil && !errors.Is(err, store.ErrNotFound) {
+		return nil, err
+	}
+
+	domains := make([]DomainStats, 0, len(stored))
+	for _, s := range stored {
+		if filter.Domain != "" && !strings.Contains(s.Domain, strings.ToLower(filter.Domain)) {
+			continue
+		}
+		switch filter.Missing {
+		case "first":
+			if s.MissingInFirst == 0 {
+				continue
+			}
+		case "second":
+			if s.MissingInSecond == 0 {
+				continue
+			}
+		case "any":
+			if s.MissingInFirst == 0 && s.MissingInSecond == 0 {
+				continue
+			}
+		}
+		domains = append(domains, s)
+	}
+
+	if filter.Sort != "" {
+		sortDomains(domains, filter.Sort)
+	}
+	if filter.Limit > 0 && len(domains) > filter.Limit {
+		domains = domains[:filter.Limit]
+	}
+	return domains, nil
+}
@@ -0,0 +1,44 @@
+package services
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestDomainBreakdown(t *testing.T) {
+	entry := func(email string, valid bool) EmailEntry {
+		return EmailEntry{Email: email, NormalizedEmail: email, IsValid: valid}
+	}
+	report := &ReportData{
+		FirstEntries: []EmailEntry{
+			entry("a@corp.vn", true), entry("a@corp.vn", true), entry("b@mail.corp.vn", true),
+			entry("nguyen.vana@corp.vn", true), entry("old@corp.vn", true), entry("x@other.com", false),
+		},
+		SecondEntries: []EmailEntry{
+			entry("a@corp.vn", true), entry("c@corp.vn", true), entry("nguyenvana@corp.vn", true), entry("new@corp.vn", true),
+		},
+		Matching:        []EmailEntry{entry("a@corp.vn", true)},
+		MissingInFirst:  []EmailEntry{entry("c@corp.vn", true)},
+		MissingInSecond: []EmailEntry{entry("b@mail.corp.vn", true), entry("x@other.com", false)},
+		KeyMatches:      []KeyMatch{{First: entry("old@corp.vn", true), Second: entry("new@corp.vn", true)}},
+		ProbableMatches: []ProbableMatch{{First: entry("nguyen.vana@corp.vn", true), Second: entry("nguyenvana@corp.vn", true)}},
+	}
+
+	domains, err := domainBreakdown(context.Background(), report)
+	if err != nil {
+		t.Fatalf("domainBreakdown() error = %v", err)
+	}
+	want := []DomainStats{
+		{Domain: "corp.vn", Total: 5, Valid: 5, Matching: 1, MissingInFirst: 1, MissingInSecond: 1, KeyMatches: 1, ProbableMatches: 1, Duplicates: 1},
+		{Domain: "other.com", Total: 1, MissingInSecond: 1},
+	}
+	if !reflect.DeepEqual(domains, want) {
+		t.Errorf("domainBreakdown() = %+v, want %+v", domains, want)
+	}
+	for _, domain := range domains {
+		if sum := domain.Matching + domain.MissingInFirst + domain.MissingInSecond + domain.KeyMatches + domain.ProbableMatches; sum != domain.Total {
+			t.Errorf("categories of %s add up to %d, want the total %d", domain.Domain, sum, domain.Total)
+		}
+	}
+}
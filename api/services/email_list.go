@@ -0,0 +1,88 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"ness-to-odoo-golang-validation-api-tool/config"
+	"ness-to-odoo-golang-validation-api-tool/utils"
+)
+
+// EmailListResult is the validation of a list of emails given without a file
+type EmailListResult struct {
+	// Results are in the order of the list, one per email
+	Results []utils.EmailValidationResult `json:"results"`
+	Summary EmailListSummary              `json:"summary"`
+}
+
+// EmailListSummary counts the results of a list validation
+type EmailListSummary struct {
+	Total      int `json:"total"`
+	Valid      int `json:"valid"`
+	Invalid    int `json:"invalid"`
+	Disposable int `json:"disposable"`
+	// Unique is the number of distinct normalized emails
+	Unique int `json:"unique"`
+	// Reasons counts the invalid emails by reason code
+	Reasons map[string]int `json:"reasons"`
+	// DomainCheck reports whether email domains were resolved, as in file validations
+	DomainCheck           bool    `json:"domainCheck"`
+	ProcessingTimeSeconds float64 `json:"processingTimeSeconds"`
+}
+
+// disposableReason is the reason of valid emails of a disposable provider in list validations and lookups
+const disposableReason = "Email domain is a disposable email provider"
+
+// withDisposableReason gives a valid email of a disposable provider the disposable reason. File
+// reports leave it out, so that their Reason column only explains invalid emails.
+func withDisposableReason(result utils.EmailValidationResult) utils.EmailValidationResult {
+	if result.IsValid && result.IsDisposable {
+		result.Reason = disposableReason
+		result.ReasonCode = utils.ReasonDisposable
+	}
+	return result
+}
+
+// ValidateEmailList validates a list of emails with the same checks as the emails of uploaded
+// files, and counts the results
+func ValidateEmailList(ctx context.Context, emails []string) (*EmailListResult, error) {
+	logger := utils.GetLogger()
+	startTime := time.Now()
+
+	if maxEmails := config.Get().MaxBatchEmails; maxEmails > 0 && len(emails) > maxEmails {
+		return nil, &TooLargeError{Subject: "email list", Size: int64(len(emails)), Limit: int64(maxEmails), Unit: "emails"}
+	}
+
+	results, err := utils.ValidateEmailsBatch(ctx, emails)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := EmailListSummary{
+		Total:       len(results),
+		Reasons:     make(map[string]int),
+		DomainCheck: utils.DomainCheckEnabled(),
+	}
+	unique := make(map[string]struct{}, len(results))
+	for i, result := range results {
+		if err := checkCancelled(ctx, i); err != nil {
+			return nil, err
+		}
+		results[i] = withDisposableReason(result)
+		if result.IsValid {
+			summary.Valid++
+		} else {
+			summary.Invalid++
+			summary.Reasons[result.ReasonCode]++
+		}
+		if result.IsDisposable {
+			summary.Disposable++
+		}
+		unique[result.NormalizedEmail] = struct{}{}
+	}
+	summary.Unique = len(unique)
+	summary.ProcessingTimeSeconds = time.Since(startTime).Seconds()
+
+	logger.Info("Validated a list of %d emails: %d valid, %d invalid", summary.Total, summary.Valid, summary.Invalid)
+	return &EmailListResult{Results: results, Summary: summary}, nil
+}
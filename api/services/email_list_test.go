@@ -0,0 +1,47 @@
+package services
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"ness-to-odoo-golang-validation-api-tool/utils"
+)
+
+func TestValidateEmailListDisposable(t *testing.T) {
+	result, err := ValidateEmailList(context.Background(), []string{"ann@example.com", "ann@mailinator.com", "ann.example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var codes []string
+	for _, r := range result.Results {
+		codes = append(codes, r.ReasonCode)
+	}
+	if want := []string{"", utils.ReasonDisposable, utils.ReasonAtSign}; !reflect.DeepEqual(codes, want) {
+		t.Errorf("reason codes = %q, want %q", codes, want)
+	}
+	if disposable := result.Results[1]; !disposable.IsValid || !disposable.IsDisposable || disposable.Reason == "" {
+		t.Errorf("disposable result = %+v, want a valid disposable email with a reason", disposable)
+	}
+	if want := map[string]int{utils.ReasonAtSign: 1}; !reflect.DeepEqual(result.Summary.Reasons, want) {
+		t.Errorf("summary reasons = %v, want %v", result.Summary.Reasons, want)
+	}
+	if result.Summary.Disposable != 1 {
+		t.Errorf("summary disposable = %d, want 1", result.Summary.Disposable)
+	}
+
+	if lookup := LookupEmail("ann@mailinator.com"); lookup.Result.ReasonCode != utils.ReasonDisposable {
+		t.Errorf("LookupEmail() reason code = %q, want %q", lookup.Result.ReasonCode, utils.ReasonDisposable)
+	}
+}
+
+func TestValidateEmailListFileReason(t *testing.T) {
+	entries, err := validateEmailList(context.Background(), []ExtractedEmail{{Email: "ann@mailinator.com"}}, "input.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || !entries[0].IsDisposable || entries[0].Reason != "" {
+		t.Errorf("file entries = %+v, want a disposable email without a reason", entries)
+	}
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"ness-to-odoo-golang-validation-api-tool/utils"
+)
+
+// commonMailDomains are the mailbox providers checked for typos in the domain of an email
+var commonMailDomains = []string{
+	"gmail.com", "googlemail.com", "yahoo.com", "yahoo.com.vn", "ymail.com", "hotmail.com",
+	"outlook.com", "live.com", "msn.com", "icloud.com", "me.com", "aol.com", "mail.com",
+	"protonmail.com", "zoho.com",
+}
+
+// EmailLookup explains the validation of a single email
+type EmailLookup struct {
+	Result utils.EmailValidationResult `json:"result"`
+	utils.EmailTrace
+	Suggestions []EmailSuggestion `json:"suggestions"`
+}
+
+// EmailSuggestion is a likely fix of an email
+type EmailSuggestion struct {
+	// Email is the corrected address, when the fix can be applied automatically
+	Email  string `json:"email,omitempty"`
+	Reason string `json:"reason"`
+}
+
+// LookupEmail validates an email with the same checks as file validations, and explains the
+// normalization rules applied, the checks performed and the likely fixes
+func LookupEmail(email string) EmailLookup {
+	result, trace := utils.ExplainEmail(email)
+	return EmailLookup{
+		Result:      withDisposableReason(result),
+		EmailTrace:  trace,
+		Suggestions: emailSuggestions(strings.TrimSpace(email)),
+	}
+}
+
+// emailSuggestions returns the likely fixes of an email: spaces inside it, a missing or repeated
+// @, an empty part, a domain without a top-level domain and typos of common mail domains
+func emailSuggestions(email string) []EmailSuggestion {
+	suggestions := make([]EmailSuggestion, 0)
+	if email == "" {
+		return suggestions
+	}
+	if strings.ContainsAny(email, " \t") {
+		fixed := strings.Join(strings.Fields(email), "")
+		suggestions = append(suggestions, EmailSuggestion{Email: fixed, Reason: "Remove the spaces inside the address"})
+		email = fixed
+	}
+
+	local, domain, found := strings.Cut(email, "@")
+	switch {
+	case !found:
+		return append(suggestions, EmailSuggestion{Reason: "Add the @ between the mailbox name and the domain"})
+	case strings.Contains(domain, "@"):
+		return append(suggestions, EmailSuggestion{Reason: "An address has a single @; several addresses may have been pasted together"})
+	case local == "":
+		suggestions = append(suggestions, EmailSuggestion{Reason: "The mailbox name before the @ is empty"})
+	}
+	if domain == "" {
+		return append(suggestions, EmailSuggestion{Reason: "The domain after the @ is empty"})
+	}
+	if !strings.Contains(domain, ".") {
+		suggestions = append(suggestions, EmailSuggestion{
+			Reason: fmt.Sprintf("The domain %s has no top-level domain, such as .com", domain),
+		})
+	}
+	if typo, ok := commonDomainTypo(strings.ToLower(domain)); ok {
+		suggestions = append(suggestions, EmailSuggestion{
+			Email:  local + "@" + typo,
+			Reason: fmt.Sprintf("%s looks like a typo of %s", domain, typo),
+		})
+	}
+	return suggestions
+}
+
+// commonDomainTypo returns the common mail domain closest to domain when it is one or two edits
+// away (one for short domains), and false when domain is a common domain itself
+func commonDomainTypo(domain string) (string, bool) {
+	best, bestDistance := "", 0
+	runes := []rune(domain)
+	for _, common := range commonMailDomains {
+		if common == domain {
+			return "", false
+		}
+		maxDistance := 2
+		if len(common) < 8 {
+			maxDistance = 1
+		}
+		distance := editDistance(runes, []rune(common), maxDistance)
+		if distance <= maxDistance && (best == "" || distance < bestDistance) {
+			best, bestDistance = common, distance
+		}
+	}
+	return best, best != ""
+}
@@ -1,62 +1,177 @@
 package services
 
 import (
+	"context"
 	"encoding/csv"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
-	"strings"
+	"strconv"
 	"sync"
 	"time"
 
-	"github.com/xuri/excelize/v2"
+	"ness-to-odoo-golang-validation-api-tool/config"
 	"ness-to-odoo-golang-validation-api-tool/utils"
 )
 
 // EmailEntry represents an email entry with validation details
 type EmailEntry struct {
-	Email           string `json:"email"`
+	Email string `json:"email"`
+	// DisplayName is the name given with the address in the input, if any
+	DisplayName     string `json:"displayName,omitempty"`
 	Source          string `json:"source"`
 	IsValid         bool   `json:"isValid"`
 	IsDisposable    bool   `json:"isDisposable"`
 	NormalizedEmail string `json:"normalizedEmail"`
 	Status          string `json:"status"`
 	Reason          string `json:"reason,omitempty"`
+	// Location is where the email was read from
+	Location SourceLocation `json:"location"`
+	// MatchedLocation is where a matching email was read from in the other file
+	MatchedLocation *SourceLocation `json:"matchedLocation,omitempty"`
+	// MatchedEmail and Similarity are the email of a probable match in the other file and how
+	// similar it is, from 0 to 1
+	MatchedEmail string  `json:"matchedEmail,omitempty"`
+	Similarity   float64 `json:"similarity,omitempty"`
+	// MatchedKey is the match key that paired the email with MatchedEmail, when the email changed
+	MatchedKey string `json:"matchedKey,omitempty"`
+	// Fields holds further columns of the source row, when the report needs them
+	Fields map[string]string `json:"fields,omitempty"`
 }
 
 // ValidationResult represents the result of email validation
 type ValidationResult struct {
-	MatchingEmails      []string          `json:"matchingEmails"`
-	MissingInFirstFile  []string          `json:"missingInFirstFile"`
-	MissingInSecondFile []string          `json:"missingInSecondFile"`
-	OutputFileURL       string            `json:"outputFileURL"`
-	FileName            string            `json:"fileName"`
-	Summary             ValidationSummary `json:"summary"`
+	MatchingEmails      []string `json:"matchingEmails"`
+	MissingInFirstFile  []string `json:"missingInFirstFile"`
+	MissingInSecondFile []string `json:"missingInSecondFile"`
+	// KeyMatches pairs emails missing in either file whose records have the same match key values
+	KeyMatches []KeyMatch `json:"keyMatches"`
+	// ProbableMatches pairs emails missing in either file that are near-identical
+	ProbableMatches []ProbableMatch   `json:"probableMatches"`
+	OutputFileURL   string            `json:"outputFileURL,omitempty"`
+	FileName        string            `json:"fileName"`
+	RunID           string            `json:"runId,omitempty"`
+	Summary         ValidationSummary `json:"summary"`
+	// Entries has the per-email results with the location of each email in the input files
+	Entries []RunEntry `json:"entries"`
+	// Inputs describes how each input file was read, including the dialect of CSV files
+	Inputs []InputFile `json:"inputs"`
+	// ParseErrors are the malformed rows skipped by lenient parsing
+	ParseErrors []ParseError `json:"parseErrors"`
+	// Domains counts the emails by registrable domain, largest domains first
+	Domains []DomainStats `json:"domains"`
 }
 
 // ValidationSummary contains summary statistics of the validation
 type ValidationSummary struct {
-	TotalEmailsFirstFile  int     `json:"totalEmailsFirstFile"`
-	TotalEmailsSecondFile int     `json:"totalEmailsSecondFile"`
-	ValidEmailsFirstFile  int     `json:"validEmailsFirstFile"`
-	ValidEmailsSecondFile int     `json:"validEmailsSecondFile"`
-	MatchingCount         int     `json:"matchingCount"`
-	MissingInFirstCount   int     `json:"missingInFirstCount"`
-	MissingInSecondCount  int     `json:"missingInSecondCount"`
-	DisposableEmailsCount int     `json:"disposableEmailsCount"`
+	TotalEmailsFirstFile  int `json:"totalEmailsFirstFile"`
+	TotalEmailsSecondFile int `json:"totalEmailsSecondFile"`
+	ValidEmailsFirstFile  int `json:"validEmailsFirstFile"`
+	ValidEmailsSecondFile int `json:"validEmailsSecondFile"`
+	MatchingCount         int `json:"matchingCount"`
+	MissingInFirstCount   int `json:"missingInFirstCount"`
+	MissingInSecondCount  int `json:"missingInSecondCount"`
+	// ComparisonStrategy is the key the emails were compared on, e.g. "normalized"
+	ComparisonStrategy ComparisonStrategy `json:"comparisonStrategy"`
+	// KeyMatchCount is the number of emails matched by another key because they changed, which
+	// are not counted as missing
+	KeyMatchCount int `json:"keyMatchCount"`
+	// ProbableMatchCount is the number of probable matches, whose emails are not counted as
+	// missing; ProbableMatchThreshold is the similarity they needed, 0 when disabled
+	ProbableMatchCount     int     `json:"probableMatchCount"`
+	ProbableMatchThreshold float64 `json:"probableMatchThreshold"`
+	DisposableEmailsCount  int     `json:"disposableEmailsCount"`
+	// SkippedRowsFirstFile and SkippedRowsSecondFile count the leading rows of each file that
+	// were not read as data: rows skipped with skipRows and header rows
+	SkippedRowsFirstFile  int `json:"skippedRowsFirstFile"`
+	SkippedRowsSecondFile int `json:"skippedRowsSecondFile"`
+	// ParseErrorsFirstFile and ParseErrorsSecondFile count the malformed rows of each file
+	// skipped by lenient parsing
+	ParseErrorsFirstFile  int     `json:"parseErrorsFirstFile"`
+	ParseErrorsSecondFile int     `json:"parseErrorsSecondFile"`
 	ProcessingTimeSeconds float64 `json:"processingTimeSeconds"`
 }
 
+// ValidationOptions controls how a validation run reads its inputs and writes its report
+type ValidationOptions struct {
+	// OutputFormat is the report format, one of ReportFormats(), e.g. "csv", "excel" or "odoo-csv"
+	OutputFormat string `json:"outputFormat"`
+	// OutputPath overrides the report location. By default a timestamped file is created in the temp directory.
+	OutputPath string `json:"outputPath,omitempty"`
+	// FirstFile and SecondFile select the email column and sheet of each input file
+	FirstFile  ExtractOptions `json:"firstFile"`
+	SecondFile ExtractOptions `json:"secondFile"`
+	// OdooMapping is the column mapping of the odoo-csv and odoo-xml formats. Defaults to DefaultOdooMapping().
+	OdooMapping OdooMapping `json:"odooMapping,omitempty"`
+	// Comparison is the key on which the emails are compared, one of ComparisonStrategies().
+	// Defaults to ComparisonNormalized.
+	Comparison ComparisonStrategy `json:"comparison,omitempty"`
+	// MatchKeys are the secondary keys, such as name and phone, that pair records whose email
+	// changed. They are tried in order on the emails that did not match.
+	MatchKeys []MatchKey `json:"matchKeys,omitempty"`
+	// CallbackURL receives a signed webhook when the run completes or fails
+	CallbackURL string `json:"callbackUrl,omitempty"`
+	// ProbableMatchThreshold is the similarity from which emails missing in either file are paired
+	// as probable matches; 0 disables probable matching. Defaults to the server configuration.
+	ProbableMatchThreshold *float64 `json:"probableMatchThreshold,omitempty"`
+}
+
+// cancelCheckInterval is how many loop iterations tight loops run between context checks
+const cancelCheckInterval = 1000
+
 // ValidateEmails processes two files containing emails and returns validation results
-// This version uses concurrent processing for better performance
-func ValidateEmails(firstFilePath, secondFilePath, outputFormat string) (*ValidationResult, error) {
+// This version uses concurrent processing for better performance.
+// Every stage observes ctx; when it is cancelled the partially written output file is
+// removed and the returned error wraps ctx.Err().
+func ValidateEmails(ctx context.Context, firstFilePath, secondFilePath string, opts ValidationOptions) (*ValidationResult, error) {
 	logger := utils.GetLogger()
 	logger.Info("Starting email validation process for files: %s and %s", firstFilePath, secondFilePath)
+	reporter, err := GetReporter(opts.OutputFormat)
+	if err != nil {
+		return nil, err
+	}
+	// The Odoo import files carry the mapped columns of the first file over
+	firstFileOpts, secondFileOpts := opts.FirstFile, opts.SecondFile
+	if isOdooFormat(opts.OutputFormat) {
+		if opts.OdooMapping == nil {
+			if opts.OdooMapping, err = DefaultOdooMapping(); err != nil {
+				return nil, err
+			}
+		}
+		firstFileOpts.Fields = opts.OdooMapping.Columns()
+	}
+	// Match keys are read from the columns of both files
+	firstFileOpts.Fields = matchKeyColumns(firstFileOpts.Fields, opts.MatchKeys, false)
+	secondFileOpts.Fields = matchKeyColumns(secondFileOpts.Fields, opts.MatchKeys, true)
+	defer trackJob()()
 	startTime := time.Now()
+	progress := newValidationProgress("extracting")
+
+	run := newRun(startTime, opts,
+		RunInput{Role: "first", FileName: filepath.Base(firstFilePath)},
+		RunInput{Role: "second", FileName: filepath.Base(secondFilePath)})
+	inputPaths := []string{firstFilePath, secondFilePath}
+
+	// Record failed runs, and log how far the run got if it ends because the context was cancelled
+	var runErr error
+	defer func() {
+		if runErr == nil {
+			return
+		}
+		if ctx.Err() != nil {
+			logger.Warn("Email validation cancelled after %s (%s): %v",
+				utils.FormatDuration(time.Since(startTime)), progress, ctx.Err())
+		}
+		saveFailedRun(run, inputPaths, runErr, ctx.Err() != nil)
+		notifyRun(run, "")
+	}()
+
 	// Create temp directory if it doesn't exist
-	if err := os.MkdirAll("./temp", os.ModePerm); err != nil {
-		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+	tempDir := config.Get().TempDir
+	if err := os.MkdirAll(tempDir, os.ModePerm); err != nil {
+		runErr = fmt.Errorf("failed to create temp directory: %w", err)
+		return nil, runErr
 	}
 
 	// Use a WaitGroup to process both files concurrently
@@ -65,8 +180,8 @@ func ValidateEmails(firstFilePath, secondFilePath, outputFormat string) (*Valida
 
 	// Channels for results and errors
 	type extractResult struct {
-		emails []string
-		err    error
+		*Extraction
+		err error
 	}
 	firstFileCh := make(chan extractResult, 1)
 	secondFileCh := make(chan extractResult, 1)
@@ -74,15 +189,15 @@ func ValidateEmails(firstFilePath, secondFilePath, outputFormat string) (*Valida
 	// Extract emails from first file concurrently
 	go func() {
 		defer wg.Done()
-		emails, err := extractEmails(firstFilePath)
-		firstFileCh <- extractResult{emails, err}
+		extraction, err := ExtractEmails(ctx, firstFilePath, firstFileOpts)
+		firstFileCh <- extractResult{extraction, err}
 	}()
 
 	// Extract emails from second file concurrently
 	go func() {
 		defer wg.Done()
-		emails, err := extractEmails(secondFilePath)
-		secondFileCh <- extractResult{emails, err}
+		extraction, err := ExtractEmails(ctx, secondFilePath, secondFileOpts)
+		secondFileCh <- extractResult{extraction, err}
 	}()
 
 	// Wait for both goroutines to complete
@@ -94,18 +209,27 @@ func ValidateEmails(firstFilePath, secondFilePath, outputFormat string) (*Valida
 
 	// Check for errors
 	if firstResult.err != nil {
-		return nil, fmt.Errorf("failed to extract emails from first file: %w", firstResult.err)
+		runErr = fmt.Errorf("failed to extract emails from first file: %w", firstResult.err)
+		return nil, runErr
 	}
 	if secondResult.err != nil {
-		return nil, fmt.Errorf("failed to extract emails from second file: %w", secondResult.err)
+		runErr = fmt.Errorf("failed to extract emails from second file: %w", secondResult.err)
+		return nil, runErr
 	}
+	progress.set("extracted from first file", len(firstResult.Emails))
+	progress.set("extracted from second file", len(secondResult.Emails))
+	inputs := append(firstResult.inputs("first"), secondResult.inputs("second")...)
+	run.setInputFiles(inputs)
+	parseErrors := append(firstResult.parseErrors("first"), secondResult.parseErrors("second")...)
 
 	// Process both files concurrently
+	progress.setStage("validating")
 	wg.Add(2)
 
 	// Channels for validation results
 	type validationResult struct {
 		entries []EmailEntry
+		err     error
 	}
 	firstValidationCh := make(chan validationResult, 1)
 	secondValidationCh := make(chan validationResult, 1)
@@ -113,42 +237,105 @@ func ValidateEmails(firstFilePath, secondFilePath, outputFormat string) (*Valida
 	// Validate first file emails concurrently
 	go func() {
 		defer wg.Done()
-		entries := validateEmailList(firstResult.emails, "First File")
-		firstValidationCh <- validationResult{entries}
+		entries, err := validateEmailList(ctx, firstResult.Emails, "First File")
+		firstValidationCh <- validationResult{entries, err}
 	}()
 
 	// Validate second file emails concurrently
 	go func() {
 		defer wg.Done()
-		entries := validateEmailList(secondResult.emails, "Second File")
-		secondValidationCh <- validationResult{entries}
+		entries, err := validateEmailList(ctx, secondResult.Emails, "Second File")
+		secondValidationCh <- validationResult{entries, err}
 	}()
 
 	// Wait for validation to complete
 	wg.Wait()
 
 	// Get validation results
-	firstFileEntries := (<-firstValidationCh).entries
-	secondFileEntries := (<-secondValidationCh).entries
+	firstValidation := <-firstValidationCh
+	secondValidation := <-secondValidationCh
+	if firstValidation.err != nil {
+		runErr = fmt.Errorf("failed to validate emails from first file: %w", firstValidation.err)
+		return nil, runErr
+	}
+	if secondValidation.err != nil {
+		runErr = fmt.Errorf("failed to validate emails from second file: %w", secondValidation.err)
+		return nil, runErr
+	}
+	firstFileEntries := firstValidation.entries
+	secondFileEntries := secondValidation.entries
+	progress.set("validated", len(firstFileEntries)+len(secondFileEntries))
+
+	// Compare emails on the key of the comparison strategy, the normalized emails by default
+	progress.setStage("comparing")
+	matchingEmails, missingInFirst, missingInSecond, summary, err := compareEmailEntries(ctx, firstFileEntries, secondFileEntries, opts.Comparison)
+	if err != nil {
+		runErr = fmt.Errorf("failed to compare emails: %w", err)
+		return nil, runErr
+	}
 
-	// Compare emails using normalized versions for better matching
-	matchingEmails, missingInFirst, missingInSecond, summary := compareEmailEntries(firstFileEntries, secondFileEntries)
+	// Pair the records whose email changed by their other keys
+	keyMatches, missingInFirst, missingInSecond, err := findKeyMatches(ctx, missingInFirst, missingInSecond, opts.MatchKeys, opts.Comparison.orDefault())
+	if err != nil {
+		runErr = fmt.Errorf("failed to match emails by other keys: %w", err)
+		return nil, runErr
+	}
+	summary.KeyMatchCount = len(keyMatches)
+
+	// Pair near-identical emails that did not match exactly
+	summary.ProbableMatchThreshold = probableMatchThreshold(opts.ProbableMatchThreshold)
+	if summary.ProbableMatchThreshold > 0 && !probableMatchingApplies(opts.Comparison) {
+		logger.Info("Skipping probable matching, which does not apply to the %s comparison", opts.Comparison)
+		summary.ProbableMatchThreshold = 0
+	}
+	probableMatches, missingInFirst, missingInSecond, err := findProbableMatches(ctx, missingInFirst, missingInSecond, summary.ProbableMatchThreshold)
+	if err != nil {
+		runErr = fmt.Errorf("failed to find probable matches: %w", err)
+		return nil, runErr
+	}
+	summary.ProbableMatchCount = len(probableMatches)
+	summary.MissingInFirstCount = len(missingInFirst)
+	summary.MissingInSecondCount = len(missingInSecond)
+
+	summary.SkippedRowsFirstFile = firstResult.skippedRows()
+	summary.SkippedRowsSecondFile = secondResult.skippedRows()
+	summary.ParseErrorsFirstFile = len(firstResult.ParseErrors)
+	summary.ParseErrorsSecondFile = len(secondResult.ParseErrors)
 
 	// Generate output file
 	// Add processing time to summary
 	processingTime := time.Since(startTime)
 	summary.ProcessingTimeSeconds = processingTime.Seconds()
 
-	if outputFormat == "excel" {
-		outputFormat = "xlsx"
-	}
-	outputFileName := fmt.Sprintf("validation_result_%s.%s", time.Now().Format("20060102_150405"), outputFormat)
-	outputFilePath := filepath.Join("./temp", outputFileName)
+	outputFilePath, outputFileName, outputFileURL := outputLocation("validation_result", opts.OutputFormat, opts.OutputPath)
 
+	progress.setStage("generating report")
 	logger.Info("Generating output file: %s", outputFilePath)
-	if err := generateEnhancedOutputFile(outputFilePath, firstFileEntries, secondFileEntries, matchingEmails, missingInFirst, missingInSecond, summary); err != nil {
+	report := &ReportData{
+		FirstEntries:    firstFileEntries,
+		SecondEntries:   secondFileEntries,
+		Matching:        matchingEmails,
+		MissingInFirst:  missingInFirst,
+		MissingInSecond: missingInSecond,
+		KeyMatches:      keyMatches,
+		ProbableMatches: probableMatches,
+		Summary:         summary,
+		OdooMapping:     opts.OdooMapping,
+		Inputs:          inputs,
+		ParseErrors:     parseErrors,
+	}
+	if report.Domains, err = domainBreakdown(ctx, report); err != nil {
+		runErr = fmt.Errorf("failed to count emails by domain: %w", err)
+		return nil, runErr
+	}
+	if err := writeReportFile(ctx, outputFilePath, reporter, report); err != nil {
 		logger.Error("Failed to generate output file: %v", err)
-		return nil, fmt.Errorf("failed to generate output file: %w", err)
+		// Do not leave a partially written report behind
+		if removeErr := os.Remove(outputFilePath); removeErr != nil && !os.IsNotExist(removeErr) {
+			logger.Warn("Failed to remove partial output file %s: %v", outputFilePath, removeErr)
+		}
+		runErr = fmt.Errorf("failed to generate output file: %w", err)
+		return nil, runErr
 	}
 
 	// Extract just the email strings for the API response
@@ -174,11 +361,22 @@ func ValidateEmails(firstFilePath, secondFilePath, outputFormat string) (*Valida
 		MatchingEmails:      matchingEmailStrings,
 		MissingInFirstFile:  missingInFirstStrings,
 		MissingInSecondFile: missingInSecondStrings,
-		OutputFileURL:       fmt.Sprintf("/api/v1/download/%s", outputFileName),
+		KeyMatches:          keyMatches,
+		ProbableMatches:     probableMatches,
+		OutputFileURL:       outputFileURL,
 		FileName:            outputFileName,
 		Summary:             summary,
+		Entries:             runEntries(matchingEmails, missingInFirst, missingInSecond, keyMatches, probableMatches),
+		Inputs:              inputs,
+		ParseErrors:         parseErrors,
+		Domains:             report.Domains,
 	}
 
+	if saveCompletedRun(run, inputPaths, summary, outputFilePath, result.Entries, report.Domains) {
+		result.RunID = run.ID
+	}
+	notifyRun(run, outputFileURL)
+
 	totalTime := time.Since(startTime)
 	logger.Info("Email validation completed in %s. Results: %d matching, %d missing in first, %d missing in second",
 		utils.FormatDuration(totalTime),
@@ -189,154 +387,25 @@ func ValidateEmails(firstFilePath, secondFilePath, outputFormat string) (*Valida
 	return result, nil
 }
 
-// extractEmails extracts emails from a CSV or Excel file
-func extractEmails(filePath string) ([]string, error) {
-	logger := utils.GetLogger()
-	defer utils.LogExecutionTime(fmt.Sprintf("extractEmails(%s)", filePath))()
-
-	ext := strings.ToLower(filepath.Ext(filePath))
-	logger.Info("Extracting emails from %s (format: %s)", filePath, ext)
-
-	var emails []string
-	var err error
-
-	switch ext {
-	case ".csv":
-		emails, err = extractEmailsFromCSV(filePath)
-	case ".xlsx", ".xls":
-		emails, err = extractEmailsFromExcel(filePath)
-	default:
-		return nil, fmt.Errorf("unsupported file format: %s", ext)
-	}
-
-	if err != nil {
-		logger.Error("Failed to extract emails from %s: %v", filePath, err)
-		return nil, err
-	}
-
-	logger.Info("Successfully extracted %d emails from %s", len(emails), filePath)
-	return emails, nil
-}
-
-// extractEmailsFromCSV extracts emails from a CSV file
-// This version is optimized for large files with streaming processing
-func extractEmailsFromCSV(filePath string) ([]string, error) {
+// validateEmailList validates a list of emails and returns detailed validation results
+// This version uses batch processing for better performance
+func validateEmailList(ctx context.Context, extracted []ExtractedEmail, source string) ([]EmailEntry, error) {
 	logger := utils.GetLogger()
-	defer utils.LogExecutionTime("extractEmailsFromCSV")()
-	logger.Debug("Starting CSV extraction from %s", filePath)
-	file, err := os.Open(filePath)
-	if err != nil {
-		return nil, err
-	}
-	defer file.Close()
-
-	// Create a buffered reader for better performance
-	reader := csv.NewReader(file)
-
-	// Read header row
-	_, err = reader.Read()
-	if err != nil {
-		return nil, err
-	}
-
-	// Pre-allocate emails slice with a reasonable capacity
-	// This avoids repeated slice growth and memory reallocation
-	emails := make([]string, 0, 1000) // Start with capacity for 1000 emails
-
-	// Process records one at a time to avoid loading the entire file into memory
-	for {
-		record, err := reader.Read()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return nil, err
-		}
-
-		// Extract email from the first column if it's valid
-		if len(record) > 0 && record[0] != "" {
-			// Only perform basic validation here for speed
-			// The detailed validation will happen later
-			if strings.Contains(record[0], "@") {
-				emails = append(emails, record[0])
-			}
-		}
-	}
-
-	logger.Debug("CSV extraction completed, found %d potential emails", len(emails))
-	return emails, nil
-}
+	defer utils.LogExecutionTime(fmt.Sprintf("validateEmailList(%s)", source))()
 
-// extractEmailsFromExcel extracts emails from an Excel file
-// This version is optimized for large files with streaming processing
-func extractEmailsFromExcel(filePath string) ([]string, error) {
-	logger := utils.GetLogger()
-	defer utils.LogExecutionTime("extractEmailsFromExcel")()
-	logger.Debug("Starting Excel extraction from %s", filePath)
-	// Open the Excel file with streaming mode for better performance with large files
-	f, err := excelize.OpenFile(filePath, excelize.Options{
-		RawCellValue: true, // Get raw values for better performance
-	})
-	if err != nil {
-		return nil, err
-	}
-	defer f.Close()
+	logger.Info("Validating %d emails from %s", len(extracted), source)
 
-	// Get the first sheet
-	sheets := f.GetSheetList()
-	if len(sheets) == 0 {
-		return nil, fmt.Errorf("no sheets found in Excel file")
+	emails := make([]string, len(extracted))
+	for i, email := range extracted {
+		emails[i] = email.Email
 	}
 
-	// Pre-allocate emails slice with a reasonable capacity
-	emails := make([]string, 0, 1000) // Start with capacity for 1000 emails
-
-	// Use rows iterator for streaming large files
-	rows, err := f.Rows(sheets[0])
+	// Use batch validation for better performance
+	validationResults, err := utils.ValidateEmailsBatch(ctx, emails)
 	if err != nil {
 		return nil, err
 	}
 
-	// Skip header row
-	if rows.Next() {
-		_, err := rows.Columns()
-		if err != nil {
-			return nil, err
-		}
-	}
-
-	// Process each row
-	for rows.Next() {
-		row, err := rows.Columns()
-		if err != nil {
-			return nil, err
-		}
-
-		// Extract email from the first column if it exists
-		if len(row) > 0 && row[0] != "" {
-			// Only perform basic validation here for speed
-			// The detailed validation will happen later
-			if strings.Contains(row[0], "@") {
-				emails = append(emails, row[0])
-			}
-		}
-	}
-
-	logger.Debug("Excel extraction completed, found %d potential emails", len(emails))
-	return emails, nil
-}
-
-// validateEmailList validates a list of emails and returns detailed validation results
-// This version uses batch processing for better performance
-func validateEmailList(emails []string, source string) []EmailEntry {
-	logger := utils.GetLogger()
-	defer utils.LogExecutionTime(fmt.Sprintf("validateEmailList(%s)", source))()
-
-	logger.Info("Validating %d emails from %s", len(emails), source)
-
-	// Use batch validation for better performance
-	validationResults := utils.ValidateEmailsBatch(emails)
-
 	// Convert validation results to email entries
 	result := make([]EmailEntry, len(emails))
 	for i, validationResult := range validationResults {
@@ -347,91 +416,54 @@ func validateEmailList(emails []string, source string) []EmailEntry {
 
 		result[i] = EmailEntry{
 			Email:           validationResult.Email,
+			DisplayName:     extracted[i].DisplayName,
 			Source:          source,
 			IsValid:         validationResult.IsValid,
 			IsDisposable:    validationResult.IsDisposable,
 			NormalizedEmail: validationResult.NormalizedEmail,
 			Status:          status,
 			Reason:          validationResult.Reason,
+			Location:        extracted[i].Location,
+			Fields:          extracted[i].Fields,
 		}
 	}
 
 	logger.Info("Completed validation of %d emails from %s", len(emails), source)
-	return result
+	return result, nil
 }
 
-// compareEmailEntries compares two lists of email entries and returns matching and missing emails
-// This version is optimized for performance with pre-allocated slices and single-pass processing
-func compareEmailEntries(firstEntries, secondEntries []EmailEntry) (matching, missingInFirst, missingInSecond []EmailEntry, summary ValidationSummary) {
+// compareEmailEntries compares two lists of email entries on the key of a strategy and returns
+// matching and missing emails. It is the two-source case of compareSources. Domain-level
+// strategies report every domain once, with the last email of the domain in the first file.
+func compareEmailEntries(ctx context.Context, firstEntries, secondEntries []EmailEntry, strategy ComparisonStrategy) (matching, missingInFirst, missingInSecond []EmailEntry, summary ValidationSummary, err error) {
 	logger := utils.GetLogger()
 	defer utils.LogExecutionTime("compareEmailEntries")()
-	logger.Info("Comparing %d emails from first file with %d emails from second file", len(firstEntries), len(secondEntries))
-	// Pre-allocate maps with appropriate capacity to avoid rehashing
-	firstMap := make(map[string]EmailEntry, len(firstEntries))
-	secondMap := make(map[string]EmailEntry, len(secondEntries))
-
-	// Pre-allocate result slices with estimated capacities
-	// This avoids repeated slice growth and memory reallocation
-	estimatedMatchCount := min(len(firstEntries), len(secondEntries)) / 2
-	estimatedMissingCount := len(firstEntries) / 4
-
-	matching = make([]EmailEntry, 0, estimatedMatchCount)
-	missingInFirst = make([]EmailEntry, 0, estimatedMissingCount)
-	missingInSecond = make([]EmailEntry, 0, estimatedMissingCount)
-
-	// Initialize summary
-	summary = ValidationSummary{
-		TotalEmailsFirstFile:  len(firstEntries),
-		TotalEmailsSecondFile: len(secondEntries),
-	}
-
-	// Process first file entries
-	for _, entry := range firstEntries {
-		// Count valid emails
-		if entry.IsValid {
-			summary.ValidEmailsFirstFile++
-		}
-
-		// Use normalized email for comparison
-		firstMap[entry.NormalizedEmail] = entry
-	}
+	logger.Info("Comparing %d emails from first file with %d emails from second file (%s)", len(firstEntries), len(secondEntries), strategy.orDefault())
 
-	// Process second file entries and find matches/missing in one pass
-	for _, entry := range secondEntries {
-		// Count valid emails
-		if entry.IsValid {
-			summary.ValidEmailsSecondFile++
-		}
-
-		// Check if this email exists in first file
-		if firstEntry, exists := firstMap[entry.NormalizedEmail]; exists {
-			// It's a match
-			matching = append(matching, firstEntry)
-		} else {
-			// Missing in first file
-			missingInFirst = append(missingInFirst, entry)
-		}
-
-		// Store in second map for finding missing in second file
-		secondMap[entry.NormalizedEmail] = entry
+	membership, err := compareSources(ctx, []string{"First File", "Second File"}, [][]EmailEntry{firstEntries, secondEntries}, strategy)
+	if err != nil {
+		return nil, nil, nil, ValidationSummary{}, err
 	}
-
-	// Find emails missing in second file
-	for normalizedEmail, entry := range firstMap {
-		if _, exists := secondMap[normalizedEmail]; !exists {
-			missingInSecond = append(missingInSecond, entry)
-		}
+	matching, missingInFirst, missingInSecond, summary, err = membership.twoWay(ctx)
+	if err != nil {
+		return nil, nil, nil, summary, err
 	}
 
-	// Update summary counts
-	summary.MatchingCount = len(matching)
-	summary.MissingInFirstCount = len(missingInFirst)
-	summary.MissingInSecondCount = len(missingInSecond)
-
 	logger.Info("Comparison completed: %d matching, %d missing in first, %d missing in second",
 		summary.MatchingCount, summary.MissingInFirstCount, summary.MissingInSecondCount)
+	return matching, missingInFirst, missingInSecond, summary, nil
+}
 
-	return matching, missingInFirst, missingInSecond, summary
+// outputLocation returns the path, file name and download URL of a report. By default the
+// report is a timestamped file in the temp directory; reports written to outputPath are not
+// downloadable through the API.
+func outputLocation(prefix, outputFormat, outputPath string) (path, name, url string) {
+	if outputPath != "" {
+		return outputPath, filepath.Base(outputPath), ""
+	}
+
+	name = fmt.Sprintf("%s_%s%s", prefix, time.Now().Format("20060102_150405"), reportExtension(outputFormat))
+	return filepath.Join(config.Get().TempDir, name), name, fmt.Sprintf("/api/v1/download/%s", name)
 }
 
 // min returns the smaller of two integers
@@ -442,80 +474,141 @@ func min(a, b int) int {
 	return b
 }
 
-// generateEnhancedOutputFile generates an enhanced output file with detailed validation results
-func generateEnhancedOutputFile(outputPath string, firstEntries, secondEntries, matching, missingInFirst, missingInSecond []EmailEntry, summary ValidationSummary) error {
-	ext := strings.ToLower(filepath.Ext(outputPath))
-
-	switch ext {
-	case ".csv":
-		return generateEnhancedCSVOutput(outputPath, firstEntries, secondEntries, matching, missingInFirst, missingInSecond, summary)
-	case ".xlsx", ".xls":
-		return generateEnhancedExcelOutput(outputPath, firstEntries, secondEntries, matching, missingInFirst, missingInSecond, summary)
-	default:
-		return fmt.Errorf("unsupported output format: %s", ext)
-	}
-}
-
-// generateEnhancedCSVOutput generates an enhanced CSV output file with detailed validation results
-func generateEnhancedCSVOutput(outputPath string, firstEntries, secondEntries, matching, missingInFirst, missingInSecond []EmailEntry, summary ValidationSummary) error {
-	file, err := os.Create(outputPath)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	writer := csv.NewWriter(file)
+// writeCSVReport writes the validation results followed by the summary as CSV
+func writeCSVReport(ctx context.Context, w io.Writer, report *ReportData) error {
+	writer := csv.NewWriter(w)
 	defer writer.Flush()
 
 	// Write header
 	if err := writer.Write([]string{
 		"Email",
+		"Display Name",
 		"Normalized Email",
 		"Source",
 		"Status",
 		"Valid",
 		"Reason",
+		"First File Location",
+		"Second File Location",
+		"Matched Email",
+		"Similarity",
+		"Matched Key",
 	}); err != nil {
 		return err
 	}
 
 	// Write matching emails
-	for _, entry := range matching {
+	for i, entry := range report.Matching {
+		if err := checkCancelled(ctx, i); err != nil {
+			return err
+		}
 		if err := writer.Write([]string{
 			entry.Email,
+			entry.DisplayName,
 			entry.NormalizedEmail,
 			"Both",
 			"Matching",
 			fmtBool(entry.IsValid),
 			entry.Reason,
+			entry.Location.String(),
+			formatLocation(entry.MatchedLocation),
+			"",
+			"",
+			"",
 		}); err != nil {
 			return err
 		}
 	}
 
 	// Write emails missing in first file
-	for _, entry := range missingInFirst {
+	for i, entry := range report.MissingInFirst {
+		if err := checkCancelled(ctx, i); err != nil {
+			return err
+		}
 		if err := writer.Write([]string{
 			entry.Email,
+			entry.DisplayName,
 			entry.NormalizedEmail,
 			"Second File Only",
 			"Missing in First File",
 			fmtBool(entry.IsValid),
 			entry.Reason,
+			"",
+			entry.Location.String(),
+			"",
+			"",
+			"",
 		}); err != nil {
 			return err
 		}
 	}
 
 	// Write emails missing in second file
-	for _, entry := range missingInSecond {
+	for i, entry := range report.MissingInSecond {
+		if err := checkCancelled(ctx, i); err != nil {
+			return err
+		}
 		if err := writer.Write([]string{
 			entry.Email,
+			entry.DisplayName,
 			entry.NormalizedEmail,
 			"First File Only",
 			"Missing in Second File",
 			fmtBool(entry.IsValid),
 			entry.Reason,
+			entry.Location.String(),
+			"",
+			"",
+			"",
+			"",
+		}); err != nil {
+			return err
+		}
+	}
+
+	// Write probable matches, with the similar email of the second file
+	for i, match := range report.ProbableMatches {
+		if err := checkCancelled(ctx, i); err != nil {
+			return err
+		}
+		entry := match.entry()
+		if err := writer.Write([]string{
+			entry.Email,
+			entry.DisplayName,
+			entry.NormalizedEmail,
+			"Both",
+			CategoryProbableMatch,
+			fmtBool(entry.IsValid),
+			entry.Reason,
+			entry.Location.String(),
+			formatLocation(entry.MatchedLocation),
+			entry.MatchedEmail,
+			fmtSimilarity(entry.Similarity),
+			"",
+		}); err != nil {
+			return err
+		}
+	}
+
+	// Write the emails matched by another key, with the new email of the second file
+	for i, match := range report.KeyMatches {
+		if err := checkCancelled(ctx, i); err != nil {
+			return err
+		}
+		entry := match.entry()
+		if err := writer.Write([]string{
+			entry.Email,
+			entry.DisplayName,
+			entry.NormalizedEmail,
+			"Both",
+			CategoryKeyMatch,
+			fmtBool(entry.IsValid),
+			entry.Reason,
+			entry.Location.String(),
+			formatLocation(entry.MatchedLocation),
+			entry.MatchedEmail,
+			"",
+			entry.MatchedKey,
 		}); err != nil {
 			return err
 		}
@@ -536,14 +629,22 @@ func generateEnhancedCSVOutput(outputPath string, firstEntries, secondEntries, m
 
 	// Write summary statistics
 	summaryData := [][]string{
-		{"Total Emails in First File", fmt.Sprintf("%d", summary.TotalEmailsFirstFile)},
-		{"Total Emails in Second File", fmt.Sprintf("%d", summary.TotalEmailsSecondFile)},
-		{"Valid Emails in First File", fmt.Sprintf("%d", summary.ValidEmailsFirstFile)},
-		{"Valid Emails in Second File", fmt.Sprintf("%d", summary.ValidEmailsSecondFile)},
-		{"Matching Emails", fmt.Sprintf("%d", summary.MatchingCount)},
-		{"Emails Missing in First File", fmt.Sprintf("%d", summary.MissingInFirstCount)},
-		{"Emails Missing in Second File", fmt.Sprintf("%d", summary.MissingInSecondCount)},
-		{"Disposable Emails", fmt.Sprintf("%d", summary.DisposableEmailsCount)},
+		{"Total Emails in First File", fmt.Sprintf("%d", report.Summary.TotalEmailsFirstFile)},
+		{"Total Emails in Second File", fmt.Sprintf("%d", report.Summary.TotalEmailsSecondFile)},
+		{"Valid Emails in First File", fmt.Sprintf("%d", report.Summary.ValidEmailsFirstFile)},
+		{"Valid Emails in Second File", fmt.Sprintf("%d", report.Summary.ValidEmailsSecondFile)},
+		{"Matching Emails", fmt.Sprintf("%d", report.Summary.MatchingCount)},
+		{"Emails Missing in First File", fmt.Sprintf("%d", report.Summary.MissingInFirstCount)},
+		{"Emails Missing in Second File", fmt.Sprintf("%d", report.Summary.MissingInSecondCount)},
+		{"Comparison Strategy", string(report.Summary.ComparisonStrategy)},
+		{"Matched by Other Key", fmt.Sprintf("%d", report.Summary.KeyMatchCount)},
+		{"Probable Matches", fmt.Sprintf("%d", report.Summary.ProbableMatchCount)},
+		{"Probable Match Threshold", fmtSimilarity(report.Summary.ProbableMatchThreshold)},
+		{"Disposable Emails", fmt.Sprintf("%d", report.Summary.DisposableEmailsCount)},
+		{"Skipped Rows in First File", fmt.Sprintf("%d", report.Summary.SkippedRowsFirstFile)},
+		{"Skipped Rows in Second File", fmt.Sprintf("%d", report.Summary.SkippedRowsSecondFile)},
+		{"Parse Errors in First File", fmt.Sprintf("%d", report.Summary.ParseErrorsFirstFile)},
+		{"Parse Errors in Second File", fmt.Sprintf("%d", report.Summary.ParseErrorsSecondFile)},
 	}
 
 	for _, row := range summaryData {
@@ -552,138 +653,53 @@ func generateEnhancedCSVOutput(outputPath string, firstEntries, secondEntries, m
 		}
 	}
 
-	return nil
-}
-
-// fmtBool formats a boolean value as "Yes" or "No"
-func fmtBool(b bool) string {
-	if b {
-		return "Yes"
-	}
-	return "No"
-}
-
-// generateEnhancedExcelOutput generates an enhanced Excel output file with detailed validation results
-func generateEnhancedExcelOutput(outputPath string, firstEntries, secondEntries, matching, missingInFirst, missingInSecond []EmailEntry, summary ValidationSummary) error {
-	f := excelize.NewFile()
-
-	// Create a new sheet for validation results
-	resultsSheet := "Validation Results"
-	index, err := f.NewSheet(resultsSheet)
-	if err != nil {
-		return err
-	}
-	f.SetActiveSheet(index)
-
-	// Create styles
-	headerStyle, err := f.NewStyle(&excelize.Style{
-		Font: &excelize.Font{Bold: true},
-		Fill: excelize.Fill{Type: "pattern", Color: []string{"#DDEBF7"}, Pattern: 1},
-		Border: []excelize.Border{
-			{Type: "bottom", Color: "#000000", Style: 1},
-		},
-		Alignment: &excelize.Alignment{Horizontal: "center"},
-	})
-	if err != nil {
-		return err
-	}
-
-	// Write header
-	headers := []string{
-		"Email",
-		"Normalized Email",
-		"Source",
-		"Status",
-		"Valid",
-		"Reason",
-	}
-
-	for i, header := range headers {
-		cell := fmt.Sprintf("%s1", string('A'+i))
-		f.SetCellValue(resultsSheet, cell, header)
-	}
-
-	// Apply header style
-	f.SetCellStyle(resultsSheet, "A1", string('A'+len(headers)-1)+"1", headerStyle)
-
-	// Write matching emails
-	row := 2
-	for _, entry := range matching {
-		f.SetCellValue(resultsSheet, fmt.Sprintf("A%d", row), entry.Email)
-		f.SetCellValue(resultsSheet, fmt.Sprintf("B%d", row), entry.NormalizedEmail)
-		f.SetCellValue(resultsSheet, fmt.Sprintf("C%d", row), "Both")
-		f.SetCellValue(resultsSheet, fmt.Sprintf("D%d", row), "Matching")
-		f.SetCellValue(resultsSheet, fmt.Sprintf("E%d", row), fmtBool(entry.IsValid))
-		f.SetCellValue(resultsSheet, fmt.Sprintf("I%d", row), entry.Reason)
-		row++
-	}
-
-	// Write emails missing in first file
-	for _, entry := range missingInFirst {
-		f.SetCellValue(resultsSheet, fmt.Sprintf("A%d", row), entry.Email)
-		f.SetCellValue(resultsSheet, fmt.Sprintf("B%d", row), entry.NormalizedEmail)
-		f.SetCellValue(resultsSheet, fmt.Sprintf("C%d", row), "Second File Only")
-		f.SetCellValue(resultsSheet, fmt.Sprintf("D%d", row), "Missing in First File")
-		f.SetCellValue(resultsSheet, fmt.Sprintf("E%d", row), fmtBool(entry.IsValid))
-		f.SetCellValue(resultsSheet, fmt.Sprintf("I%d", row), entry.Reason)
-		row++
-	}
-
-	// Write emails missing in second file
-	for _, entry := range missingInSecond {
-		f.SetCellValue(resultsSheet, fmt.Sprintf("A%d", row), entry.Email)
-		f.SetCellValue(resultsSheet, fmt.Sprintf("B%d", row), entry.NormalizedEmail)
-		f.SetCellValue(resultsSheet, fmt.Sprintf("C%d", row), "First File Only")
-		f.SetCellValue(resultsSheet, fmt.Sprintf("D%d", row), "Missing in Second File")
-		f.SetCellValue(resultsSheet, fmt.Sprintf("E%d", row), fmtBool(entry.IsValid))
-		f.SetCellValue(resultsSheet, fmt.Sprintf("I%d", row), entry.Reason)
-		row++
-	}
-
-	// Create a summary sheet
-	summarySheet := "Summary"
-	_, err = f.NewSheet(summarySheet)
-	if err != nil {
-		return err
+	// Write the domain breakdown
+	if len(report.Domains) > 0 {
+		rows := [][]string{{""}, {"Domains"}, domainStatsColumns}
+		for _, domain := range report.Domains {
+			row := make([]string, 0, len(domainStatsColumns))
+			for _, value := range domain.row() {
+				row = append(row, fmt.Sprint(value))
+			}
+			rows = append(rows, row)
+		}
+		if err := writer.WriteAll(rows); err != nil {
+			return err
+		}
 	}
 
-	// Write summary headers
-	f.SetCellValue(summarySheet, "A1", "Metric")
-	f.SetCellValue(summarySheet, "B1", "Value")
-	f.SetCellStyle(summarySheet, "A1", "B1", headerStyle)
-
-	// Write summary data
-	summaryData := [][]interface{}{
-		{"Total Emails in First File", summary.TotalEmailsFirstFile},
-		{"Total Emails in Second File", summary.TotalEmailsSecondFile},
-		{"Valid Emails in First File", summary.ValidEmailsFirstFile},
-		{"Valid Emails in Second File", summary.ValidEmailsSecondFile},
-		{"Matching Emails", summary.MatchingCount},
-		{"Emails Missing in First File", summary.MissingInFirstCount},
-		{"Emails Missing in Second File", summary.MissingInSecondCount},
-		{"Disposable Emails", summary.DisposableEmailsCount},
+	// Write the malformed rows skipped by lenient parsing
+	if len(report.ParseErrors) > 0 {
+		rows := [][]string{{""}, {"Parse Errors"}, {"Input", "File", "Line", "Error"}}
+		for _, parseError := range report.ParseErrors {
+			rows = append(rows, []string{parseError.Role, parseError.File, fmt.Sprintf("%d", parseError.Line), parseError.Message})
+		}
+		if err := writer.WriteAll(rows); err != nil {
+			return err
+		}
 	}
 
-	for i, row := range summaryData {
-		f.SetCellValue(summarySheet, fmt.Sprintf("A%d", i+2), row[0])
-		f.SetCellValue(summarySheet, fmt.Sprintf("B%d", i+2), row[1])
-	}
+	writer.Flush()
+	return writer.Error()
+}
 
-	// Auto-fit columns in both sheets
-	for _, col := range []string{"A", "B", "C", "D", "E", "F", "G", "H", "I"} {
-		f.SetColWidth(resultsSheet, col, col, 20)
+// checkCancelled returns the context error every cancelCheckInterval iterations of a loop
+func checkCancelled(ctx context.Context, iteration int) error {
+	if iteration%cancelCheckInterval != 0 {
+		return nil
 	}
+	return ctx.Err()
+}
 
-	f.SetColWidth(summarySheet, "A", "A", 30)
-	f.SetColWidth(summarySheet, "B", "B", 15)
-
-	// Delete default sheet
-	f.DeleteSheet("Sheet1")
+// fmtSimilarity formats a similarity or threshold with two decimals, e.g. "0.91"
+func fmtSimilarity(similarity float64) string {
+	return strconv.FormatFloat(similarity, 'f', 2, 64)
+}
 
-	// Save the file
-	if err := f.SaveAs(outputPath); err != nil {
-		return err
+// fmtBool formats a boolean value as "Yes" or "No"
+func fmtBool(b bool) string {
+	if b {
+		return "Yes"
 	}
-
-	return nil
+	return "No"
 }
@@ -0,0 +1,268 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Error codes of the API error responses. They are stable, so clients can rely on them rather
+// than on the messages.
+const (
+	CodeInvalidRequest    = "invalid_request"
+	CodeUnauthorized      = "unauthorized"
+	CodeNotFound          = "not_found"
+	CodeConflict          = "conflict"
+	CodeUnsupportedFormat = "unsupported_format"
+	CodeEmptyFile         = "empty_file"
+	CodeNoEmailColumn     = "no_email_column"
+	CodeNoSheet           = "no_sheet"
+	CodeParseError        = "parse_error"
+	CodeTooLarge          = "too_large"
+	CodeTimeout           = "timeout"
+	CodeCancelled         = "cancelled"
+	CodeHistoryDisabled   = "history_disabled"
+	CodeInternal          = "internal"
+)
+
+// CodedError is an error caused by the input of a request, with a stable code and the details
+// that help fixing the input
+type CodedError interface {
+	error
+	Code() string
+	Details() map[string]interface{}
+}
+
+// UnsupportedFormatError is returned for an input file whose format is not supported
+type UnsupportedFormatError struct {
+	File   string
+	Reason string
+}
+
+func (e *UnsupportedFormatError) Error() string {
+	if e.Reason != "" {
+		return fmt.Sprintf("unsupported file format of %s: %s", e.File, e.Reason)
+	}
+	return fmt.Sprintf("unsupported file format of %s", e.File)
+}
+
+// Is matches ErrUnsupportedFormat, which older callers check for
+func (e *UnsupportedFormatError) Is(target error) bool { return target == ErrUnsupportedFormat }
+
+// Code returns CodeUnsupportedFormat
+func (e *UnsupportedFormatError) Code() string { return CodeUnsupportedFormat }
+
+// Details returns the file and the supported extensions
+func (e *UnsupportedFormatError) Details() map[string]interface{} {
+	return map[string]interface{}{"file": e.File, "supportedFormats": InputExtensions()}
+}
+
+// EmptyFileError is returned for an input file without data, such as an empty upload or an
+// Excel workbook without sheets
+type EmptyFileError struct {
+	File   string
+	Reason string
+}
+
+func (e *EmptyFileError) Error() string {
+	return fmt.Sprintf("%s is empty: %s", e.File, e.Reason)
+}
+
+// Code returns CodeEmptyFile
+func (e *EmptyFileError) Code() string { return CodeEmptyFile }
+
+// Details returns the file
+func (e *EmptyFileError) Details() map[string]interface{} {
+	return map[string]interface{}{"file": e.File}
+}
+
+// NoEmailColumnError is returned when the email column selected for a file is not in it
+type NoEmailColumnError struct {
+	File   string
+	Column string
+	// Header lists the column names of the file, when it has a header
+	Header []string
+}
+
+func (e *NoEmailColumnError) Error() string {
+	if len(e.Header) > 0 {
+		return fmt.Sprintf("column %q not found in header of %s (columns: %s)", e.Column, e.File, strings.Join(e.Header, ", "))
+	}
+	return fmt.Sprintf("column %q not found in %s", e.Column, e.File)
+}
+
+// Code returns CodeNoEmailColumn
+func (e *NoEmailColumnError) Code() string { return CodeNoEmailColumn }
+
+// Details returns the file, the column and the header
+func (e *NoEmailColumnError) Details() map[string]interface{} {
+	details := map[string]interface{}{"file": e.File, "column": e.Column}
+	if len(e.Header) > 0 {
+		details["header"] = e.Header
+	}
+	return details
+}
+
+// NoSheetError is returned when the sheet selected for an Excel file is not in it
+type NoSheetError struct {
+	File  string
+	Sheet string
+	// Sheets lists the sheet names of the file
+	Sheets []string
+}
+
+func (e *NoSheetError) Error() string {
+	return fmt.Sprintf("sheet %q not found in %s (sheets: %s)", e.Sheet, e.File, strings.Join(e.Sheets, ", "))
+}
+
+// Code returns CodeNoSheet
+func (e *NoSheetError) Code() string { return CodeNoSheet }
+
+// Details returns the file, the sheet and the sheets of the file
+func (e *NoSheetError) Details() map[string]interface{} {
+	return map[string]interface{}{"file": e.File, "sheet": e.Sheet, "sheets": e.Sheets}
+}
+
+// InvalidOptionError is returned for an extraction option that is malformed whatever the file,
+// such as a column index of 0 or a JSON path with a missing ]
+type InvalidOptionError struct {
+	File   string
+	Option string
+	Value  string
+	Reason string
+}
+
+func (e *InvalidOptionError) Error() string {
+	if e.File != "" {
+		return fmt.Sprintf("invalid %s %q for %s: %s", e.Option, e.Value, e.File, e.Reason)
+	}
+	return fmt.Sprintf("invalid %s %q: %s", e.Option, e.Value, e.Reason)
+}
+
+// Code returns CodeInvalidRequest
+func (e *InvalidOptionError) Code() string { return CodeInvalidRequest }
+
+// Details returns the option, its value and the file, when known
+func (e *InvalidOptionError) Details() map[string]interface{} {
+	details := map[string]interface{}{"option": e.Option, "value": e.Value}
+	if e.File != "" {
+		details["file"] = e.File
+	}
+	return details
+}
+
+// FileParseError is returned for an input file that cannot be parsed, with the location of the
+// problem when it is known
+type FileParseError struct {
+	File string
+	// Line and Column are 1-based, or 0 when unknown
+	Line   int
+	Column int
+	Err    error
+}
+
+func (e *FileParseError) Error() string {
+	switch {
+	case e.Line > 0 && e.Column > 0:
+		return fmt.Sprintf("cannot parse %s on line %d, column %d: %v", e.File, e.Line, e.Column, e.Err)
+	case e.Line > 0:
+		return fmt.Sprintf("cannot parse %s on line %d: %v", e.File, e.Line, e.Err)
+	default:
+		return fmt.Sprintf("cannot parse %s: %v", e.File, e.Err)
+	}
+}
+
+func (e *FileParseError) Unwrap() error { return e.Err }
+
+// Code returns CodeParseError
+func (e *FileParseError) Code() string { return CodeParseError }
+
+// Details returns the file and the location of the problem
+func (e *FileParseError) Details() map[string]interface{} {
+	details := map[string]interface{}{"file": e.File}
+	if e.Line > 0 {
+		details["line"] = e.Line
+	}
+	if e.Column > 0 {
+		details["column"] = e.Column
+	}
+	return details
+}
+
+// TooLargeError is returned when an input exceeds a size limit
+type TooLargeError struct {
+	// Subject is what is too large, e.g. "email list" or "ZIP entry contacts.csv"
+	Subject string
+	// Size and Limit are counted in Unit, e.g. "emails" or "bytes"; Size is 0 when unknown
+	Size  int64
+	Limit int64
+	Unit  string
+}
+
+func (e *TooLargeError) Error() string {
+	if e.Size > 0 {
+		return fmt.Sprintf("%s is too large: %d %s given, at most %d allowed", e.Subject, e.Size, e.Unit, e.Limit)
+	}
+	return fmt.Sprintf("%s is too large: more than %d %s", e.Subject, e.Limit, e.Unit)
+}
+
+// Code returns CodeTooLarge
+func (e *TooLargeError) Code() string { return CodeTooLarge }
+
+// Details returns the limit and the size
+func (e *TooLargeError) Details() map[string]interface{} {
+	details := map[string]interface{}{"limit": e.Limit, "unit": e.Unit}
+	if e.Size > 0 {
+		details["size"] = e.Size
+	}
+	return details
+}
+
+// AsCodedError returns the CodedError in the chain of err, if any
+func AsCodedError(err error) (CodedError, bool) {
+	var coded CodedError
+	if errors.As(err, &coded) {
+		return coded, true
+	}
+	return nil, false
+}
+
+// withFileName sets the file named by the typed errors in the chain of err, for errors raised
+// by code that does not know the name, and returns err
+func withFileName(err error, fileName string) error {
+	var (
+		formatErr   *UnsupportedFormatError
+		emptyErr    *EmptyFileError
+		noColumnErr *NoEmailColumnError
+		noSheetErr  *NoSheetError
+		optionErr   *InvalidOptionError
+		parseErr    *FileParseError
+	)
+	switch {
+	case errors.As(err, &formatErr):
+		formatErr.File = fileName
+	case errors.As(err, &emptyErr):
+		emptyErr.File = fileName
+	case errors.As(err, &noColumnErr):
+		noColumnErr.File = fileName
+	case errors.As(err, &noSheetErr):
+		noSheetErr.File = fileName
+	case errors.As(err, &optionErr):
+		optionErr.File = fileName
+	case errors.As(err, &parseErr):
+		parseErr.File = fileName
+	}
+	return err
+}
+
+// lineColumn returns the 1-based line and column of a byte offset in data
+func lineColumn(data []byte, offset int64) (int, int) {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	before := data[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := len(before) - bytes.LastIndexByte(before, '\n')
+	return line, column
+}
@@ -0,0 +1,476 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"unicode/utf8"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Sheets of the Excel validation report
+const (
+	excelSummarySheet         = "Summary"
+	excelMatchingSheet        = "Matching"
+	excelMissingInFirstSheet  = "Missing in First"
+	excelMissingInSecondSheet = "Missing in Second"
+	excelProbableMatchSheet   = "Probable Match"
+	excelKeyMatchSheet        = "Matched by Other Key"
+	excelInvalidSheet         = "Invalid"
+	excelDuplicatesSheet      = "Duplicates"
+	excelDomainsSheet         = "Domains"
+	excelParseErrorsSheet     = "Parse Errors"
+)
+
+// excelCategorySheets are the sheets listing the entries of each category, in workbook order.
+// Delta reports read the same sheets back from uploaded Excel reports.
+var excelCategorySheets = []struct {
+	name     string
+	category string
+}{
+	{excelMatchingSheet, CategoryMatching},
+	{excelMissingInFirstSheet, CategoryMissingInFirst},
+	{excelMissingInSecondSheet, CategoryMissingInSecond},
+	{excelKeyMatchSheet, CategoryKeyMatch},
+	{excelProbableMatchSheet, CategoryProbableMatch},
+}
+
+// Bounds of the computed column widths, in characters
+const (
+	minExcelColumnWidth = 10
+	maxExcelColumnWidth = 60
+)
+
+// excelEntryHeaders are the columns of every entry sheet of the Excel report. The
+// conditional formatting of invalid rows relies on Valid being column F.
+var excelEntryHeaders = []string{
+	"Email",
+	"Display Name",
+	"Normalized Email",
+	"Source",
+	"Status",
+	"Valid",
+	"Disposable",
+	"Reason",
+	"First File Location",
+	"Second File Location",
+	"Matched Email",
+	"Similarity",
+	"Matched Key",
+}
+
+// excelReportStyles holds the styles shared by the sheets of the Excel report
+type excelReportStyles struct {
+	header  int
+	invalid int
+}
+
+// writeExcelReport writes an Excel workbook with a summary sheet with charts and one sheet
+// per category: matching, missing in each file, matched by another key, probable matches,
+// invalid and duplicate emails, followed by the domain breakdown and the rows skipped by lenient
+// parsing, if any
+func writeExcelReport(ctx context.Context, w io.Writer, report *ReportData) error {
+	firstEntries, secondEntries := report.FirstEntries, report.SecondEntries
+	matching, missingInFirst, missingInSecond := report.Matching, report.MissingInFirst, report.MissingInSecond
+
+	f := excelize.NewFile()
+	defer f.Close()
+
+	styles, err := newExcelReportStyles(f)
+	if err != nil {
+		return err
+	}
+
+	// The default sheet becomes the summary, which keeps it first in the workbook
+	if err := f.SetSheetName("Sheet1", excelSummarySheet); err != nil {
+		return err
+	}
+
+	// Category of every normalized email, shown as the status on the invalid and duplicate sheets
+	probable := probableEntries(report.ProbableMatches)
+	changed := keyMatchEntries(report.KeyMatches)
+	categories := make(map[string]string, len(matching)+len(missingInFirst)+len(missingInSecond)+2*len(changed)+2*len(probable))
+	for _, match := range report.KeyMatches {
+		categories[match.First.NormalizedEmail] = CategoryKeyMatch
+		categories[match.Second.NormalizedEmail] = CategoryKeyMatch
+	}
+	for _, match := range report.ProbableMatches {
+		categories[match.First.NormalizedEmail] = CategoryProbableMatch
+		categories[match.Second.NormalizedEmail] = CategoryProbableMatch
+	}
+	for _, entry := range missingInSecond {
+		categories[entry.NormalizedEmail] = CategoryMissingInSecond
+	}
+	for _, entry := range missingInFirst {
+		categories[entry.NormalizedEmail] = CategoryMissingInFirst
+	}
+	for _, entry := range matching {
+		categories[entry.NormalizedEmail] = CategoryMatching
+	}
+
+	categoryEntries := map[string][]EmailEntry{
+		CategoryMatching:        matching,
+		CategoryMissingInFirst:  missingInFirst,
+		CategoryMissingInSecond: missingInSecond,
+		CategoryKeyMatch:        changed,
+		CategoryProbableMatch:   probable,
+	}
+	for _, sheet := range excelCategorySheets {
+		entries := categoryEntries[sheet.category]
+		rows := make([][]interface{}, len(entries))
+		for i, entry := range entries {
+			if err := checkCancelled(ctx, i); err != nil {
+				return err
+			}
+			rows[i] = excelEntryRow(entry, sheet.category)
+		}
+		if err := writeExcelEntrySheet(ctx, f, sheet.name, excelEntryHeaders, rows, styles); err != nil {
+			return err
+		}
+	}
+
+	// Invalid emails of both files
+	var invalidRows [][]interface{}
+	for _, entries := range [][]EmailEntry{firstEntries, secondEntries} {
+		for i, entry := range entries {
+			if err := checkCancelled(ctx, i); err != nil {
+				return err
+			}
+			if !entry.IsValid {
+				invalidRows = append(invalidRows, excelEntryRow(entry, categories[entry.NormalizedEmail]))
+			}
+		}
+	}
+	if err := writeExcelEntrySheet(ctx, f, excelInvalidSheet, excelEntryHeaders, invalidRows, styles); err != nil {
+		return err
+	}
+
+	// Every occurrence of an email that appears more than once in the same file
+	duplicateHeaders := append(append([]string{}, excelEntryHeaders...), "Occurrences")
+	var duplicateRows [][]interface{}
+	for _, entries := range [][]EmailEntry{firstEntries, secondEntries} {
+		occurrences, err := countOccurrences(ctx, entries)
+		if err != nil {
+			return err
+		}
+		for i, entry := range entries {
+			if err := checkCancelled(ctx, i); err != nil {
+				return err
+			}
+			if count := occurrences[entry.NormalizedEmail]; count > 1 {
+				duplicateRows = append(duplicateRows, append(excelEntryRow(entry, categories[entry.NormalizedEmail]), count))
+			}
+		}
+	}
+	if err := writeExcelEntrySheet(ctx, f, excelDuplicatesSheet, duplicateHeaders, duplicateRows, styles); err != nil {
+		return err
+	}
+
+	// Counts by registrable domain
+	domainRows := make([][]interface{}, len(report.Domains))
+	for i, domain := range report.Domains {
+		domainRows[i] = domain.row()
+	}
+	if err := writeExcelEntrySheet(ctx, f, excelDomainsSheet, domainStatsColumns, domainRows, styles); err != nil {
+		return err
+	}
+
+	// Malformed rows skipped by lenient parsing
+	if len(report.ParseErrors) > 0 {
+		rows := make([][]interface{}, len(report.ParseErrors))
+		for i, parseError := range report.ParseErrors {
+			rows[i] = []interface{}{parseError.Role, parseError.File, parseError.Line, parseError.Message}
+		}
+		if err := writeExcelEntrySheet(ctx, f, excelParseErrorsSheet, []string{"Input", "File", "Line", "Error"}, rows, styles); err != nil {
+			return err
+		}
+	}
+
+	counts := excelFileCounts{first: fileCounts(firstEntries), second: fileCounts(secondEntries)}
+	if err := writeExcelSummarySheet(f, report.Summary, counts, styles); err != nil {
+		return err
+	}
+
+	// Open the workbook on the summary
+	f.SetActiveSheet(0)
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return f.Write(w)
+}
+
+// newExcelReportStyles creates the header style and the conditional style of invalid rows
+func newExcelReportStyles(f *excelize.File) (excelReportStyles, error) {
+	var styles excelReportStyles
+	var err error
+
+	styles.header, err = f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true},
+		Fill: excelize.Fill{Type: "pattern", Color: []string{"#DDEBF7"}, Pattern: 1},
+		Border: []excelize.Border{
+			{Type: "bottom", Color: "#000000", Style: 1},
+		},
+		Alignment: &excelize.Alignment{Horizontal: "center"},
+	})
+	if err != nil {
+		return styles, err
+	}
+
+	styles.invalid, err = f.NewConditionalStyle(&excelize.Style{
+		Font: &excelize.Font{Color: "#9C0006"},
+		Fill: excelize.Fill{Type: "pattern", Color: []string{"#FFC7CE"}, Pattern: 1},
+	})
+	return styles, err
+}
+
+// excelEntryRow returns the cells of an entry on an entry sheet
+func excelEntryRow(entry EmailEntry, status string) []interface{} {
+	first, second := entryLocations(entry)
+	var similarity interface{}
+	if entry.Similarity > 0 {
+		similarity = entry.Similarity
+	}
+	return []interface{}{
+		entry.Email,
+		entry.DisplayName,
+		entry.NormalizedEmail,
+		entry.Source,
+		status,
+		fmtBool(entry.IsValid),
+		fmtBool(entry.IsDisposable),
+		entry.Reason,
+		first,
+		second,
+		entry.MatchedEmail,
+		similarity,
+		entry.MatchedKey,
+	}
+}
+
+// entryLocations returns the formatted locations of an entry in the first and second file
+func entryLocations(entry EmailEntry) (first, second string) {
+	if entry.MatchedLocation != nil {
+		return entry.Location.String(), entry.MatchedLocation.String()
+	}
+	if entry.Source == "Second File" {
+		return "", entry.Location.String()
+	}
+	return entry.Location.String(), ""
+}
+
+// countOccurrences returns the number of times each normalized email appears in entries
+func countOccurrences(ctx context.Context, entries []EmailEntry) (map[string]int, error) {
+	counts := make(map[string]int, len(entries))
+	for i, entry := range entries {
+		if err := checkCancelled(ctx, i); err != nil {
+			return nil, err
+		}
+		counts[entry.NormalizedEmail]++
+	}
+	return counts, nil
+}
+
+// writeExcelEntrySheet writes a sheet of entries with a styled, filterable and frozen header row,
+// highlighted invalid rows and column widths fitted to the content
+func writeExcelEntrySheet(ctx context.Context, f *excelize.File, sheet string, headers []string, rows [][]interface{}, styles excelReportStyles) error {
+	if _, err := f.NewSheet(sheet); err != nil {
+		return err
+	}
+
+	headerRow := make([]interface{}, len(headers))
+	widths := make([]int, len(headers))
+	for i, header := range headers {
+		headerRow[i] = header
+		widths[i] = utf8.RuneCountInString(header)
+	}
+	if err := f.SetSheetRow(sheet, "A1", &headerRow); err != nil {
+		return err
+	}
+
+	for i, row := range rows {
+		if err := checkCancelled(ctx, i); err != nil {
+			return err
+		}
+		if err := f.SetSheetRow(sheet, fmt.Sprintf("A%d", i+2), &row); err != nil {
+			return err
+		}
+		for j, value := range row {
+			if width := utf8.RuneCountInString(fmt.Sprint(value)); width > widths[j] {
+				widths[j] = width
+			}
+		}
+	}
+
+	lastColumn, err := excelize.ColumnNumberToName(len(headers))
+	if err != nil {
+		return err
+	}
+	if err := f.SetCellStyle(sheet, "A1", lastColumn+"1", styles.header); err != nil {
+		return err
+	}
+
+	for i, width := range widths {
+		column, err := excelize.ColumnNumberToName(i + 1)
+		if err != nil {
+			return err
+		}
+		width += 2
+		if width < minExcelColumnWidth {
+			width = minExcelColumnWidth
+		}
+		if width > maxExcelColumnWidth {
+			width = maxExcelColumnWidth
+		}
+		if err := f.SetColWidth(sheet, column, column, float64(width)); err != nil {
+			return err
+		}
+	}
+
+	lastRow := len(rows) + 1
+	if err := f.AutoFilter(sheet, fmt.Sprintf("A1:%s%d", lastColumn, lastRow), nil); err != nil {
+		return err
+	}
+	if err := f.SetPanes(sheet, &excelize.Panes{
+		Freeze:      true,
+		YSplit:      1,
+		TopLeftCell: "A2",
+		ActivePane:  "bottomLeft",
+	}); err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	return f.SetConditionalFormat(sheet, fmt.Sprintf("A2:%s%d", lastColumn, lastRow), []excelize.ConditionalFormatOptions{
+		{Type: "formula", Criteria: `$F2="No"`, Format: styles.invalid},
+	})
+}
+
+// excelFileStats contains the per-file counts charted on the summary sheet
+type excelFileStats struct {
+	valid, invalid, disposable, duplicates int
+}
+
+// excelFileCounts holds the chart counts of both files
+type excelFileCounts struct {
+	first, second excelFileStats
+}
+
+// fileCounts counts the valid, invalid, disposable and duplicate entries of a file. Only the
+// repeated occurrences of an email count as duplicates.
+func fileCounts(entries []EmailEntry) excelFileStats {
+	var stats excelFileStats
+	seen := make(map[string]struct{}, len(entries))
+	for _, entry := range entries {
+		if entry.IsValid {
+			stats.valid++
+		} else {
+			stats.invalid++
+		}
+		if entry.IsDisposable {
+			stats.disposable++
+		}
+		if _, exists := seen[entry.NormalizedEmail]; exists {
+			stats.duplicates++
+		} else {
+			seen[entry.NormalizedEmail] = struct{}{}
+		}
+	}
+	return stats
+}
+
+// writeExcelSummarySheet fills the summary sheet with the summary metrics, the chart data, a
+// pie chart of the categories and a bar chart of the email quality of each file
+func writeExcelSummarySheet(f *excelize.File, summary ValidationSummary, counts excelFileCounts, styles excelReportStyles) error {
+	sheet := excelSummarySheet
+
+	rows := [][]interface{}{
+		{"Metric", "Value"},
+		{"Total Emails in First File", summary.TotalEmailsFirstFile},
+		{"Total Emails in Second File", summary.TotalEmailsSecondFile},
+		{"Valid Emails in First File", summary.ValidEmailsFirstFile},
+		{"Valid Emails in Second File", summary.ValidEmailsSecondFile},
+		{"Matching Emails", summary.MatchingCount},
+		{"Emails Missing in First File", summary.MissingInFirstCount},
+		{"Emails Missing in Second File", summary.MissingInSecondCount},
+		{"Disposable Emails", summary.DisposableEmailsCount},
+		{"Processing Time (s)", math.Round(summary.ProcessingTimeSeconds*100) / 100},
+		{},
+		// Rows 12-17: data of the category pie chart
+		{"Category", "Emails"},
+		{CategoryMatching, summary.MatchingCount},
+		{CategoryMissingInFirst, summary.MissingInFirstCount},
+		{CategoryMissingInSecond, summary.MissingInSecondCount},
+		{CategoryKeyMatch, summary.KeyMatchCount},
+		{CategoryProbableMatch, summary.ProbableMatchCount},
+		{},
+		// Rows 19-21: data of the email quality bar chart
+		{"File", "Valid", "Invalid", "Disposable", "Duplicates"},
+		{"First File", counts.first.valid, counts.first.invalid, counts.first.disposable, counts.first.duplicates},
+		{"Second File", counts.second.valid, counts.second.invalid, counts.second.disposable, counts.second.duplicates},
+		{},
+		// Rows 23-24: leading rows of each file that were not read as data
+		{"Skipped Rows in First File", summary.SkippedRowsFirstFile},
+		{"Skipped Rows in Second File", summary.SkippedRowsSecondFile},
+		// Rows 25-26: malformed rows of each file skipped by lenient parsing
+		{"Parse Errors in First File", summary.ParseErrorsFirstFile},
+		{"Parse Errors in Second File", summary.ParseErrorsSecondFile},
+		// Row 27: similarity from which emails were paired as probable matches
+		{"Probable Match Threshold", summary.ProbableMatchThreshold},
+		// Row 28: key the emails were compared on
+		{"Comparison Strategy", string(summary.ComparisonStrategy)},
+	}
+	for i, row := range rows {
+		if len(row) == 0 {
+			continue
+		}
+		if err := f.SetSheetRow(sheet, fmt.Sprintf("A%d", i+1), &row); err != nil {
+			return err
+		}
+	}
+	for _, header := range []struct{ from, to string }{{"A1", "B1"}, {"A12", "B12"}, {"A19", "E19"}} {
+		if err := f.SetCellStyle(sheet, header.from, header.to, styles.header); err != nil {
+			return err
+		}
+	}
+	if err := f.SetColWidth(sheet, "A", "A", 38); err != nil {
+		return err
+	}
+	if err := f.SetColWidth(sheet, "B", "E", 12); err != nil {
+		return err
+	}
+
+	if err := f.AddChart(sheet, "G1", &excelize.Chart{
+		Type:   excelize.Pie,
+		Title:  []excelize.RichTextRun{{Text: "Emails by Category"}},
+		Legend: excelize.ChartLegend{Position: "right"},
+		Series: []excelize.ChartSeries{{
+			Name:       "Summary!$B$12",
+			Categories: "Summary!$A$13:$A$17",
+			Values:     "Summary!$B$13:$B$17",
+		}},
+		PlotArea:  excelize.ChartPlotArea{ShowPercent: true},
+		Dimension: excelize.ChartDimension{Width: 480, Height: 300},
+	}); err != nil {
+		return err
+	}
+
+	var series []excelize.ChartSeries
+	for _, column := range []string{"B", "C", "D", "E"} {
+		series = append(series, excelize.ChartSeries{
+			Name:       fmt.Sprintf("Summary!$%s$19", column),
+			Categories: "Summary!$A$20:$A$21",
+			Values:     fmt.Sprintf("Summary!$%s$20:$%s$21", column, column),
+		})
+	}
+	return f.AddChart(sheet, "G19", &excelize.Chart{
+		Type:      excelize.Bar,
+		Title:     []excelize.RichTextRun{{Text: "Email Quality by File"}},
+		Legend:    excelize.ChartLegend{Position: "bottom"},
+		Series:    series,
+		Dimension: excelize.ChartDimension{Width: 480, Height: 300},
+	})
+}
@@ -0,0 +1,810 @@
+package services
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+	"ness-to-odoo-golang-validation-api-tool/config"
+	"ness-to-odoo-golang-validation-api-tool/utils"
+)
+
+// ExtractOptions controls where emails are read from in an input file
+type ExtractOptions struct {
+	// Column selects the email column by header name, letter ("B") or 1-based index.
+	// Defaults to the first column.
+	Column string `json:"column,omitempty"`
+	// Sheet selects the Excel worksheet by name or 1-based index. Defaults to the first sheet.
+	// It is ignored for other formats.
+	Sheet string `json:"sheet,omitempty"`
+	// JSONPath selects the emails of JSON and NDJSON files, e.g. "$.contacts[*].email".
+	// Defaults to the Column key of every element, or to every string that contains "@".
+	JSONPath string `json:"jsonPath,omitempty"`
+	// Delimiter, Quote and Encoding override the detected dialect of CSV and TSV files; Encoding
+	// also applies to text files. See ParseDelimiter, QuoteDouble and QuoteNone, and
+	// lookupEncoding for the accepted values.
+	Delimiter string `json:"delimiter,omitempty"`
+	Quote     string `json:"quote,omitempty"`
+	Encoding  string `json:"encoding,omitempty"`
+	// HasHeader tells whether the first row after the skipped rows of a CSV, TSV or Excel file
+	// is a header. When nil the row is a header unless it looks like data, i.e. has a cell with "@".
+	HasHeader *bool `json:"hasHeader,omitempty"`
+	// SkipRows is the number of leading rows, such as title banners, to ignore before the header
+	// or the first data row of CSV, TSV and Excel files. Blank lines of CSV files do not count.
+	SkipRows int `json:"skipRows,omitempty"`
+	// Lenient reads CSV and TSV files with variable field counts and lazy quotes. Rows that
+	// are still malformed are recorded as parse errors and skipped instead of failing the read.
+	Lenient bool `json:"lenient,omitempty"`
+	// MaxParseErrors is the number of malformed rows a lenient read skips before it fails.
+	// Defaults to the MAX_PARSE_ERRORS configuration.
+	MaxParseErrors int `json:"maxParseErrors,omitempty"`
+	// Fields are header names of further columns whose values are kept with each email
+	Fields []string `json:"-"`
+}
+
+// ParseHasHeader parses a hasHeader option: true or false, or an empty string or "auto" to
+// detect the header
+func ParseHasHeader(value string) (*bool, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || strings.EqualFold(value, "auto") {
+		return nil, nil
+	}
+	hasHeader, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid hasHeader %q: use true, false or auto", value)
+	}
+	return &hasHeader, nil
+}
+
+// ValidateTableOptions checks the header and parse error options of CSV, TSV and Excel files
+func ValidateTableOptions(opts ExtractOptions) error {
+	if opts.SkipRows < 0 {
+		return fmt.Errorf("skipRows must be 0 or greater, got %d", opts.SkipRows)
+	}
+	if opts.MaxParseErrors < 0 {
+		return fmt.Errorf("maxParseErrors must be 0 or greater, got %d", opts.MaxParseErrors)
+	}
+	return nil
+}
+
+// ParseError is a malformed row that a lenient read skipped
+type ParseError struct {
+	// Role is the part the file plays in a run, as in InputFile
+	Role string `json:"role,omitempty"`
+	File string `json:"file"`
+	// Line is the 1-based line of the file the row starts on
+	Line    int    `json:"line"`
+	Message string `json:"error"`
+}
+
+// ErrTooManyParseErrors is returned when a lenient read finds more malformed rows than allowed
+var ErrTooManyParseErrors = errors.New("too many parse errors")
+
+// IsMalformedInput reports whether err was caused by an input file that could not be read as
+// email data, rather than by the server
+func IsMalformedInput(err error) bool {
+	var (
+		csvErr      *csv.ParseError
+		parseErr    *FileParseError
+		emptyErr    *EmptyFileError
+		noColumnErr *NoEmailColumnError
+		noSheetErr  *NoSheetError
+		formatErr   *UnsupportedFormatError
+	)
+	return errors.As(err, &csvErr) || errors.As(err, &parseErr) || errors.As(err, &emptyErr) ||
+		errors.As(err, &noColumnErr) || errors.As(err, &noSheetErr) || errors.As(err, &formatErr) ||
+		errors.Is(err, ErrTooManyParseErrors)
+}
+
+// SourceLocation identifies the cell an email was read from
+type SourceLocation struct {
+	File  string `json:"file"`
+	Sheet string `json:"sheet,omitempty"`
+	// Row is the 1-based row number, counting the header row
+	Row int `json:"row"`
+	// Column is the column letter, e.g. "B"
+	Column string `json:"column"`
+}
+
+// String formats the location for reports, e.g. "contacts.xlsx, sheet Clients, row 12, column B"
+func (l SourceLocation) String() string {
+	if l.Sheet != "" {
+		return fmt.Sprintf("%s, sheet %s, row %d, column %s", l.File, l.Sheet, l.Row, l.Column)
+	}
+	return fmt.Sprintf("%s, row %d, column %s", l.File, l.Row, l.Column)
+}
+
+// formatLocation formats an optional location, returning an empty string when it is nil
+func formatLocation(location *SourceLocation) string {
+	if location == nil {
+		return ""
+	}
+	return location.String()
+}
+
+// ExtractedEmail is a candidate email together with the cell it was read from
+type ExtractedEmail struct {
+	Email string
+	// DisplayName is the name given with the address in the cell, e.g. "Nguyen Van A" in
+	// "Nguyen Van A <a@x.vn>"
+	DisplayName string
+	Location    SourceLocation
+	// Fields holds the values of the columns requested in ExtractOptions.Fields, by header name
+	Fields map[string]string
+}
+
+// Extraction is the result of reading an input file
+type Extraction struct {
+	Emails []ExtractedEmail
+	// Files describes how the file was read; ZIP archives list each entry
+	Files []InputFile
+	// ParseErrors are the malformed rows skipped by a lenient read
+	ParseErrors []ParseError
+}
+
+// InputFile describes how an input file, or an entry of a ZIP archive, was read
+type InputFile struct {
+	// Role is the part the file plays in a run, e.g. "first", "second" or a source label
+	Role   string `json:"role,omitempty"`
+	File   string `json:"file"`
+	Format string `json:"format"`
+	// Dialect is the detected or given dialect of CSV and TSV files
+	Dialect *CSVDialect `json:"dialect,omitempty"`
+	// HeaderRow is the 1-based row of the header of CSV, TSV and Excel files, or 0 without header
+	HeaderRow int `json:"headerRow,omitempty"`
+	// SkippedRows is the number of leading rows that were not read as data: the rows skipped
+	// with SkipRows and the header row
+	SkippedRows int `json:"skippedRows,omitempty"`
+}
+
+// skippedRows returns the number of leading rows skipped in all files of the extraction
+func (e *Extraction) skippedRows() int {
+	skipped := 0
+	for _, file := range e.Files {
+		skipped += file.SkippedRows
+	}
+	return skipped
+}
+
+// parseErrors returns the parse errors of the extraction with their role set
+func (e *Extraction) parseErrors(role string) []ParseError {
+	parseErrors := make([]ParseError, len(e.ParseErrors))
+	for i, parseError := range e.ParseErrors {
+		parseError.Role = role
+		parseErrors[i] = parseError
+	}
+	return parseErrors
+}
+
+// inputs returns the files of the extraction with their role set
+func (e *Extraction) inputs(role string) []InputFile {
+	files := make([]InputFile, len(e.Files))
+	for i, file := range e.Files {
+		file.Role = role
+		files[i] = file
+	}
+	return files
+}
+
+// Extractor reads the candidate emails of one input format
+type Extractor func(ctx context.Context, filePath string, opts ExtractOptions) (*Extraction, error)
+
+// extractors maps lower-case file extensions, including the dot, to their extractors
+var extractors = map[string]Extractor{}
+
+// RegisterExtractor makes an input format available for files with the given extension
+func RegisterExtractor(ext string, extractor Extractor) {
+	extractors[strings.ToLower(ext)] = extractor
+}
+
+// InputExtensions returns the extensions of the supported input files, sorted
+func InputExtensions() []string {
+	exts := make([]string, 0, len(extractors))
+	for ext := range extractors {
+		exts = append(exts, ext)
+	}
+	sort.Strings(exts)
+	return exts
+}
+
+func init() {
+	RegisterExtractor(".csv", extractEmailsFromCSV)
+	RegisterExtractor(".tsv", extractEmailsFromTSV)
+	RegisterExtractor(".tab", extractEmailsFromTSV)
+	RegisterExtractor(".xlsx", extractEmailsFromExcel)
+	RegisterExtractor(".json", extractEmailsFromJSON)
+	RegisterExtractor(".ndjson", extractEmailsFromNDJSON)
+	RegisterExtractor(".jsonl", extractEmailsFromNDJSON)
+	RegisterExtractor(".txt", extractEmailsFromText)
+	RegisterExtractor(".zip", extractEmailsFromZip)
+}
+
+// ExtractEmails extracts emails from an input file. The format is chosen by the file extension,
+// or by sniffing the content when the extension is not one of InputExtensions().
+func ExtractEmails(ctx context.Context, filePath string, opts ExtractOptions) (*Extraction, error) {
+	logger := utils.GetLogger()
+	defer utils.LogExecutionTime(fmt.Sprintf("ExtractEmails(%s)", filePath))()
+
+	if info, err := os.Stat(filePath); err == nil && info.Size() == 0 {
+		return nil, &EmptyFileError{File: filepath.Base(filePath), Reason: "the file has no content"}
+	}
+
+	ext := strings.ToLower(filepath.Ext(filePath))
+	extractor, ok := extractors[ext]
+	if !ok {
+		sniffed, err := sniffInputFormat(filePath)
+		if err != nil {
+			return nil, err
+		}
+		logger.Debug("Detected format %s for %s", sniffed, filePath)
+		ext, extractor = sniffed, extractors[sniffed]
+	}
+	logger.Info("Extracting emails from %s (format: %s)", filePath, ext)
+
+	extraction, err := extractor(ctx, filePath, opts)
+	if err != nil {
+		logger.Error("Failed to extract emails from %s: %v", filePath, err)
+		return nil, err
+	}
+	// Cells may hold display names, mailto: links or several addresses
+	extraction.Emails = expandAddressLists(extraction.Emails)
+	if len(extraction.Files) == 0 {
+		extraction.Files = []InputFile{{}}
+	}
+	if len(extraction.Files) == 1 && extraction.Files[0].File == "" {
+		extraction.Files[0].File = filepath.Base(filePath)
+		extraction.Files[0].Format = strings.TrimPrefix(ext, ".")
+	}
+
+	logger.Info("Successfully extracted %d emails from %s", len(extraction.Emails), filePath)
+	return extraction, nil
+}
+
+// extractEmailsFromCSV extracts emails from a CSV file, detecting its delimiter
+func extractEmailsFromCSV(ctx context.Context, filePath string, opts ExtractOptions) (*Extraction, error) {
+	return extractEmailsFromDelimited(ctx, filePath, opts, 0)
+}
+
+// extractEmailsFromTSV extracts emails from a tab-separated file
+func extractEmailsFromTSV(ctx context.Context, filePath string, opts ExtractOptions) (*Extraction, error) {
+	return extractEmailsFromDelimited(ctx, filePath, opts, '\t')
+}
+
+// extractEmailsFromDelimited extracts emails from a CSV-like file. The encoding, BOM and quote
+// style are detected, and so is the field separator when comma is 0; options override them.
+// This version is optimized for large files with streaming processing
+func extractEmailsFromDelimited(ctx context.Context, filePath string, opts ExtractOptions, comma rune) (*Extraction, error) {
+	logger := utils.GetLogger()
+	defer utils.LogExecutionTime("extractEmailsFromDelimited")()
+	logger.Debug("Starting delimited extraction from %s", filePath)
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	input, dialect, err := openDialect(file, opts, comma)
+	if err != nil {
+		return nil, withFileName(err, filepath.Base(filePath))
+	}
+	logger.Info("Reading %s with %s", filePath, dialect)
+
+	// The dialect reader is buffered, so records are read efficiently
+	reader := csv.NewReader(input)
+	dialect.apply(reader)
+
+	// Skipped rows, such as title banners, may have any number of fields
+	if opts.SkipRows > 0 {
+		reader.FieldsPerRecord = -1
+	}
+	// A lenient read accepts stray quotes and checks the number of fields itself, so that
+	// malformed rows are skipped instead of ending the read
+	if opts.Lenient {
+		reader.FieldsPerRecord = -1
+		reader.LazyQuotes = true
+	}
+	fileName := filepath.Base(filePath)
+	layout := newTableLayout(opts, fileName)
+
+	maxParseErrors := opts.MaxParseErrors
+	if maxParseErrors == 0 {
+		maxParseErrors = config.Get().MaxParseErrors
+	}
+	var parseErrors []ParseError
+	skipRow := func(line int, message string) error {
+		parseErrors = append(parseErrors, ParseError{File: fileName, Line: line, Message: message})
+		logger.Debug("Skipping malformed row on line %d of %s: %s", line, filePath, message)
+		if len(parseErrors) > maxParseErrors {
+			return &FileParseError{File: fileName, Err: fmt.Errorf("%w: more than %d malformed rows", ErrTooManyParseErrors, maxParseErrors)}
+		}
+		return nil
+	}
+
+	// Pre-allocate emails slice with a reasonable capacity
+	// This avoids repeated slice growth and memory reallocation
+	emails := make([]ExtractedEmail, 0, 1000) // Start with capacity for 1000 emails
+
+	// Process records one at a time to avoid loading the entire file into memory.
+	// Rows are numbered like a spreadsheet would show them: the first line is row 1, blank
+	// lines count as rows and a quoted field spanning several lines stays in one row.
+	row, lastLine := 0, 0
+	for {
+		if err := ctx.Err(); err != nil {
+			logger.Warn("Delimited extraction from %s cancelled after %d emails", filePath, len(emails))
+			return nil, err
+		}
+
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			if !opts.Lenient {
+				return nil, &FileParseError{File: fileName, Line: parseErr.Line, Column: parseErr.Column,
+					Err: fmt.Errorf("%w; use lenient parsing to skip malformed rows", parseErr.Err)}
+			}
+			row += parseErr.StartLine - lastLine
+			lastLine = parseErr.Line
+			if err := skipRow(parseErr.StartLine, fmt.Sprintf("column %d: %v", parseErr.Column, parseErr.Err)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		startLine, _ := reader.FieldPos(0)
+		row += startLine - lastLine
+		lastLine = recordEndLine(reader, record)
+
+		leading, err := layout.leading(record, row)
+		if err != nil {
+			return nil, err
+		}
+		if leading {
+			// After the skipped rows, the header or first data row sets the number of fields
+			if !opts.Lenient && !layout.resolved && layout.skipped == opts.SkipRows {
+				reader.FieldsPerRecord = 0
+			}
+			continue
+		}
+		if opts.Lenient && len(record) != layout.width {
+			message := fmt.Sprintf("wrong number of fields: expected %d, got %d", layout.width, len(record))
+			if err := skipRow(startLine, message); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		// Extract email from the selected column if it's valid
+		column := layout.column
+		if len(record) > column && record[column] != "" {
+			// Only perform basic validation here for speed
+			// The detailed validation will happen later
+			if strings.Contains(record[column], "@") {
+				emails = append(emails, ExtractedEmail{
+					Email:    record[column],
+					Location: SourceLocation{File: fileName, Row: row, Column: layout.columnName},
+					Fields:   rowFields(record, layout.fields),
+				})
+			}
+		}
+	}
+
+	logger.Debug("Delimited extraction completed, found %d potential emails", len(emails))
+	if len(parseErrors) > 0 {
+		logger.Warn("Skipped %d malformed rows of %s", len(parseErrors), filePath)
+	}
+	inputFile := layout.inputFile()
+	inputFile.Dialect = dialect
+	return &Extraction{Emails: emails, Files: []InputFile{inputFile}, ParseErrors: parseErrors}, nil
+}
+
+// tableLayout tracks the leading rows of a CSV file or worksheet, the rows skipped with
+// SkipRows and the optional header, and resolves the email and field columns against the header
+type tableLayout struct {
+	opts     ExtractOptions
+	fileName string
+	skipped  int
+	// resolved is set once the header, or the first data row of a file without header, was seen
+	resolved  bool
+	headerRow int
+	// width is the number of fields of the header, or of the first data row without header
+	width      int
+	column     int
+	columnName string
+	fields     map[string]int
+}
+
+// newTableLayout returns the layout of a table read with opts
+func newTableLayout(opts ExtractOptions, fileName string) *tableLayout {
+	return &tableLayout{opts: opts, fileName: fileName, columnName: "A"}
+}
+
+// leading reports whether a row is one of the leading rows, which are not data. The first row
+// after the skipped ones is the header when HasHeader is set, or when it does not look like data.
+func (t *tableLayout) leading(row []string, rowNumber int) (bool, error) {
+	if t.skipped < t.opts.SkipRows {
+		t.skipped++
+		return true, nil
+	}
+	if t.resolved {
+		return false, nil
+	}
+	t.resolved = true
+
+	isHeader := !looksLikeData(row)
+	if t.opts.HasHeader != nil {
+		isHeader = *t.opts.HasHeader
+	}
+	var header []string
+	if isHeader {
+		header = row
+		t.headerRow = rowNumber
+	}
+
+	column, err := resolveColumn(t.opts.Column, header)
+	if err != nil {
+		return false, withFileName(err, t.fileName)
+	}
+	if t.columnName, err = excelize.ColumnNumberToName(column + 1); err != nil {
+		return false, err
+	}
+	t.column = column
+	t.width = len(row)
+	t.fields = resolveFields(t.opts.Fields, header, t.fileName)
+	utils.GetLogger().Debug("Reading emails from column %d of %s (header row: %d, skipped rows: %d)",
+		column+1, t.fileName, t.headerRow, t.skipped)
+	return isHeader, nil
+}
+
+// inputFile describes the leading rows of the table
+func (t *tableLayout) inputFile() InputFile {
+	file := InputFile{HeaderRow: t.headerRow, SkippedRows: t.skipped}
+	if t.headerRow > 0 {
+		file.SkippedRows++
+	}
+	return file
+}
+
+// looksLikeData reports whether a row holds data rather than column names, i.e. has a cell with "@"
+func looksLikeData(row []string) bool {
+	for _, cell := range row {
+		if strings.Contains(cell, "@") {
+			return true
+		}
+	}
+	return false
+}
+
+// recordEndLine returns the line on which the record last read by reader ends
+func recordEndLine(reader *csv.Reader, record []string) int {
+	if len(record) == 0 {
+		return 0
+	}
+	line, _ := reader.FieldPos(len(record) - 1)
+	return line + strings.Count(record[len(record)-1], "\n")
+}
+
+// extractEmailsFromExcel extracts emails from an Excel file
+// This version is optimized for large files with streaming processing
+func extractEmailsFromExcel(ctx context.Context, filePath string, opts ExtractOptions) (*Extraction, error) {
+	logger := utils.GetLogger()
+	defer utils.LogExecutionTime("extractEmailsFromExcel")()
+	logger.Debug("Starting Excel extraction from %s", filePath)
+	// Open the Excel file with streaming mode for better performance with large files
+	fileName := filepath.Base(filePath)
+	f, err := excelize.OpenFile(filePath, excelize.Options{
+		RawCellValue: true, // Get raw values for better performance
+	})
+	if err != nil {
+		return nil, &FileParseError{File: fileName, Err: err}
+	}
+	defer f.Close()
+
+	// Get the requested sheet, or the first one
+	sheet, err := resolveSheet(f.GetSheetList(), opts.Sheet)
+	if err != nil {
+		return nil, withFileName(err, fileName)
+	}
+	logger.Debug("Reading emails from sheet %q of %s", sheet, filePath)
+
+	// Pre-allocate emails slice with a reasonable capacity
+	emails := make([]ExtractedEmail, 0, 1000) // Start with capacity for 1000 emails
+
+	// Use rows iterator for streaming large files
+	rows, err := f.Rows(sheet)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	layout := newTableLayout(opts, fileName)
+
+	// Process each row; the iterator also visits empty rows, so the count matches the sheet
+	rowNumber := 0
+	for rows.Next() {
+		if err := ctx.Err(); err != nil {
+			logger.Warn("Excel extraction from %s cancelled after %d emails", filePath, len(emails))
+			return nil, err
+		}
+
+		row, err := rows.Columns()
+		if err != nil {
+			return nil, &FileParseError{File: fileName, Line: rowNumber + 1, Err: err}
+		}
+		rowNumber++
+
+		leading, err := layout.leading(row, rowNumber)
+		if err != nil {
+			return nil, err
+		}
+		if leading {
+			continue
+		}
+
+		// Extract email from the selected column if it exists
+		column := layout.column
+		if len(row) > column && row[column] != "" {
+			// Only perform basic validation here for speed
+			// The detailed validation will happen later
+			if strings.Contains(row[column], "@") {
+				emails = append(emails, ExtractedEmail{
+					Email:    row[column],
+					Location: SourceLocation{File: fileName, Sheet: sheet, Row: rowNumber, Column: layout.columnName},
+					Fields:   rowFields(row, layout.fields),
+				})
+			}
+		}
+	}
+
+	logger.Debug("Excel extraction completed, found %d potential emails", len(emails))
+	return &Extraction{Emails: emails, Files: []InputFile{layout.inputFile()}}, nil
+}
+
+// resolveColumn returns the 0-based index of the column selected by spec.
+// The spec is matched against the header names first, then as a 1-based index,
+// then as a column letter. An empty spec selects the first column.
+func resolveColumn(spec string, header []string) (int, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return 0, nil
+	}
+
+	for i, name := range header {
+		if strings.EqualFold(strings.TrimSpace(name), spec) {
+			return i, nil
+		}
+	}
+
+	if n, err := strconv.Atoi(spec); err == nil {
+		if n < 1 {
+			return 0, &InvalidOptionError{Option: "column", Value: spec, Reason: "a column index must be 1 or greater"}
+		}
+		return n - 1, nil
+	}
+
+	if n, err := excelize.ColumnNameToNumber(spec); err == nil {
+		return n - 1, nil
+	}
+
+	names := make([]string, len(header))
+	for i, name := range header {
+		names[i] = strings.TrimSpace(name)
+	}
+	return 0, &NoEmailColumnError{Column: spec, Header: names}
+}
+
+// resolveFields returns the 0-based indexes of the named columns in header. Columns that are
+// not in the header are logged and left out, so their values stay empty.
+func resolveFields(names []string, header []string, fileName string) map[string]int {
+	if len(names) == 0 {
+		return nil
+	}
+	columns := make(map[string]int, len(names))
+	for _, name := range names {
+		found := false
+		for i, headerName := range header {
+			if strings.EqualFold(strings.TrimSpace(headerName), strings.TrimSpace(name)) {
+				columns[name] = i
+				found = true
+				break
+			}
+		}
+		if !found {
+			utils.GetLogger().Warn("Column %q not found in header of %s", name, fileName)
+		}
+	}
+	return columns
+}
+
+// rowFields returns the values of the resolved field columns in a row
+func rowFields(row []string, columns map[string]int) map[string]string {
+	if len(columns) == 0 {
+		return nil
+	}
+	fields := make(map[string]string, len(columns))
+	for name, i := range columns {
+		if i < len(row) {
+			fields[name] = row[i]
+		}
+	}
+	return fields
+}
+
+// resolveSheet returns the sheet selected by spec, either by name or by 1-based index.
+// An empty spec selects the first sheet.
+func resolveSheet(sheets []string, spec string) (string, error) {
+	if len(sheets) == 0 {
+		return "", &EmptyFileError{Reason: "no sheets found in Excel file"}
+	}
+
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return sheets[0], nil
+	}
+
+	for _, name := range sheets {
+		if strings.EqualFold(name, spec) {
+			return name, nil
+		}
+	}
+
+	if n, err := strconv.Atoi(spec); err == nil {
+		if n < 1 || n > len(sheets) {
+			return "", &NoSheetError{Sheet: spec, Sheets: sheets}
+		}
+		return sheets[n-1], nil
+	}
+
+	return "", &NoSheetError{Sheet: spec, Sheets: sheets}
+}
+
+// extractEmailsFromText extracts emails from a plain text file with one email per line. The
+// encoding is the option, else the BOM, else detected, as for CSV files.
+func extractEmailsFromText(ctx context.Context, filePath string, opts ExtractOptions) (*Extraction, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	fileName := filepath.Base(filePath)
+	input, _, _, dialect, err := openText(file, opts)
+	if err != nil {
+		return nil, withFileName(err, fileName)
+	}
+	utils.GetLogger().Info("Reading %s with encoding %s", filePath, dialect.Encoding)
+
+	emails := make([]ExtractedEmail, 0, 1000)
+	scanner := bufio.NewScanner(input)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	for line := 1; scanner.Scan(); line++ {
+		if err := checkCancelled(ctx, line); err != nil {
+			return nil, err
+		}
+		email := strings.TrimSpace(scanner.Text())
+		if strings.Contains(email, "@") {
+			emails = append(emails, ExtractedEmail{
+				Email:    email,
+				Location: SourceLocation{File: fileName, Row: line, Column: "A"},
+			})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return &Extraction{Emails: emails}, nil
+}
+
+// ErrUnsupportedFormat is returned for input files whose format cannot be determined
+var ErrUnsupportedFormat = errors.New("unsupported file format")
+
+// utf8BOM is the byte order mark some editors put at the start of UTF-8 files
+const utf8BOM = "\ufeff"
+
+// maxLineSize is the longest line the line-based extractors accept
+const maxLineSize = 16 * 1024 * 1024
+
+// sniffSize is how much of a file is read to detect its format
+const sniffSize = 8 * 1024
+
+// oleSignature starts OLE2 compound files, such as Excel 97-2003 workbooks
+var oleSignature = []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}
+
+// sniffInputFormat detects the format of a file from its content and returns the extension
+// of the matching extractor
+func sniffInputFormat(filePath string) (string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	head := make([]byte, sniffSize)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+	head = head[:n]
+
+	// Excel 97-2003 workbooks are OLE2 compound files, which excelize cannot read
+	if bytes.HasPrefix(head, oleSignature) {
+		return "", &UnsupportedFormatError{File: filepath.Base(filePath),
+			Reason: "it is an Excel 97-2003 (.xls) workbook, which cannot be read; save it as .xlsx or CSV"}
+	}
+	// Excel workbooks are ZIP archives with a workbook part
+	if bytes.HasPrefix(head, []byte("PK\x03\x04")) {
+		if isExcelWorkbook(filePath) {
+			return ".xlsx", nil
+		}
+		return ".zip", nil
+	}
+	// UTF-16 text is sniffed as UTF-8; CSV and TSV files are decoded by their extractor
+	name, _ := detectBOM(head)
+	if !strings.HasPrefix(name, "utf-16") {
+		name, _ = detectUTF16(head)
+	}
+	if name != "" && name != encodingUTF8 {
+		enc, _, err := lookupEncoding(name)
+		if err != nil {
+			return "", err
+		}
+		if head, err = enc.NewDecoder().Bytes(head[:len(head)&^1]); err != nil {
+			return "", err
+		}
+	}
+	if bytes.IndexByte(head, 0) >= 0 {
+		return "", &UnsupportedFormatError{File: filepath.Base(filePath), Reason: "it looks like a binary file"}
+	}
+
+	text := bytes.TrimLeft(bytes.TrimPrefix(head, []byte(utf8BOM)), " \t\r\n")
+	firstLine := text
+	if i := bytes.IndexByte(text, '\n'); i >= 0 {
+		firstLine = text[:i]
+	}
+	switch {
+	case bytes.HasPrefix(text, []byte("[")):
+		return ".json", nil
+	case bytes.HasPrefix(text, []byte("{")):
+		// A complete object on the first line is the start of an NDJSON file
+		if json.Valid(bytes.TrimSpace(firstLine)) {
+			return ".ndjson", nil
+		}
+		return ".json", nil
+	case bytes.ContainsRune(firstLine, '\t'):
+		return ".tsv", nil
+	case bytes.ContainsAny(firstLine, ",;"):
+		return ".csv", nil
+	default:
+		return ".txt", nil
+	}
+}
+
+// isExcelWorkbook reports whether a ZIP archive is an Excel workbook
+func isExcelWorkbook(filePath string) bool {
+	archive, err := zip.OpenReader(filePath)
+	if err != nil {
+		return false
+	}
+	defer archive.Close()
+	for _, entry := range archive.File {
+		if entry.Name == "xl/workbook.xml" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,321 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// jsonPathSegment is a step of a JSON path: an object key, an array index, or a wildcard
+// over the elements of an array or the values of an object
+type jsonPathSegment struct {
+	key      string
+	index    int
+	wildcard bool
+}
+
+// parseJSONPath parses the subset of JSONPath used to select emails: "$.contacts[*].email",
+// "data[0].emails[*]" or "$['e-mail']". The leading "$" is optional.
+func parseJSONPath(path string) ([]jsonPathSegment, error) {
+	rest := strings.TrimPrefix(strings.TrimSpace(path), "$")
+	var segments []jsonPathSegment
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, &InvalidOptionError{Option: "JSON path", Value: path, Reason: "missing ]"}
+			}
+			inner := strings.TrimSpace(rest[1:end])
+			rest = rest[end+1:]
+			switch {
+			case inner == "*":
+				segments = append(segments, jsonPathSegment{wildcard: true})
+			case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+				segments = append(segments, jsonPathSegment{key: inner[1 : len(inner)-1], index: -1})
+			default:
+				index, err := strconv.Atoi(inner)
+				if err != nil || index < 0 {
+					return nil, &InvalidOptionError{Option: "JSON path", Value: path, Reason: fmt.Sprintf("bad index %q", inner)}
+				}
+				segments = append(segments, jsonPathSegment{index: index})
+			}
+			continue
+		}
+
+		end := strings.IndexAny(rest, ".[")
+		if end < 0 {
+			end = len(rest)
+		}
+		key := rest[:end]
+		rest = rest[end:]
+		if key == "" {
+			return nil, &InvalidOptionError{Option: "JSON path", Value: path, Reason: "empty key"}
+		}
+		if key == "*" {
+			segments = append(segments, jsonPathSegment{wildcard: true})
+		} else {
+			segments = append(segments, jsonPathSegment{key: key, index: -1})
+		}
+	}
+	return segments, nil
+}
+
+// ValidateJSONPath checks the JSONPath option, so that a malformed path is rejected before the
+// files are read
+func ValidateJSONPath(path string) error {
+	if strings.TrimSpace(path) == "" {
+		return nil
+	}
+	_, err := parseJSONPath(path)
+	return err
+}
+
+// jsonMatch is a string selected from a JSON document
+type jsonMatch struct {
+	value string
+	// path is the concrete path of the value, e.g. "$[3].email"
+	path string
+	// row is the 1-based element of the top-level array the value is in, or 1
+	row int
+	// parent is the object holding the value, if any
+	parent map[string]interface{}
+}
+
+// jsonSelector returns the path segments selecting the emails of a document. Without a JSON path
+// it selects the Column key of the document or its elements, and nil to select every string.
+func jsonSelector(document interface{}, opts ExtractOptions) ([]jsonPathSegment, error) {
+	if strings.TrimSpace(opts.JSONPath) != "" {
+		return parseJSONPath(opts.JSONPath)
+	}
+	if opts.Column == "" {
+		return nil, nil
+	}
+	key := jsonPathSegment{key: opts.Column, index: -1}
+	if _, ok := document.([]interface{}); ok {
+		return []jsonPathSegment{{wildcard: true}, key}, nil
+	}
+	return []jsonPathSegment{key}, nil
+}
+
+// matchJSON calls visit for every string in value that is selected by segments. With nil
+// segments every string that contains "@" is selected.
+func matchJSON(value interface{}, segments []jsonPathSegment, selectAll bool, path string, row int,
+	parent map[string]interface{}, visit func(jsonMatch)) {
+	if !selectAll && len(segments) == 0 {
+		if text, ok := jsonText(value); ok {
+			visit(jsonMatch{value: text, path: path, row: row, parent: parent})
+		}
+		return
+	}
+
+	switch typed := value.(type) {
+	case []interface{}:
+		for i, element := range typed {
+			if !selectAll && !segments[0].wildcard && segments[0].index != i {
+				continue
+			}
+			elementRow := row
+			if path == "$" {
+				elementRow = i + 1
+			}
+			matchJSON(element, tail(segments, selectAll), selectAll, fmt.Sprintf("%s[%d]", path, i), elementRow, parent, visit)
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(typed))
+		for key := range typed {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		if !selectAll && !segments[0].wildcard {
+			if segments[0].index >= 0 {
+				return
+			}
+			key, ok := objectKey(typed, segments[0].key)
+			if !ok {
+				return
+			}
+			keys = []string{key}
+		}
+		for _, key := range keys {
+			matchJSON(typed[key], tail(segments, selectAll), selectAll, jsonChildPath(path, key), row, typed, visit)
+		}
+	case string:
+		if selectAll && strings.Contains(typed, "@") {
+			visit(jsonMatch{value: typed, path: path, row: row, parent: parent})
+		}
+	}
+}
+
+// tail returns the segments after the first one
+func tail(segments []jsonPathSegment, selectAll bool) []jsonPathSegment {
+	if selectAll {
+		return nil
+	}
+	return segments[1:]
+}
+
+// objectKey returns the key of object named name, preferring an exact match over a
+// case-insensitive one
+func objectKey(object map[string]interface{}, name string) (string, bool) {
+	if _, ok := object[name]; ok {
+		return name, true
+	}
+	for key := range object {
+		if strings.EqualFold(key, name) {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// jsonChildPath appends an object key to a path, quoting keys that are not plain names
+func jsonChildPath(path, key string) string {
+	for _, r := range key {
+		if !(r == '_' || r == '-' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z') {
+			return fmt.Sprintf("%s[%q]", path, key)
+		}
+	}
+	return path + "." + key
+}
+
+// jsonText returns a scalar JSON value as text
+func jsonText(value interface{}) (string, bool) {
+	switch typed := value.(type) {
+	case string:
+		return typed, true
+	case json.Number:
+		return typed.String(), true
+	case bool:
+		return strconv.FormatBool(typed), true
+	}
+	return "", false
+}
+
+// jsonFields returns the requested keys of the object an email was read from
+func jsonFields(parent map[string]interface{}, names []string) map[string]string {
+	if len(names) == 0 || parent == nil {
+		return nil
+	}
+	fields := make(map[string]string, len(names))
+	for _, name := range names {
+		if key, ok := objectKey(parent, name); ok {
+			if text, ok := jsonText(parent[key]); ok {
+				fields[name] = text
+			}
+		}
+	}
+	return fields
+}
+
+// extractEmailsFromDocument extracts the emails selected in a decoded JSON document
+func extractEmailsFromDocument(document interface{}, opts ExtractOptions, location SourceLocation, lineRows bool) ([]ExtractedEmail, error) {
+	segments, err := jsonSelector(document, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var emails []ExtractedEmail
+	matchJSON(document, segments, segments == nil, "$", 1, nil, func(match jsonMatch) {
+		if !strings.Contains(match.value, "@") {
+			return
+		}
+		emailLocation := location
+		emailLocation.Column = match.path
+		if !lineRows {
+			emailLocation.Row = match.row
+		}
+		emails = append(emails, ExtractedEmail{
+			Email:    strings.TrimSpace(match.value),
+			Location: emailLocation,
+			Fields:   jsonFields(match.parent, opts.Fields),
+		})
+	})
+	return emails, nil
+}
+
+// extractEmailsFromJSON extracts emails from a JSON document. Rows are the elements of a
+// top-level array and columns are the paths of the values.
+func extractEmailsFromJSON(ctx context.Context, filePath string, opts ExtractOptions) (*Extraction, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	fileName := filepath.Base(filePath)
+	data = bytes.TrimPrefix(data, []byte(utf8BOM))
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil {
+		if err == io.EOF {
+			return nil, &EmptyFileError{File: fileName, Reason: "no JSON document"}
+		}
+		parseErr := &FileParseError{File: fileName, Err: fmt.Errorf("invalid JSON: %w", err)}
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			parseErr.Line, parseErr.Column = lineColumn(data, syntaxErr.Offset)
+		}
+		return nil, parseErr
+	}
+
+	emails, err := extractEmailsFromDocument(document, opts, SourceLocation{File: fileName}, false)
+	if err != nil {
+		return nil, withFileName(err, fileName)
+	}
+	return &Extraction{Emails: emails}, nil
+}
+
+// extractEmailsFromNDJSON extracts emails from a file with one JSON document per line. Rows
+// are line numbers and columns are the paths of the values within the line.
+func extractEmailsFromNDJSON(ctx context.Context, filePath string, opts ExtractOptions) (*Extraction, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	fileName := filepath.Base(filePath)
+	emails := make([]ExtractedEmail, 0, 1000)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	for line := 1; scanner.Scan(); line++ {
+		if err := checkCancelled(ctx, line); err != nil {
+			return nil, err
+		}
+		text := bytes.TrimSpace(bytes.TrimPrefix(scanner.Bytes(), []byte(utf8BOM)))
+		if len(text) == 0 {
+			continue
+		}
+
+		decoder := json.NewDecoder(bytes.NewReader(text))
+		decoder.UseNumber()
+		var document interface{}
+		if err := decoder.Decode(&document); err != nil {
+			return nil, &FileParseError{File: fileName, Line: line, Err: fmt.Errorf("invalid JSON: %w", err)}
+		}
+		lineEmails, err := extractEmailsFromDocument(document, opts, SourceLocation{File: fileName, Row: line}, true)
+		if err != nil {
+			return nil, withFileName(err, fileName)
+		}
+		emails = append(emails, lineEmails...)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return &Extraction{Emails: emails}, nil
+}
@@ -0,0 +1,336 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeInput writes content to a file named name in a temporary directory and returns its path
+func writeInput(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// extractedRows returns the email and row of each extracted email
+func extractedRows(emails []ExtractedEmail) map[string]int {
+	rows := make(map[string]int, len(emails))
+	for _, email := range emails {
+		rows[email.Email] = email.Location.Row
+	}
+	return rows
+}
+
+func TestExtractDelimitedRows(t *testing.T) {
+	tests := []struct {
+		name          string
+		content       string
+		opts          ExtractOptions
+		wantRows      map[string]int
+		wantColumn    string
+		wantHeaderRow int
+	}{
+		{
+			name:       "without header",
+			content:    "a@x.com\nb@x.com\n",
+			wantRows:   map[string]int{"a@x.com": 1, "b@x.com": 2},
+			wantColumn: "A",
+		},
+		{
+			name:          "header counts as a row",
+			content:       "email\na@x.com\nb@x.com\n",
+			wantRows:      map[string]int{"a@x.com": 2, "b@x.com": 3},
+			wantColumn:    "A",
+			wantHeaderRow: 1,
+		},
+		{
+			name:          "skipped rows before the header",
+			content:       "Contacts export\n\nname,email\nAnn,a@x.com\n",
+			opts:          ExtractOptions{SkipRows: 1, Column: "email"},
+			wantRows:      map[string]int{"a@x.com": 4},
+			wantColumn:    "B",
+			wantHeaderRow: 3,
+		},
+		{
+			name:          "blank lines count as rows",
+			content:       "email\n\na@x.com\n\n\nb@x.com\n",
+			wantRows:      map[string]int{"a@x.com": 3, "b@x.com": 6},
+			wantColumn:    "A",
+			wantHeaderRow: 1,
+		},
+		{
+			name:          "multiline quoted field stays in one row",
+			content:       "note,email\n\"first\nsecond\",a@x.com\nthird,b@x.com\n",
+			opts:          ExtractOptions{Column: "2"},
+			wantRows:      map[string]int{"a@x.com": 2, "b@x.com": 3},
+			wantColumn:    "B",
+			wantHeaderRow: 1,
+		},
+		{
+			name:          "multiline last field",
+			content:       "email,note\na@x.com,\"first\nsecond\nthird\"\nb@x.com,x\n",
+			wantRows:      map[string]int{"a@x.com": 2, "b@x.com": 3},
+			wantColumn:    "A",
+			wantHeaderRow: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeInput(t, "contacts.csv", tt.content)
+			extraction, err := extractEmailsFromCSV(context.Background(), path, tt.opts)
+			if err != nil {
+				t.Fatalf("extractEmailsFromCSV() error = %v", err)
+			}
+			if got := extractedRows(extraction.Emails); !reflect.DeepEqual(got, tt.wantRows) {
+				t.Errorf("rows = %v, want %v", got, tt.wantRows)
+			}
+			for _, email := range extraction.Emails {
+				if email.Location.Column != tt.wantColumn || email.Location.File != "contacts.csv" {
+					t.Errorf("location of %s = %+v, want column %s of contacts.csv", email.Email, email.Location, tt.wantColumn)
+				}
+			}
+			if got := extraction.Files[0].HeaderRow; got != tt.wantHeaderRow {
+				t.Errorf("HeaderRow = %d, want %d", got, tt.wantHeaderRow)
+			}
+		})
+	}
+}
+
+func TestExtractDelimitedLenientParseErrors(t *testing.T) {
+	tests := []struct {
+		name            string
+		content         string
+		maxParseErrors  int
+		wantRows        map[string]int
+		wantParseErrors []int
+		wantErr         bool
+	}{
+		{
+			name:            "wrong number of fields",
+			content:         "email,name\na@x.com,Ann\nb@x.com\nc@x.com,Cy\n",
+			wantRows:        map[string]int{"a@x.com": 2, "c@x.com": 4},
+			wantParseErrors: []int{3},
+		},
+		{
+			name:            "row after a multiline field",
+			content:         "name,email\n\"An\nn\",a@x.com\nBo,b@x.com,extra\nCy,c@x.com\n",
+			wantRows:        map[string]int{"a@x.com": 2, "c@x.com": 4},
+			wantParseErrors: []int{4},
+		},
+		{
+			name:            "stray quotes are accepted",
+			content:         "email,name\na@x.com,An\"n\n",
+			wantRows:        map[string]int{"a@x.com": 2},
+			wantParseErrors: nil,
+		},
+		{
+			name:           "too many parse errors",
+			content:        "email,name\na@x.com\nb@x.com\nc@x.com,Cy\n",
+			maxParseErrors: 1,
+			wantErr:        true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			maxParseErrors := tt.maxParseErrors
+			if maxParseErrors == 0 {
+				maxParseErrors = 10
+			}
+			path := writeInput(t, "contacts.csv", tt.content)
+			opts := ExtractOptions{Lenient: true, MaxParseErrors: maxParseErrors, Column: "email"}
+			extraction, err := extractEmailsFromCSV(context.Background(), path, opts)
+			if tt.wantErr {
+				if !IsMalformedInput(err) {
+					t.Fatalf("extractEmailsFromCSV() error = %v, want too many parse errors", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("extractEmailsFromCSV() error = %v", err)
+			}
+			if got := extractedRows(extraction.Emails); !reflect.DeepEqual(got, tt.wantRows) {
+				t.Errorf("rows = %v, want %v", got, tt.wantRows)
+			}
+			var lines []int
+			for _, parseErr := range extraction.ParseErrors {
+				lines = append(lines, parseErr.Line)
+			}
+			if !reflect.DeepEqual(lines, tt.wantParseErrors) {
+				t.Errorf("parse error lines = %v, want %v", lines, tt.wantParseErrors)
+			}
+		})
+	}
+}
+
+func TestExtractDelimitedStrictParseError(t *testing.T) {
+	path := writeInput(t, "contacts.csv", "email,name\na@x.com,Ann\nb@x.com\n")
+	_, err := extractEmailsFromCSV(context.Background(), path, ExtractOptions{})
+	coded, ok := AsCodedError(err)
+	if !ok || coded.Code() != CodeParseError {
+		t.Fatalf("extractEmailsFromCSV() error = %v, want a parse error", err)
+	}
+	if line := coded.Details()["line"]; line != 3 {
+		t.Errorf("line = %v, want 3", line)
+	}
+}
+
+func TestExtractOptionErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		content  string
+		opts     ExtractOptions
+		wantCode string
+	}{
+		{name: "column index 0", file: "contacts.csv", content: "email\na@x.com\n", opts: ExtractOptions{Column: "0"}, wantCode: CodeInvalidRequest},
+		{name: "column not in header", file: "contacts.csv", content: "email\na@x.com\n", opts: ExtractOptions{Column: "mail"}, wantCode: CodeNoEmailColumn},
+		{name: "unterminated JSON path", file: "contacts.json", content: `[{"email":"a@x.com"}]`, opts: ExtractOptions{JSONPath: "$.a["}, wantCode: CodeInvalidRequest},
+		{name: "bad JSON path index", file: "contacts.json", content: `[{"email":"a@x.com"}]`, opts: ExtractOptions{JSONPath: "$[x].email"}, wantCode: CodeInvalidRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ExtractEmails(context.Background(), writeInput(t, tt.file, tt.content), tt.opts)
+			coded, ok := AsCodedError(err)
+			if !ok || coded.Code() != tt.wantCode {
+				t.Fatalf("ExtractEmails() error = %v, want code %s", err, tt.wantCode)
+			}
+			if file := coded.Details()["file"]; file != tt.file {
+				t.Errorf("file = %v, want %s", file, tt.file)
+			}
+		})
+	}
+}
+
+func TestResolveSheet(t *testing.T) {
+	sheets := []string{"Summary", "Contacts"}
+	tests := []struct {
+		spec    string
+		want    string
+		wantErr bool
+	}{
+		{spec: "", want: "Summary"},
+		{spec: "contacts", want: "Contacts"},
+		{spec: "2", want: "Contacts"},
+		{spec: "3", wantErr: true},
+		{spec: "0", wantErr: true},
+		{spec: "Leads", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := resolveSheet(sheets, tt.spec)
+			if tt.wantErr {
+				var noSheet *NoSheetError
+				if !errors.As(err, &noSheet) || !reflect.DeepEqual(noSheet.Sheets, sheets) {
+					t.Fatalf("resolveSheet(%q) error = %v, want a NoSheetError listing the sheets", tt.spec, err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("resolveSheet(%q) = %q, %v, want %q", tt.spec, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateJSONPath(t *testing.T) {
+	tests := []struct {
+		path    string
+		wantErr bool
+	}{
+		{path: ""},
+		{path: "$.contacts[*].email"},
+		{path: "data[0].emails[*]"},
+		{path: "$['e-mail']"},
+		{path: "$.a[", wantErr: true},
+		{path: "$.a[-1]", wantErr: true},
+		{path: "$..email", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			err := ValidateJSONPath(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateJSONPath(%q) error = %v, wantErr %v", tt.path, err, tt.wantErr)
+			}
+			if err != nil && !errors.As(err, new(*InvalidOptionError)) {
+				t.Errorf("ValidateJSONPath(%q) error = %T, want *InvalidOptionError", tt.path, err)
+			}
+		})
+	}
+}
+
+func TestExtractText(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		opts    ExtractOptions
+		want    map[string]int
+	}{
+		{
+			name:    "utf-8",
+			content: "a@x.com\nnot an email\nb@x.com\n",
+			want:    map[string]int{"a@x.com": 1, "b@x.com": 3},
+		},
+		{
+			name:    "utf-8 BOM",
+			content: "\xef\xbb\xbfa@x.com\r\nb@x.com\r\n",
+			want:    map[string]int{"a@x.com": 1, "b@x.com": 2},
+		},
+		{
+			name:    "utf-16le with BOM",
+			content: "\xff\xfe" + string(utf16LE("a@x.com\r\nđức@x.vn\r\n")),
+			want:    map[string]int{"a@x.com": 1, "đức@x.vn": 2},
+		},
+		{
+			name:    "utf-16le without BOM",
+			content: string(utf16LE("a@x.com\nb@x.com\n")),
+			want:    map[string]int{"a@x.com": 1, "b@x.com": 2},
+		},
+		{
+			name:    "encoding option",
+			content: "jos\xe9@x.com\n",
+			opts:    ExtractOptions{Encoding: "latin1"},
+			want:    map[string]int{"josé@x.com": 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			extraction, err := ExtractEmails(context.Background(), writeInput(t, "contacts.txt", tt.content), tt.opts)
+			if err != nil {
+				t.Fatalf("ExtractEmails() error = %v", err)
+			}
+			if got := extractedRows(extraction.Emails); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("emails = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractLegacyExcel(t *testing.T) {
+	content := string(oleSignature) + strings.Repeat("\x00", 504)
+	for _, name := range []string{"contacts.xls", "contacts.dat"} {
+		t.Run(name, func(t *testing.T) {
+			_, err := ExtractEmails(context.Background(), writeInput(t, name, content), ExtractOptions{})
+			coded, ok := AsCodedError(err)
+			if !ok || coded.Code() != CodeUnsupportedFormat || !strings.Contains(err.Error(), ".xlsx") {
+				t.Fatalf("ExtractEmails() error = %v, want an unsupported format error suggesting .xlsx", err)
+			}
+			if file := coded.Details()["file"]; file != name {
+				t.Errorf("file = %v, want %s", file, name)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"ness-to-odoo-golang-validation-api-tool/utils"
+)
+
+// maxZipEntrySize caps the uncompressed size of a ZIP entry, to guard against decompression bombs
+const maxZipEntrySize = 2 << 30
+
+// extractEmailsFromZip extracts and merges the emails of every supported file in a ZIP archive.
+// Locations name the entry inside the archive, e.g. "bundle.zip/2024/contacts.csv".
+func extractEmailsFromZip(ctx context.Context, filePath string, opts ExtractOptions) (*Extraction, error) {
+	logger := utils.GetLogger()
+	archive, err := zip.OpenReader(filePath)
+	if err != nil {
+		return nil, &FileParseError{File: filepath.Base(filePath), Err: fmt.Errorf("invalid ZIP archive: %w", err)}
+	}
+	defer archive.Close()
+
+	archiveName := filepath.Base(filePath)
+	result := &Extraction{}
+	for _, entry := range archive.File {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if skipZipEntry(entry) {
+			continue
+		}
+		if strings.EqualFold(path.Ext(entry.Name), ".zip") {
+			logger.Warn("Skipping nested archive %s in %s", entry.Name, archiveName)
+			continue
+		}
+
+		entryName := archiveName + "/" + entry.Name
+		extraction, err := extractZipEntry(ctx, entry, opts)
+		if errors.Is(err, ErrUnsupportedFormat) || errors.As(err, new(*EmptyFileError)) {
+			logger.Warn("Skipping %s in %s: %v", entry.Name, archiveName, err)
+			continue
+		}
+		if _, ok := AsCodedError(err); ok {
+			// Errors about the entry name it rather than its temporary copy
+			return nil, withFileName(err, entryName)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", entry.Name, err)
+		}
+		for i := range extraction.Emails {
+			extraction.Emails[i].Location.File = entryName
+		}
+		for _, file := range extraction.Files {
+			file.File = entryName
+			result.Files = append(result.Files, file)
+		}
+		for _, parseError := range extraction.ParseErrors {
+			parseError.File = entryName
+			result.ParseErrors = append(result.ParseErrors, parseError)
+		}
+		result.Emails = append(result.Emails, extraction.Emails...)
+	}
+
+	if len(result.Files) == 0 {
+		return nil, &UnsupportedFormatError{File: archiveName, Reason: "no supported files in the ZIP archive"}
+	}
+	logger.Debug("Extracted %d emails from %d files in %s", len(result.Emails), len(result.Files), archiveName)
+	return result, nil
+}
+
+// skipZipEntry reports whether an entry is a directory or metadata added by the archiver
+func skipZipEntry(entry *zip.File) bool {
+	if entry.FileInfo().IsDir() || strings.HasPrefix(entry.Name, "__MACOSX/") {
+		return true
+	}
+	return strings.HasPrefix(path.Base(entry.Name), ".")
+}
+
+// extractZipEntry copies an entry to a temporary file and extracts its emails like any input file
+func extractZipEntry(ctx context.Context, entry *zip.File, opts ExtractOptions) (*Extraction, error) {
+	source, err := entry.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer source.Close()
+
+	// Only the extension of the entry name is used, so names cannot escape the temp directory
+	temp, err := os.CreateTemp("", "zip-entry-*"+path.Ext(entry.Name))
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(temp.Name())
+
+	written, err := io.Copy(temp, io.LimitReader(source, maxZipEntrySize+1))
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	if written > maxZipEntrySize {
+		return nil, &TooLargeError{Subject: "ZIP entry " + entry.Name, Limit: maxZipEntrySize, Unit: "bytes"}
+	}
+
+	return ExtractEmails(ctx, temp.Name(), opts)
+}
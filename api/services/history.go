@@ -0,0 +1,536 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"ness-to-odoo-golang-validation-api-tool/config"
+	"ness-to-odoo-golang-validation-api-tool/store"
+	"ness-to-odoo-golang-validation-api-tool/utils"
+)
+
+// Buckets of the history database
+const (
+	runsBucket       = "runs"
+	runEntriesBucket = "run_entries"
+)
+
+// Run statuses
+const (
+	RunStatusCompleted = "completed"
+	RunStatusFailed    = "failed"
+	RunStatusCancelled = "cancelled"
+)
+
+// Categories of a stored run entry
+const (
+	CategoryMatching        = "Matching"
+	CategoryMissingInFirst  = "Missing in First File"
+	CategoryMissingInSecond = "Missing in Second File"
+	CategoryProbableMatch   = "Probable Match"
+	CategoryKeyMatch        = "Matched by Other Key (Email Changed)"
+)
+
+// runBuckets are the buckets that keep the records of a run under its ID
+var runBuckets = []string{runsBucket, runEntriesBucket, runDomainsBucket, runWebhooksBucket}
+
+// Limits on the number of runs returned by ListRuns
+const (
+	defaultRunListLimit = 50
+	maxRunListLimit     = 500
+)
+
+var (
+	// ErrHistoryDisabled is returned when the history database is not available
+	ErrHistoryDisabled = errors.New("validation history is disabled")
+	// ErrRunNotFound is returned when a run ID does not exist
+	ErrRunNotFound = errors.New("run not found")
+	// ErrRunIncomplete is returned when a run that failed or was cancelled is used as a comparison baseline
+	ErrRunIncomplete = errors.New("run did not complete")
+)
+
+// RunInput describes an input file of a validation run
+type RunInput struct {
+	Role     string `json:"role"`
+	FileName string `json:"fileName"`
+	SHA256   string `json:"sha256,omitempty"`
+	Size     int64  `json:"size"`
+	// Files describes how the input was read, with one file per entry of a ZIP archive
+	Files []InputFile `json:"files,omitempty"`
+}
+
+// Run is the stored record of a validation run
+type Run struct {
+	ID         string            `json:"id"`
+	Status     string            `json:"status"`
+	Error      string            `json:"error,omitempty"`
+	StartedAt  time.Time         `json:"startedAt"`
+	FinishedAt time.Time         `json:"finishedAt"`
+	Inputs     []RunInput        `json:"inputs"`
+	Options    ValidationOptions `json:"options"`
+	Summary    ValidationSummary `json:"summary"`
+	// Sources is the summary of an N-way comparison, whose inputs are the labelled sources
+	Sources    *MultiSourceSummary `json:"sources,omitempty"`
+	ReportFile string              `json:"reportFile,omitempty"`
+	ReportURL  string              `json:"reportURL,omitempty"`
+}
+
+// RunEntry is the stored result of a single email of a validation run
+type RunEntry struct {
+	Email           string `json:"email"`
+	DisplayName     string `json:"displayName,omitempty"`
+	NormalizedEmail string `json:"normalizedEmail"`
+	Source          string `json:"source"`
+	Category        string `json:"category"`
+	IsValid         bool   `json:"isValid"`
+	IsDisposable    bool   `json:"isDisposable"`
+	Reason          string `json:"reason,omitempty"`
+	// Location is where the email was read from; MatchedLocation is its location in the other file when it matched
+	Location        *SourceLocation `json:"location,omitempty"`
+	MatchedLocation *SourceLocation `json:"matchedLocation,omitempty"`
+	// MatchedEmail and Similarity are the other email of a probable match and how similar it is
+	MatchedEmail string  `json:"matchedEmail,omitempty"`
+	Similarity   float64 `json:"similarity,omitempty"`
+	// MatchedKey is the match key that paired the email with MatchedEmail when the email changed
+	MatchedKey string `json:"matchedKey,omitempty"`
+}
+
+// RunFilter selects runs in ListRuns. Zero values do not filter.
+type RunFilter struct {
+	From   time.Time
+	To     time.Time
+	Status string
+	// FileName matches runs with an input whose name contains it, case-insensitively
+	FileName string
+	// SHA256 matches runs with an input with this hash
+	SHA256 string
+	Limit  int
+}
+
+// OpenHistory opens the history database. Runs are recorded only after it has been opened.
+func OpenHistory(path string, timeout time.Duration) error {
+	if err := store.Init(path, timeout, runBuckets...); err != nil {
+		return err
+	}
+	utils.GetLogger().Info("Validation history stored in %s", path)
+	pruneHistory()
+	return nil
+}
+
+// PruneHistory deletes the runs that started more than maxAge ago and those beyond the maxRuns
+// most recent, along with their kept reports. Zero values do not prune. It returns the number
+// of runs deleted.
+func PruneHistory(maxAge time.Duration, maxRuns int) (int, error) {
+	db := store.Get()
+	if db == nil {
+		return 0, ErrHistoryDisabled
+	}
+	if maxAge <= 0 && maxRuns <= 0 {
+		return 0, nil
+	}
+
+	cutoff := time.Now().Add(-maxAge)
+	kept := 0
+	expired := make([]string, 0)
+	err := db.ForEachReverse(runsBucket, func(key string, data []byte) (bool, error) {
+		var run struct {
+			StartedAt time.Time `json:"startedAt"`
+		}
+		if err := json.Unmarshal(data, &run); err != nil {
+			return false, fmt.Errorf("failed to decode run %s: %w", key, err)
+		}
+		if (maxRuns > 0 && kept >= maxRuns) || (maxAge > 0 && run.StartedAt.Before(cutoff)) {
+			expired = append(expired, key)
+		} else {
+			kept++
+		}
+		return true, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	if len(expired) == 0 {
+		return 0, nil
+	}
+
+	if err := db.Delete(expired, runBuckets...); err != nil {
+		return 0, err
+	}
+	for _, id := range expired {
+		if err := os.RemoveAll(runReportDir(id)); err != nil {
+			utils.GetLogger().Warn("Failed to remove report of run %s: %v", id, err)
+		}
+	}
+	return len(expired), nil
+}
+
+// pruneHistory applies the retention configured with HISTORY_MAX_AGE and HISTORY_MAX_RUNS,
+// logging failures since the run that triggered it was recorded anyway
+func pruneHistory() {
+	cfg := config.Get()
+	logger := utils.GetLogger()
+	deleted, err := PruneHistory(cfg.HistoryMaxAge, cfg.HistoryMaxRuns)
+	if err != nil {
+		logger.Warn("Failed to prune validation history: %v", err)
+		return
+	}
+	if deleted > 0 {
+		logger.Info("Deleted %d run(s) past the history retention", deleted)
+	}
+}
+
+// CloseHistory closes the history database
+func CloseHistory() error {
+	return store.Close()
+}
+
+// HistoryPath returns the location of the history database in the data directory
+func HistoryPath() string {
+	return filepath.Join(config.Get().DataDir, "history.db")
+}
+
+// newRunID returns a unique run ID that sorts by start time
+func newRunID(startedAt time.Time) string {
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		// Fall back to the sub-second part of the start time
+		return fmt.Sprintf("%s-%08x", startedAt.UTC().Format("20060102T150405"), startedAt.Nanosecond())
+	}
+	return fmt.Sprintf("%s-%s", startedAt.UTC().Format("20060102T150405"), hex.EncodeToString(suffix))
+}
+
+// newRun creates the record of a run that is starting, with one input per file it reads
+func newRun(startedAt time.Time, opts ValidationOptions, inputs ...RunInput) *Run {
+	return &Run{
+		ID:        newRunID(startedAt),
+		StartedAt: startedAt,
+		Options:   opts,
+		Inputs:    inputs,
+	}
+}
+
+// setInputFiles attaches the files read for each input of the run, matched by role
+func (r *Run) setInputFiles(files []InputFile) {
+	for i := range r.Inputs {
+		for _, file := range files {
+			if file.Role == r.Inputs[i].Role {
+				file.Role = ""
+				r.Inputs[i].Files = append(r.Inputs[i].Files, file)
+			}
+		}
+	}
+}
+
+// saveCompletedRun stores a successful run with its per-entry results and domain breakdown, and
+// keeps a copy of the report. It reports whether the run was recorded.
+func saveCompletedRun(run *Run, inputPaths []string, summary ValidationSummary, reportPath string, entries []RunEntry, domains []DomainStats) bool {
+	run.Status = RunStatusCompleted
+	run.FinishedAt = time.Now()
+	run.Summary = summary
+	db := store.Get()
+	if db == nil {
+		return false
+	}
+	logger := utils.GetLogger()
+	hashInputs(run, inputPaths)
+
+	// Keep the report outside the temp directory so it can be downloaded later
+	reportCopy := filepath.Join(runReportDir(run.ID), filepath.Base(reportPath))
+	if err := copyFile(reportPath, reportCopy); err != nil {
+		logger.Warn("Failed to keep report of run %s: %v", run.ID, err)
+	} else {
+		run.ReportFile = filepath.Base(reportPath)
+		run.ReportURL = fmt.Sprintf("/api/v1/runs/%s/report", run.ID)
+	}
+
+	if err := db.Put(
+		store.Record{Bucket: runsBucket, Key: run.ID, Value: run},
+		store.Record{Bucket: runEntriesBucket, Key: run.ID, Value: entries},
+		store.Record{Bucket: runDomainsBucket, Key: run.ID, Value: domains},
+	); err != nil {
+		logger.Error("Failed to record run %s: %v", run.ID, err)
+		return false
+	}
+	logger.Info("Recorded run %s with %d entries", run.ID, len(entries))
+	pruneHistory()
+	return true
+}
+
+// saveFailedRun stores a run that failed or was cancelled
+func saveFailedRun(run *Run, inputPaths []string, runErr error, cancelled bool) {
+	run.Status = RunStatusFailed
+	if cancelled {
+		run.Status = RunStatusCancelled
+	}
+	run.Error = runErr.Error()
+	run.FinishedAt = time.Now()
+	db := store.Get()
+	if db == nil {
+		return
+	}
+	hashInputs(run, inputPaths)
+
+	if err := db.Put(store.Record{Bucket: runsBucket, Key: run.ID, Value: run}); err != nil {
+		utils.GetLogger().Error("Failed to record run %s: %v", run.ID, err)
+		return
+	}
+	pruneHistory()
+}
+
+// runEntries converts the comparison results to run entries. Both emails of a key or probable
+// match are recorded, each with the other as its matched email.
+func runEntries(matching, missingInFirst, missingInSecond []EmailEntry, keyMatches []KeyMatch, probable []ProbableMatch) []RunEntry {
+	entries := make([]RunEntry, 0, len(matching)+len(missingInFirst)+len(missingInSecond)+2*len(keyMatches)+2*len(probable))
+	entries = appendRunEntries(entries, matching, CategoryMatching)
+	entries = appendRunEntries(entries, missingInFirst, CategoryMissingInFirst)
+	entries = appendRunEntries(entries, missingInSecond, CategoryMissingInSecond)
+	changed := make([]EmailEntry, 0, 2*len(keyMatches))
+	for _, match := range keyMatches {
+		reversed := KeyMatch{First: match.Second, Second: match.First, Key: match.Key}
+		changed = append(changed, match.entry(), reversed.entry())
+	}
+	entries = appendRunEntries(entries, changed, CategoryKeyMatch)
+	pairs := make([]EmailEntry, 0, 2*len(probable))
+	for _, match := range probable {
+		reversed := ProbableMatch{First: match.Second, Second: match.First, Similarity: match.Similarity}
+		pairs = append(pairs, match.entry(), reversed.entry())
+	}
+	return appendRunEntries(entries, pairs, CategoryProbableMatch)
+}
+
+// appendRunEntries converts email entries of a category to run entries
+func appendRunEntries(runEntries []RunEntry, entries []EmailEntry, category string) []RunEntry {
+	for _, entry := range entries {
+		location := entry.Location
+		runEntries = append(runEntries, RunEntry{
+			Email:           entry.Email,
+			DisplayName:     entry.DisplayName,
+			NormalizedEmail: entry.NormalizedEmail,
+			Source:          entry.Source,
+			Category:        category,
+			IsValid:         entry.IsValid,
+			IsDisposable:    entry.IsDisposable,
+			Reason:          entry.Reason,
+			Location:        &location,
+			MatchedLocation: entry.MatchedLocation,
+			MatchedEmail:    entry.MatchedEmail,
+			Similarity:      entry.Similarity,
+			MatchedKey:      entry.MatchedKey,
+		})
+	}
+	return runEntries
+}
+
+// hashInputs fills in the size and SHA-256 hash of the run inputs that still exist
+func hashInputs(run *Run, paths []string) {
+	for i, path := range paths {
+		if i >= len(run.Inputs) {
+			break
+		}
+		hash, size, err := hashFile(path)
+		if err != nil {
+			utils.GetLogger().Warn("Failed to hash input %s of run %s: %v", path, run.ID, err)
+			continue
+		}
+		run.Inputs[i].SHA256 = hash
+		run.Inputs[i].Size = size
+	}
+}
+
+// hashFile returns the hex-encoded SHA-256 hash and the size of a file
+func hashFile(path string) (string, int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer file.Close()
+
+	hasher := sha256.New()
+	size, err := io.Copy(hasher, file)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), size, nil
+}
+
+// copyFile copies src to dst, creating the destination directory
+func copyFile(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), os.ModePerm); err != nil {
+		return err
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	return out.Close()
+}
+
+// runReportDir returns the directory that keeps the report of a run
+func runReportDir(runID string) string {
+	return filepath.Join(config.Get().DataDir, "reports", runID)
+}
+
+// ListRuns returns the stored runs matching filter, newest first
+func ListRuns(filter RunFilter) ([]Run, error) {
+	db := store.Get()
+	if db == nil {
+		return nil, ErrHistoryDisabled
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultRunListLimit
+	}
+	if limit > maxRunListLimit {
+		limit = maxRunListLimit
+	}
+
+	runs := make([]Run, 0)
+	err := db.ForEachReverse(runsBucket, func(key string, data []byte) (bool, error) {
+		var run Run
+		if err := json.Unmarshal(data, &run); err != nil {
+			return false, fmt.Errorf("failed to decode run %s: %w", key, err)
+		}
+		// Keys order runs to the second, so once a run starts in an earlier second than From,
+		// no later key can match
+		if !filter.From.IsZero() && run.StartedAt.Truncate(time.Second).Before(filter.From.Truncate(time.Second)) {
+			return false, nil
+		}
+		if filter.matches(run) {
+			runs = append(runs, run)
+		}
+		return len(runs) < limit, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
+
+// matches reports whether run satisfies the filter
+func (f RunFilter) matches(run Run) bool {
+	if !f.From.IsZero() && run.StartedAt.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && run.StartedAt.After(f.To) {
+		return false
+	}
+	if f.Status != "" && run.Status != f.Status {
+		return false
+	}
+	if f.FileName == "" && f.SHA256 == "" {
+		return true
+	}
+
+	for _, input := range run.Inputs {
+		nameMatches := f.FileName == "" || strings.Contains(strings.ToLower(input.FileName), strings.ToLower(f.FileName))
+		hashMatches := f.SHA256 == "" || strings.EqualFold(input.SHA256, f.SHA256)
+		if nameMatches && hashMatches {
+			return true
+		}
+	}
+	return false
+}
+
+// GetRun returns the stored run with the given ID
+func GetRun(id string) (*Run, error) {
+	db := store.Get()
+	if db == nil {
+		return nil, ErrHistoryDisabled
+	}
+
+	var run Run
+	if err := db.Load(runsBucket, id, &run); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, ErrRunNotFound
+		}
+		return nil, err
+	}
+	return &run, nil
+}
+
+// GetRunEntries returns the per-entry results of a stored run. Runs that did not complete have none.
+func GetRunEntries(id string) ([]RunEntry, error) {
+	db := store.Get()
+	if db == nil {
+		return nil, ErrHistoryDisabled
+	}
+
+	entries := make([]RunEntry, 0)
+	if err := db.Load(runEntriesBucket, id, &entries); err != nil && !errors.Is(err, store.ErrNotFound) {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// PreviousRunID returns the ID of the most recent completed run of the same kind started before
+// the given run; see comparableRuns
+func PreviousRunID(id string) (string, error) {
+	current, err := GetRun(id)
+	if err != nil {
+		return "", err
+	}
+	db := store.Get()
+
+	var previous *Run
+	err = db.ForEachReverse(runsBucket, func(key string, data []byte) (bool, error) {
+		var run Run
+		if err := json.Unmarshal(data, &run); err != nil {
+			return false, fmt.Errorf("failed to decode run %s: %w", key, err)
+		}
+		// Keys only order runs to the second, so keep looking until an earlier second is reached
+		if previous != nil && run.StartedAt.Truncate(time.Second).Before(previous.StartedAt.Truncate(time.Second)) {
+			return false, nil
+		}
+		if key == id || run.Status != RunStatusCompleted || !run.StartedAt.Before(current.StartedAt) || !comparableRuns(current, &run) {
+			return true, nil
+		}
+		if previous == nil || run.StartedAt.After(previous.StartedAt) {
+			previous = &run
+		}
+		return true, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	if previous == nil {
+		return "", fmt.Errorf("no completed run of the same kind before %s: %w", id, ErrRunNotFound)
+	}
+	return previous.ID, nil
+}
+
+// comparableRuns reports whether the entries of two runs can be compared by default: both are
+// two-file validations or both N-way comparisons, with the same comparison strategy. Their
+// categories and normalized emails mean something else otherwise.
+func comparableRuns(a, b *Run) bool {
+	return (a.Sources == nil) == (b.Sources == nil) && a.Options.Comparison.orDefault() == b.Options.Comparison.orDefault()
+}
+
+// RunReportPath returns the location of the kept report of a run
+func RunReportPath(run *Run) (string, error) {
+	if run.ReportFile == "" {
+		return "", fmt.Errorf("run %s has no report", run.ID)
+	}
+	return filepath.Join(runReportDir(run.ID), run.ReportFile), nil
+}
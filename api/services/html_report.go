@@ -0,0 +1,178 @@
+package services
+
+import (
+	"context"
+	_ "embed"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+)
+
+//go:embed templates/report.html
+var htmlReportTemplateText string
+
+// htmlReportTemplate renders the self-contained HTML report. The tables are embedded as JSON
+// and rendered by the inline script, which handles search, sorting and pagination.
+var htmlReportTemplate = template.Must(template.New("report").Parse(htmlReportTemplateText))
+
+// htmlEntryColumns are the columns of the entry tables of the HTML report
+var htmlEntryColumns = []string{
+	"Email",
+	"Display Name",
+	"Normalized Email",
+	"Source",
+	"Valid",
+	"Disposable",
+	"Reason",
+	"First File Location",
+	"Second File Location",
+	"Matched Email",
+	"Similarity",
+	"Matched Key",
+}
+
+// htmlReportPage is the data of the HTML report template
+type htmlReportPage struct {
+	GeneratedAt string
+	// Comparison is the comparison strategy of the run
+	Comparison string
+	Cards      []htmlSummaryCard
+	Tables     []htmlTable
+}
+
+// htmlSummaryCard is a headline number of the HTML report
+type htmlSummaryCard struct {
+	Label string
+	Value int
+	// Kind selects the card color: "ok", "warn" or "" for neutral
+	Kind string
+}
+
+// htmlTable is a table of the HTML report. Rows hold strings and numbers, which sort numerically.
+type htmlTable struct {
+	ID      string          `json:"id"`
+	Title   string          `json:"title"`
+	Columns []string        `json:"columns"`
+	Rows    [][]interface{} `json:"rows"`
+}
+
+// writeHTMLReport writes a single-file HTML report with summary cards, one table per category
+// and a domain breakdown
+func writeHTMLReport(ctx context.Context, w io.Writer, report *ReportData) error {
+	summary := report.Summary
+	page := htmlReportPage{
+		GeneratedAt: time.Now().Format("2006-01-02 15:04:05"),
+		Comparison:  string(summary.ComparisonStrategy),
+		Cards: []htmlSummaryCard{
+			{Label: "Emails in First File", Value: summary.TotalEmailsFirstFile},
+			{Label: "Emails in Second File", Value: summary.TotalEmailsSecondFile},
+			{Label: "Matching", Value: summary.MatchingCount, Kind: "ok"},
+			{Label: "Missing in First File", Value: summary.MissingInFirstCount, Kind: "warn"},
+			{Label: "Missing in Second File", Value: summary.MissingInSecondCount, Kind: "warn"},
+			{Label: "Matched by Other Key", Value: summary.KeyMatchCount},
+			{Label: "Probable Matches", Value: summary.ProbableMatchCount},
+			{Label: "Invalid", Value: invalidCount(summary), Kind: "warn"},
+			{Label: "Disposable", Value: summary.DisposableEmailsCount},
+		},
+	}
+
+	var invalid []EmailEntry
+	for _, entries := range [][]EmailEntry{report.FirstEntries, report.SecondEntries} {
+		for i, entry := range entries {
+			if err := checkCancelled(ctx, i); err != nil {
+				return err
+			}
+			if !entry.IsValid {
+				invalid = append(invalid, entry)
+			}
+		}
+	}
+
+	categories := []struct {
+		id      string
+		title   string
+		entries []EmailEntry
+	}{
+		{"matching", CategoryMatching, report.Matching},
+		{"missing-first", CategoryMissingInFirst, report.MissingInFirst},
+		{"missing-second", CategoryMissingInSecond, report.MissingInSecond},
+		{"changed", CategoryKeyMatch, keyMatchEntries(report.KeyMatches)},
+		{"probable", CategoryProbableMatch, probableEntries(report.ProbableMatches)},
+		{"invalid", "Invalid", invalid},
+	}
+	for _, category := range categories {
+		rows := make([][]interface{}, len(category.entries))
+		for i, entry := range category.entries {
+			if err := checkCancelled(ctx, i); err != nil {
+				return err
+			}
+			first, second := entryLocations(entry)
+			rows[i] = []interface{}{
+				entry.Email,
+				entry.DisplayName,
+				entry.NormalizedEmail,
+				entry.Source,
+				fmtBool(entry.IsValid),
+				fmtBool(entry.IsDisposable),
+				entry.Reason,
+				first,
+				second,
+				entry.MatchedEmail,
+				htmlSimilarity(entry),
+				entry.MatchedKey,
+			}
+		}
+		page.Tables = append(page.Tables, htmlTable{ID: category.id, Title: category.title, Columns: htmlEntryColumns, Rows: rows})
+	}
+
+	domains := htmlTable{ID: "domains", Title: "Domains", Columns: domainStatsColumns, Rows: make([][]interface{}, len(report.Domains))}
+	for i, domain := range report.Domains {
+		domains.Rows[i] = domain.row()
+	}
+	page.Tables = append(page.Tables, domains)
+
+	if len(report.ParseErrors) > 0 {
+		page.Cards = append(page.Cards, htmlSummaryCard{Label: "Parse Errors", Value: len(report.ParseErrors), Kind: "warn"})
+		page.Tables = append(page.Tables, parseErrorsTable(report.ParseErrors))
+	}
+
+	return htmlReportTemplate.Execute(w, page)
+}
+
+// htmlSimilarity returns the similarity of a probable match, or an empty cell for other entries
+func htmlSimilarity(entry EmailEntry) interface{} {
+	if entry.Similarity == 0 {
+		return ""
+	}
+	return entry.Similarity
+}
+
+// parseErrorsTable lists the malformed rows skipped by lenient parsing
+func parseErrorsTable(parseErrors []ParseError) htmlTable {
+	table := htmlTable{
+		ID:      "parse-errors",
+		Title:   "Parse Errors",
+		Columns: []string{"Input", "File", "Line", "Error"},
+		Rows:    make([][]interface{}, len(parseErrors)),
+	}
+	for i, parseError := range parseErrors {
+		table.Rows[i] = []interface{}{parseError.Role, parseError.File, parseError.Line, parseError.Message}
+	}
+	return table
+}
+
+// emailDomain returns the part of an email after the last "@", or "(none)" when there is none
+func emailDomain(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at < 0 || at == len(email)-1 {
+		return "(none)"
+	}
+	return email[at+1:]
+}
+
+// invalidCount returns the number of invalid emails across both files
+func invalidCount(summary ValidationSummary) int {
+	return summary.TotalEmailsFirstFile - summary.ValidEmailsFirstFile +
+		summary.TotalEmailsSecondFile - summary.ValidEmailsSecondFile
+}
@@ -0,0 +1,38 @@
+package services
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// activeJobs counts the validation runs currently in progress
+var activeJobs atomic.Int64
+
+// trackJob registers a running validation job and returns the function that unregisters it
+func trackJob() func() {
+	activeJobs.Add(1)
+	return func() {
+		activeJobs.Add(-1)
+	}
+}
+
+// ActiveJobs returns the number of validation runs currently in progress
+func ActiveJobs() int64 {
+	return activeJobs.Load()
+}
+
+// WaitForJobs blocks until no validation runs are in progress or ctx is done
+func WaitForJobs(ctx context.Context) error {
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	for ActiveJobs() > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
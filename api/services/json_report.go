@@ -0,0 +1,95 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+)
+
+// jsonReport is the document written by the JSON reporter
+type jsonReport struct {
+	Summary             ValidationSummary `json:"summary"`
+	Inputs              []InputFile       `json:"inputs,omitempty"`
+	ParseErrors         []ParseError      `json:"parseErrors,omitempty"`
+	Matching            []EmailEntry      `json:"matching"`
+	MissingInFirstFile  []EmailEntry      `json:"missingInFirstFile"`
+	MissingInSecondFile []EmailEntry      `json:"missingInSecondFile"`
+	KeyMatches          []KeyMatch        `json:"keyMatches"`
+	ProbableMatches     []ProbableMatch   `json:"probableMatches"`
+	Domains             []DomainStats     `json:"domains"`
+}
+
+// ndjsonEntry is a line of the NDJSON report
+type ndjsonEntry struct {
+	Category string `json:"category"`
+	EmailEntry
+}
+
+// writeJSONReport writes the summary and the entries of every category as one indented JSON document
+func writeJSONReport(ctx context.Context, w io.Writer, report *ReportData) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	document := jsonReport{
+		Summary:             report.Summary,
+		Inputs:              report.Inputs,
+		ParseErrors:         report.ParseErrors,
+		Matching:            nonNilEntries(report.Matching),
+		MissingInFirstFile:  nonNilEntries(report.MissingInFirst),
+		MissingInSecondFile: nonNilEntries(report.MissingInSecond),
+		KeyMatches:          report.KeyMatches,
+		ProbableMatches:     report.ProbableMatches,
+		Domains:             report.Domains,
+	}
+	if document.Domains == nil {
+		document.Domains = []DomainStats{}
+	}
+	if document.KeyMatches == nil {
+		document.KeyMatches = []KeyMatch{}
+	}
+	if document.ProbableMatches == nil {
+		document.ProbableMatches = []ProbableMatch{}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(document)
+}
+
+// writeNDJSONReport writes one JSON object per entry and line, with its category, so the
+// report can be streamed into other tools
+func writeNDJSONReport(ctx context.Context, w io.Writer, report *ReportData) error {
+	buffered := bufio.NewWriter(w)
+	encoder := json.NewEncoder(buffered)
+
+	categories := []struct {
+		name    string
+		entries []EmailEntry
+	}{
+		{CategoryMatching, report.Matching},
+		{CategoryMissingInFirst, report.MissingInFirst},
+		{CategoryMissingInSecond, report.MissingInSecond},
+		{CategoryKeyMatch, keyMatchEntries(report.KeyMatches)},
+		{CategoryProbableMatch, probableEntries(report.ProbableMatches)},
+	}
+	for _, category := range categories {
+		for i, entry := range category.entries {
+			if err := checkCancelled(ctx, i); err != nil {
+				return err
+			}
+			if err := encoder.Encode(ndjsonEntry{Category: category.name, EmailEntry: entry}); err != nil {
+				return err
+			}
+		}
+	}
+	return buffered.Flush()
+}
+
+// nonNilEntries returns entries, or an empty slice so that it is encoded as [] rather than null
+func nonNilEntries(entries []EmailEntry) []EmailEntry {
+	if entries == nil {
+		return []EmailEntry{}
+	}
+	return entries
+}
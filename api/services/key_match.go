@@ -0,0 +1,222 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"ness-to-odoo-golang-validation-api-tool/utils"
+)
+
+// MatchKey is a secondary key that identifies the same record in both files when its email
+// changed, such as the full name and phone, or a customer code
+type MatchKey struct {
+	// Name is the key as given, e.g. "Full Name+Phone"
+	Name string `json:"name"`
+	// FirstColumns and SecondColumns are the columns of the key in each file, in the same order
+	FirstColumns  []string `json:"firstColumns"`
+	SecondColumns []string `json:"secondColumns"`
+}
+
+// KeyMatch pairs an email missing in the second file with an email missing in the first file
+// whose record has the same match key values
+type KeyMatch struct {
+	First  EmailEntry `json:"first"`
+	Second EmailEntry `json:"second"`
+	// Key is the name of the match key that paired them
+	Key string `json:"key"`
+}
+
+// entry returns the first-file email of the match, with the email and location of the
+// second-file email, as listed in the reports
+func (m KeyMatch) entry() EmailEntry {
+	entry := m.First
+	secondLocation := m.Second.Location
+	entry.MatchedLocation = &secondLocation
+	entry.MatchedEmail = m.Second.Email
+	entry.MatchedKey = m.Key
+	return entry
+}
+
+// ParseMatchKeys parses match keys separated by ";". A key joins one or more columns with "+",
+// e.g. "Full Name+Phone;Customer Code". A column named differently in the second file is given
+// as "first=second", e.g. "Customer Code=ref".
+func ParseMatchKeys(spec string) ([]MatchKey, error) {
+	var keys []MatchKey
+	for _, part := range strings.Split(spec, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key := MatchKey{Name: part}
+		for _, column := range strings.Split(part, "+") {
+			first, second, mapped := strings.Cut(column, "=")
+			first, second = strings.TrimSpace(first), strings.TrimSpace(second)
+			if !mapped {
+				second = first
+			}
+			if first == "" || second == "" {
+				return nil, fmt.Errorf("invalid match key %q: empty column name", part)
+			}
+			key.FirstColumns = append(key.FirstColumns, first)
+			key.SecondColumns = append(key.SecondColumns, second)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// matchKeyColumns adds the columns of the match keys to fields, skipping those already there
+func matchKeyColumns(fields []string, keys []MatchKey, second bool) []string {
+	result := append([]string(nil), fields...)
+	for _, key := range keys {
+		columns := key.FirstColumns
+		if second {
+			columns = key.SecondColumns
+		}
+		for _, column := range columns {
+			found := false
+			for _, field := range result {
+				if field == column {
+					found = true
+					break
+				}
+			}
+			if !found {
+				result = append(result, column)
+			}
+		}
+	}
+	return result
+}
+
+// keyMatchEntries returns the report entries of key matches
+func keyMatchEntries(matches []KeyMatch) []EmailEntry {
+	entries := make([]EmailEntry, len(matches))
+	for i, match := range matches {
+		entries[i] = match.entry()
+	}
+	return entries
+}
+
+// findKeyMatches pairs the emails of missingInSecond (read from the first file) with those of
+// missingInFirst (read from the second file) whose records have the same values for a match
+// key. Emails are told apart by the key of the comparison strategy, so with the domain
+// strategies whole domains are paired. Keys are tried in order. A key value shared by several
+// emails on either side is ambiguous and pairs nothing. The paired emails are removed from the
+// missing lists.
+func findKeyMatches(ctx context.Context, missingInFirst, missingInSecond []EmailEntry, keys []MatchKey, strategy ComparisonStrategy) (matches []KeyMatch, remainingInFirst, remainingInSecond []EmailEntry, err error) {
+	matches = make([]KeyMatch, 0)
+	if len(keys) == 0 || len(missingInFirst) == 0 || len(missingInSecond) == 0 {
+		return matches, missingInFirst, missingInSecond, nil
+	}
+	logger := utils.GetLogger()
+	defer utils.LogExecutionTime("findKeyMatches")()
+
+	pairedFirst := make(map[string]bool)
+	pairedSecond := make(map[string]bool)
+	ambiguous := 0
+	for _, key := range keys {
+		firstValues, err := keyValues(ctx, missingInSecond, key.FirstColumns, pairedFirst, strategy)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		secondValues, err := keyValues(ctx, missingInFirst, key.SecondColumns, pairedSecond, strategy)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		// Values are visited in order so that an email with several values is paired the same way every run
+		values := make([]string, 0, len(firstValues))
+		for value := range firstValues {
+			if _, ok := secondValues[value]; ok {
+				values = append(values, value)
+			}
+		}
+		sort.Strings(values)
+		for _, value := range values {
+			firstEmails, secondEmails := firstValues[value], secondValues[value]
+			if len(firstEmails) > 1 || len(secondEmails) > 1 {
+				ambiguous++
+				continue
+			}
+			first, second := onlyEntry(firstEmails), onlyEntry(secondEmails)
+			if pairedFirst[strategy.key(first)] || pairedSecond[strategy.key(second)] {
+				continue
+			}
+			pairedFirst[strategy.key(first)] = true
+			pairedSecond[strategy.key(second)] = true
+			matches = append(matches, KeyMatch{First: first, Second: second, Key: key.Name})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].First.NormalizedEmail < matches[j].First.NormalizedEmail
+	})
+
+	remainingInFirst = withoutEmails(missingInFirst, pairedSecond, strategy)
+	remainingInSecond = withoutEmails(missingInSecond, pairedFirst, strategy)
+
+	logger.Info("Key matching paired %d emails on %d keys (%d ambiguous key values skipped)",
+		len(matches), len(keys), ambiguous)
+	return matches, remainingInFirst, remainingInSecond, nil
+}
+
+// keyValues groups the entries not yet paired by the value of their key columns, and within a
+// value by the key of the comparison strategy. Entries with an empty key column are left out.
+func keyValues(ctx context.Context, entries []EmailEntry, columns []string, paired map[string]bool, strategy ComparisonStrategy) (map[string]map[string]EmailEntry, error) {
+	values := make(map[string]map[string]EmailEntry)
+	for i, entry := range entries {
+		if err := checkCancelled(ctx, i); err != nil {
+			return nil, err
+		}
+		if paired[strategy.key(entry)] {
+			continue
+		}
+		value, ok := keyValue(entry.Fields, columns)
+		if !ok {
+			continue
+		}
+		if values[value] == nil {
+			values[value] = make(map[string]EmailEntry)
+		}
+		if _, seen := values[value][strategy.key(entry)]; !seen {
+			values[value][strategy.key(entry)] = entry
+		}
+	}
+	return values, nil
+}
+
+// keyValue joins the normalized values of the key columns of a record, and reports false when
+// one of them is empty
+func keyValue(fields map[string]string, columns []string) (string, bool) {
+	parts := make([]string, len(columns))
+	for i, column := range columns {
+		parts[i] = normalizeKeyValue(fields[column])
+		if parts[i] == "" {
+			return "", false
+		}
+	}
+	return strings.Join(parts, "\x00"), true
+}
+
+// normalizeKeyValue lowercases a value and keeps only its letters and digits, so
+// "Nguyen  Van A" matches "nguyen van a" and "090-123 4567" matches "0901234567"
+func normalizeKeyValue(value string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(value) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// onlyEntry returns the single entry of a map holding one entry
+func onlyEntry(entries map[string]EmailEntry) EmailEntry {
+	for _, entry := range entries {
+		return entry
+	}
+	return EmailEntry{}
+}
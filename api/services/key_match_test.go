@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFindKeyMatchesStrategy(t *testing.T) {
+	entry := func(email, name string) EmailEntry {
+		return EmailEntry{Email: email, NormalizedEmail: strings.ToLower(email), IsValid: true, Fields: map[string]string{"Name": name}}
+	}
+	keys := []MatchKey{{Name: "Name", FirstColumns: []string{"Name"}, SecondColumns: []string{"Name"}}}
+
+	tests := []struct {
+		name            string
+		strategy        ComparisonStrategy
+		missingInSecond []EmailEntry
+		missingInFirst  []EmailEntry
+		wantPairs       map[string]string
+		wantInSecond    []string
+	}{
+		{
+			name:            "normalized pairs every spelling of an email",
+			strategy:        ComparisonNormalized,
+			missingInSecond: []EmailEntry{entry("Ann@x.com", "Ann"), entry("ann@x.com", "Ann")},
+			missingInFirst:  []EmailEntry{entry("ann.new@x.com", "Ann")},
+			wantPairs:       map[string]string{"Ann@x.com": "ann.new@x.com"},
+			wantInSecond:    []string{},
+		},
+		{
+			name:            "raw keeps other spellings apart",
+			strategy:        ComparisonRaw,
+			missingInSecond: []EmailEntry{entry("Ann@x.com", "Ann"), entry("ann@x.com", "Bo")},
+			missingInFirst:  []EmailEntry{entry("ann.new@x.com", "Ann")},
+			wantPairs:       map[string]string{"Ann@x.com": "ann.new@x.com"},
+			wantInSecond:    []string{"ann@x.com"},
+		},
+		{
+			name:            "raw spellings sharing a key value are ambiguous",
+			strategy:        ComparisonRaw,
+			missingInSecond: []EmailEntry{entry("Ann@x.com", "Ann"), entry("ann@x.com", "Ann")},
+			missingInFirst:  []EmailEntry{entry("ann.new@x.com", "Ann")},
+			wantPairs:       map[string]string{},
+			wantInSecond:    []string{"Ann@x.com", "ann@x.com"},
+		},
+		{
+			name:            "domain pairs a company whose domain changed",
+			strategy:        ComparisonDomain,
+			missingInSecond: []EmailEntry{entry("sales@old.com", "Acme")},
+			missingInFirst:  []EmailEntry{entry("sales@new.com", "Acme")},
+			wantPairs:       map[string]string{"sales@old.com": "sales@new.com"},
+			wantInSecond:    []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches, _, inSecond, err := findKeyMatches(context.Background(), tt.missingInFirst, tt.missingInSecond, keys, tt.strategy)
+			if err != nil {
+				t.Fatalf("findKeyMatches() error = %v", err)
+			}
+			pairs := make(map[string]string, len(matches))
+			for _, match := range matches {
+				pairs[match.First.Email] = match.Second.Email
+			}
+			if !reflect.DeepEqual(pairs, tt.wantPairs) {
+				t.Errorf("pairs = %v, want %v", pairs, tt.wantPairs)
+			}
+			remaining := make([]string, len(inSecond))
+			for i, entry := range inSecond {
+				remaining[i] = entry.Email
+			}
+			if !reflect.DeepEqual(remaining, tt.wantInSecond) {
+				t.Errorf("remaining missing in second = %v, want %v", remaining, tt.wantInSecond)
+			}
+		})
+	}
+}
@@ -0,0 +1,190 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// markdownMaxRows is the number of entries listed per category; the Markdown report is meant
+// to be pasted into tickets, so the full lists are left to the other formats
+const markdownMaxRows = 100
+
+// writeMarkdownReport writes the summary and the first entries of every category as Markdown tables
+func writeMarkdownReport(ctx context.Context, w io.Writer, report *ReportData) error {
+	buffered := bufio.NewWriter(w)
+	summary := report.Summary
+
+	fmt.Fprintln(buffered, "# Email Validation Report")
+	fmt.Fprintln(buffered)
+	fmt.Fprintln(buffered, "## Summary")
+	fmt.Fprintln(buffered)
+	fmt.Fprintln(buffered, "| Metric | Value |")
+	fmt.Fprintln(buffered, "| --- | ---: |")
+	summaryData := []struct {
+		label string
+		value interface{}
+	}{
+		{"Total Emails in First File", summary.TotalEmailsFirstFile},
+		{"Total Emails in Second File", summary.TotalEmailsSecondFile},
+		{"Valid Emails in First File", summary.ValidEmailsFirstFile},
+		{"Valid Emails in Second File", summary.ValidEmailsSecondFile},
+		{"Matching Emails", summary.MatchingCount},
+		{"Emails Missing in First File", summary.MissingInFirstCount},
+		{"Emails Missing in Second File", summary.MissingInSecondCount},
+		{"Comparison Strategy", summary.ComparisonStrategy},
+		{"Matched by Other Key", summary.KeyMatchCount},
+		{"Probable Matches", summary.ProbableMatchCount},
+		{"Probable Match Threshold", fmtSimilarity(summary.ProbableMatchThreshold)},
+		{"Disposable Emails", summary.DisposableEmailsCount},
+		{"Skipped Rows in First File", summary.SkippedRowsFirstFile},
+		{"Skipped Rows in Second File", summary.SkippedRowsSecondFile},
+		{"Parse Errors in First File", summary.ParseErrorsFirstFile},
+		{"Parse Errors in Second File", summary.ParseErrorsSecondFile},
+		{"Processing Time", fmt.Sprintf("%.2f s", summary.ProcessingTimeSeconds)},
+	}
+	for _, row := range summaryData {
+		fmt.Fprintf(buffered, "| %s | %v |\n", row.label, row.value)
+	}
+
+	if len(report.Inputs) > 0 {
+		fmt.Fprintln(buffered)
+		fmt.Fprintln(buffered, "## Input Files")
+		fmt.Fprintln(buffered)
+		fmt.Fprintln(buffered, "| Role | File | Format | Dialect |")
+		fmt.Fprintln(buffered, "| --- | --- | --- | --- |")
+		for _, input := range report.Inputs {
+			dialect := ""
+			if input.Dialect != nil {
+				dialect = input.Dialect.String()
+			}
+			fmt.Fprintf(buffered, "| %s | %s | %s | %s |\n",
+				markdownEscape(input.Role),
+				markdownEscape(input.File),
+				markdownEscape(input.Format),
+				markdownEscape(dialect))
+		}
+	}
+
+	if len(report.ParseErrors) > 0 {
+		fmt.Fprintln(buffered)
+		fmt.Fprintf(buffered, "## Parse Errors (%d)\n", len(report.ParseErrors))
+		fmt.Fprintln(buffered)
+		fmt.Fprintln(buffered, "| Input | File | Line | Error |")
+		fmt.Fprintln(buffered, "| --- | --- | ---: | --- |")
+		for i, parseError := range report.ParseErrors {
+			if i == markdownMaxRows {
+				fmt.Fprintln(buffered)
+				fmt.Fprintf(buffered, "_%d more not shown._\n", len(report.ParseErrors)-markdownMaxRows)
+				break
+			}
+			fmt.Fprintf(buffered, "| %s | %s | %d | %s |\n",
+				markdownEscape(parseError.Role),
+				markdownEscape(parseError.File),
+				parseError.Line,
+				markdownEscape(parseError.Message))
+		}
+	}
+
+	categories := []struct {
+		name    string
+		entries []EmailEntry
+	}{
+		{CategoryMatching, report.Matching},
+		{CategoryMissingInFirst, report.MissingInFirst},
+		{CategoryMissingInSecond, report.MissingInSecond},
+	}
+	for _, category := range categories {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		fmt.Fprintln(buffered)
+		fmt.Fprintf(buffered, "## %s (%d)\n", category.name, len(category.entries))
+		fmt.Fprintln(buffered)
+		if len(category.entries) == 0 {
+			fmt.Fprintln(buffered, "None.")
+			continue
+		}
+
+		fmt.Fprintln(buffered, "| Email | Display Name | Valid | Reason | First File Location | Second File Location |")
+		fmt.Fprintln(buffered, "| --- | --- | --- | --- | --- | --- |")
+		for i, entry := range category.entries {
+			if i == markdownMaxRows {
+				fmt.Fprintln(buffered)
+				fmt.Fprintf(buffered, "_%d more not shown._\n", len(category.entries)-markdownMaxRows)
+				break
+			}
+			first, second := entryLocations(entry)
+			fmt.Fprintf(buffered, "| %s | %s | %s | %s | %s | %s |\n",
+				markdownEscape(entry.Email),
+				markdownEscape(entry.DisplayName),
+				fmtBool(entry.IsValid),
+				markdownEscape(entry.Reason),
+				markdownEscape(first),
+				markdownEscape(second))
+		}
+	}
+
+	if len(report.KeyMatches) > 0 {
+		fmt.Fprintln(buffered)
+		fmt.Fprintf(buffered, "## %s (%d)\n", CategoryKeyMatch, len(report.KeyMatches))
+		fmt.Fprintln(buffered)
+		fmt.Fprintln(buffered, "| First File Email | Second File Email | Matched Key | First File Location | Second File Location |")
+		fmt.Fprintln(buffered, "| --- | --- | --- | --- | --- |")
+		for i, match := range report.KeyMatches {
+			if i == markdownMaxRows {
+				fmt.Fprintln(buffered)
+				fmt.Fprintf(buffered, "_%d more not shown._\n", len(report.KeyMatches)-markdownMaxRows)
+				break
+			}
+			fmt.Fprintf(buffered, "| %s | %s | %s | %s | %s |\n",
+				markdownEscape(match.First.Email),
+				markdownEscape(match.Second.Email),
+				markdownEscape(match.Key),
+				markdownEscape(match.First.Location.String()),
+				markdownEscape(match.Second.Location.String()))
+		}
+	}
+
+	if len(report.ProbableMatches) > 0 {
+		fmt.Fprintln(buffered)
+		fmt.Fprintf(buffered, "## %s (%d)\n", CategoryProbableMatch, len(report.ProbableMatches))
+		fmt.Fprintln(buffered)
+		fmt.Fprintln(buffered, "| Email | Matched Email | Similarity | First File Location | Second File Location |")
+		fmt.Fprintln(buffered, "| --- | --- | ---: | --- | --- |")
+		for i, match := range report.ProbableMatches {
+			if i == markdownMaxRows {
+				fmt.Fprintln(buffered)
+				fmt.Fprintf(buffered, "_%d more not shown._\n", len(report.ProbableMatches)-markdownMaxRows)
+				break
+			}
+			fmt.Fprintf(buffered, "| %s | %s | %s | %s | %s |\n",
+				markdownEscape(match.First.Email),
+				markdownEscape(match.Second.Email),
+				fmtSimilarity(match.Similarity),
+				markdownEscape(match.First.Location.String()),
+				markdownEscape(match.Second.Location.String()))
+		}
+	}
+
+	return buffered.Flush()
+}
+
+// markdownEscaper escapes the characters that would break a Markdown table cell or its formatting
+var markdownEscaper = strings.NewReplacer(
+	"|", `\|`,
+	"\\", `\\`,
+	"*", `\*`,
+	"_", `\_`,
+	"`", "\\`",
+	"\r", " ",
+	"\n", " ",
+)
+
+// markdownEscape escapes a value for a Markdown table cell
+func markdownEscape(value string) string {
+	return markdownEscaper.Replace(value)
+}
@@ -0,0 +1,392 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Sheets of the Excel report of an N-way comparison
+const (
+	excelMembershipSheet   = "Membership"
+	excelCombinationsSheet = "Combinations"
+)
+
+// sourceSummaryColumns are the columns of the per-source summary of N-way reports
+var sourceSummaryColumns = []string{"Source", "File", "Total Emails", "Valid Emails", "Unique Emails", "Only In Source", "Skipped Rows", "Parse Errors"}
+
+// membershipHeaders returns the header row of the membership matrix, with a column per source.
+// Valid is column F, like on the entry sheets of the Excel report, so invalid rows are highlighted.
+func membershipHeaders(labels []string) []string {
+	headers := []string{"Email", "Normalized Email", "Key", "Source Count", "Present In", "Valid", "Disposable", "Reason"}
+	headers = append(headers, labels...)
+	return append(headers, "Locations")
+}
+
+// membershipRow returns the cells of a matrix row in the order of membershipHeaders
+func membershipRow(labels []string, row MembershipRow) []interface{} {
+	present := row.PresentIn(labels)
+	cells := []interface{}{
+		row.Email,
+		row.NormalizedEmail,
+		row.Key,
+		len(present),
+		strings.Join(present, ", "),
+		fmtBool(row.IsValid),
+		fmtBool(row.IsDisposable),
+		row.Reason,
+	}
+	for _, isPresent := range row.Present {
+		cells = append(cells, fmtBool(isPresent))
+	}
+	locations := make([]string, 0, len(present))
+	for i, location := range row.Locations {
+		if location != nil {
+			locations = append(locations, fmt.Sprintf("%s: %s", labels[i], location))
+		}
+	}
+	return append(cells, strings.Join(locations, "; "))
+}
+
+// sourceSummaryRow returns the cells of a source in the order of sourceSummaryColumns
+func sourceSummaryRow(source SourceSummary) []interface{} {
+	return []interface{}{source.Label, source.FileName, source.TotalEmails, source.ValidEmails, source.UniqueEmails, source.OnlyInSource, source.SkippedRows, source.ParseErrors}
+}
+
+// stringCells formats the cells of a row for CSV
+func stringCells(cells []interface{}) []string {
+	record := make([]string, len(cells))
+	for i, cell := range cells {
+		record[i] = fmt.Sprint(cell)
+	}
+	return record
+}
+
+// writeMembershipCSVReport writes the membership matrix, the combination counts, the per-source
+// summary and the parse errors as CSV
+func writeMembershipCSVReport(ctx context.Context, w io.Writer, report *ReportData) error {
+	membership := report.Membership
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write(membershipHeaders(membership.Labels)); err != nil {
+		return err
+	}
+	for i, row := range membership.Matrix {
+		if err := checkCancelled(ctx, i); err != nil {
+			return err
+		}
+		if err := writer.Write(stringCells(membershipRow(membership.Labels, row))); err != nil {
+			return err
+		}
+	}
+
+	// Write combination counts
+	summary := membership.Summary
+	rows := [][]string{{""}, {"Combinations"}, {"Sources", "Count"}}
+	for _, combination := range summary.Combinations {
+		rows = append(rows, []string{strings.Join(combination.Sources, " & "), fmt.Sprintf("%d", combination.Count)})
+	}
+
+	// Write per-source summary
+	rows = append(rows, []string{""}, []string{"Summary"}, sourceSummaryColumns)
+	for _, source := range summary.Sources {
+		rows = append(rows, stringCells(sourceSummaryRow(source)))
+	}
+	rows = append(rows,
+		[]string{"Comparison Strategy", string(summary.ComparisonStrategy)},
+		[]string{"Unique Emails", fmt.Sprintf("%d", summary.UniqueEmails)},
+		[]string{"In All Sources", fmt.Sprintf("%d", summary.InAllSources)},
+	)
+
+	// Write the malformed rows skipped by lenient parsing
+	if len(report.ParseErrors) > 0 {
+		rows = append(rows, []string{""}, []string{"Parse Errors"}, []string{"Source", "File", "Line", "Error"})
+		for _, parseError := range report.ParseErrors {
+			rows = append(rows, []string{parseError.Role, parseError.File, fmt.Sprintf("%d", parseError.Line), parseError.Message})
+		}
+	}
+
+	return writer.WriteAll(rows)
+}
+
+// writeMembershipExcelReport writes an Excel workbook with the membership matrix, the
+// combination counts, the per-source summary and the parse errors
+func writeMembershipExcelReport(ctx context.Context, w io.Writer, report *ReportData) error {
+	membership := report.Membership
+	f := excelize.NewFile()
+	defer f.Close()
+
+	styles, err := newExcelReportStyles(f)
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]interface{}, len(membership.Matrix))
+	for i, row := range membership.Matrix {
+		if err := checkCancelled(ctx, i); err != nil {
+			return err
+		}
+		rows[i] = membershipRow(membership.Labels, row)
+	}
+	if err := writeExcelEntrySheet(ctx, f, excelMembershipSheet, membershipHeaders(membership.Labels), rows, styles); err != nil {
+		return err
+	}
+
+	summary := membership.Summary
+	rows = make([][]interface{}, len(summary.Combinations))
+	for i, combination := range summary.Combinations {
+		rows[i] = []interface{}{strings.Join(combination.Sources, " & "), combination.Count}
+	}
+	if err := writeExcelEntrySheet(ctx, f, excelCombinationsSheet, []string{"Sources", "Count"}, rows, styles); err != nil {
+		return err
+	}
+
+	rows = make([][]interface{}, 0, len(summary.Sources)+4)
+	for _, source := range summary.Sources {
+		rows = append(rows, sourceSummaryRow(source))
+	}
+	rows = append(rows,
+		[]interface{}{},
+		[]interface{}{"Comparison Strategy", string(summary.ComparisonStrategy)},
+		[]interface{}{"Unique Emails", summary.UniqueEmails},
+		[]interface{}{"In All Sources", summary.InAllSources},
+	)
+	if err := writeExcelEntrySheet(ctx, f, excelSummarySheet, sourceSummaryColumns, rows, styles); err != nil {
+		return err
+	}
+
+	// Malformed rows skipped by lenient parsing
+	if len(report.ParseErrors) > 0 {
+		rows := make([][]interface{}, len(report.ParseErrors))
+		for i, parseError := range report.ParseErrors {
+			rows[i] = []interface{}{parseError.Role, parseError.File, parseError.Line, parseError.Message}
+		}
+		if err := writeExcelEntrySheet(ctx, f, excelParseErrorsSheet, []string{"Source", "File", "Line", "Error"}, rows, styles); err != nil {
+			return err
+		}
+	}
+
+	// Open the workbook on the matrix
+	if err := f.DeleteSheet("Sheet1"); err != nil {
+		return err
+	}
+	f.SetActiveSheet(0)
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return f.Write(w)
+}
+
+// membershipJSONReport is the document written by the JSON reporter for N-way comparisons
+type membershipJSONReport struct {
+	Sources     []string           `json:"sources"`
+	Summary     MultiSourceSummary `json:"summary"`
+	Inputs      []InputFile        `json:"inputs,omitempty"`
+	ParseErrors []ParseError       `json:"parseErrors,omitempty"`
+	Matrix      []MembershipRow    `json:"matrix"`
+}
+
+// writeMembershipJSONReport writes the summary and the membership matrix as one indented JSON document
+func writeMembershipJSONReport(ctx context.Context, w io.Writer, report *ReportData) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	membership := report.Membership
+	document := membershipJSONReport{
+		Sources:     membership.Labels,
+		Summary:     membership.Summary,
+		Inputs:      report.Inputs,
+		ParseErrors: report.ParseErrors,
+		Matrix:      membership.Matrix,
+	}
+	if document.Matrix == nil {
+		document.Matrix = []MembershipRow{}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(document)
+}
+
+// membershipNDJSONEntry is a line of the NDJSON report of an N-way comparison
+type membershipNDJSONEntry struct {
+	Category string `json:"category"`
+	MembershipRow
+}
+
+// writeMembershipNDJSONReport writes one JSON object per matrix row and line, with the
+// combination of sources that contain it as category
+func writeMembershipNDJSONReport(ctx context.Context, w io.Writer, report *ReportData) error {
+	membership := report.Membership
+	buffered := bufio.NewWriter(w)
+	encoder := json.NewEncoder(buffered)
+	for i, row := range membership.Matrix {
+		if err := checkCancelled(ctx, i); err != nil {
+			return err
+		}
+		entry := membershipNDJSONEntry{Category: membershipCategory(membership.Labels, row), MembershipRow: row}
+		if err := encoder.Encode(entry); err != nil {
+			return err
+		}
+	}
+	return buffered.Flush()
+}
+
+// writeMembershipMarkdownReport writes the per-source summary, the combination counts and the
+// first rows of the membership matrix as Markdown tables
+func writeMembershipMarkdownReport(ctx context.Context, w io.Writer, report *ReportData) error {
+	membership := report.Membership
+	summary := membership.Summary
+	buffered := bufio.NewWriter(w)
+
+	fmt.Fprintln(buffered, "# Source Comparison Report")
+	fmt.Fprintln(buffered)
+	fmt.Fprintln(buffered, "## Summary")
+	fmt.Fprintln(buffered)
+	fmt.Fprintln(buffered, "| Metric | Value |")
+	fmt.Fprintln(buffered, "| --- | ---: |")
+	fmt.Fprintf(buffered, "| Comparison Strategy | %s |\n", summary.ComparisonStrategy)
+	fmt.Fprintf(buffered, "| Unique Emails | %d |\n", summary.UniqueEmails)
+	fmt.Fprintf(buffered, "| In All Sources | %d |\n", summary.InAllSources)
+	fmt.Fprintf(buffered, "| Processing Time | %.2f s |\n", summary.ProcessingTimeSeconds)
+
+	fmt.Fprintln(buffered)
+	fmt.Fprintln(buffered, "## Sources")
+	fmt.Fprintln(buffered)
+	fmt.Fprintf(buffered, "| %s |\n", strings.Join(sourceSummaryColumns, " | "))
+	fmt.Fprintln(buffered, "| --- | --- | ---: | ---: | ---: | ---: | ---: | ---: |")
+	for _, source := range summary.Sources {
+		cells := stringCells(sourceSummaryRow(source))
+		for i := range cells {
+			cells[i] = markdownEscape(cells[i])
+		}
+		fmt.Fprintf(buffered, "| %s |\n", strings.Join(cells, " | "))
+	}
+
+	fmt.Fprintln(buffered)
+	fmt.Fprintln(buffered, "## Combinations")
+	fmt.Fprintln(buffered)
+	fmt.Fprintln(buffered, "| Sources | Count |")
+	fmt.Fprintln(buffered, "| --- | ---: |")
+	for _, combination := range summary.Combinations {
+		fmt.Fprintf(buffered, "| %s | %d |\n", markdownEscape(strings.Join(combination.Sources, " & ")), combination.Count)
+	}
+
+	if len(report.ParseErrors) > 0 {
+		fmt.Fprintln(buffered)
+		fmt.Fprintf(buffered, "## Parse Errors (%d)\n", len(report.ParseErrors))
+		fmt.Fprintln(buffered)
+		fmt.Fprintln(buffered, "| Source | File | Line | Error |")
+		fmt.Fprintln(buffered, "| --- | --- | ---: | --- |")
+		for i, parseError := range report.ParseErrors {
+			if i == markdownMaxRows {
+				fmt.Fprintln(buffered)
+				fmt.Fprintf(buffered, "_%d more not shown._\n", len(report.ParseErrors)-markdownMaxRows)
+				break
+			}
+			fmt.Fprintf(buffered, "| %s | %s | %d | %s |\n",
+				markdownEscape(parseError.Role),
+				markdownEscape(parseError.File),
+				parseError.Line,
+				markdownEscape(parseError.Message))
+		}
+	}
+
+	fmt.Fprintln(buffered)
+	fmt.Fprintf(buffered, "## Membership (%d)\n", len(membership.Matrix))
+	fmt.Fprintln(buffered)
+	if len(membership.Matrix) == 0 {
+		fmt.Fprintln(buffered, "None.")
+		return buffered.Flush()
+	}
+	headers := membershipHeaders(membership.Labels)
+	for i := range headers {
+		headers[i] = markdownEscape(headers[i])
+	}
+	fmt.Fprintf(buffered, "| %s |\n", strings.Join(headers, " | "))
+	fmt.Fprintf(buffered, "|%s\n", strings.Repeat(" --- |", len(headers)))
+	for i, row := range membership.Matrix {
+		if err := checkCancelled(ctx, i); err != nil {
+			return err
+		}
+		if i == markdownMaxRows {
+			fmt.Fprintln(buffered)
+			fmt.Fprintf(buffered, "_%d more not shown._\n", len(membership.Matrix)-markdownMaxRows)
+			break
+		}
+		cells := stringCells(membershipRow(membership.Labels, row))
+		for j := range cells {
+			cells[j] = markdownEscape(cells[j])
+		}
+		fmt.Fprintf(buffered, "| %s |\n", strings.Join(cells, " | "))
+	}
+
+	return buffered.Flush()
+}
+
+// writeMembershipHTMLReport writes a single-file HTML report with summary cards and tables of
+// the membership matrix, the combination counts and the sources
+func writeMembershipHTMLReport(ctx context.Context, w io.Writer, report *ReportData) error {
+	membership := report.Membership
+	summary := membership.Summary
+	page := htmlReportPage{
+		GeneratedAt: time.Now().Format("2006-01-02 15:04:05"),
+		Comparison:  string(summary.ComparisonStrategy),
+		Cards: []htmlSummaryCard{
+			{Label: "Unique Emails", Value: summary.UniqueEmails},
+			{Label: "In All Sources", Value: summary.InAllSources, Kind: "ok"},
+		},
+	}
+	for _, source := range summary.Sources {
+		page.Cards = append(page.Cards, htmlSummaryCard{Label: "Only in " + source.Label, Value: source.OnlyInSource, Kind: "warn"})
+	}
+
+	matrix := htmlTable{
+		ID:      "membership",
+		Title:   "Membership",
+		Columns: membershipHeaders(membership.Labels),
+		Rows:    make([][]interface{}, len(membership.Matrix)),
+	}
+	for i, row := range membership.Matrix {
+		if err := checkCancelled(ctx, i); err != nil {
+			return err
+		}
+		matrix.Rows[i] = membershipRow(membership.Labels, row)
+	}
+
+	combinations := htmlTable{
+		ID:      "combinations",
+		Title:   "Combinations",
+		Columns: []string{"Sources", "Count"},
+		Rows:    make([][]interface{}, len(summary.Combinations)),
+	}
+	for i, combination := range summary.Combinations {
+		combinations.Rows[i] = []interface{}{strings.Join(combination.Sources, " & "), combination.Count}
+	}
+
+	sources := htmlTable{
+		ID:      "sources",
+		Title:   "Sources",
+		Columns: sourceSummaryColumns,
+		Rows:    make([][]interface{}, len(summary.Sources)),
+	}
+	for i, source := range summary.Sources {
+		sources.Rows[i] = sourceSummaryRow(source)
+	}
+	page.Tables = []htmlTable{matrix, combinations, sources}
+
+	if len(report.ParseErrors) > 0 {
+		page.Cards = append(page.Cards, htmlSummaryCard{Label: "Parse Errors", Value: len(report.ParseErrors), Kind: "warn"})
+		page.Tables = append(page.Tables, parseErrorsTable(report.ParseErrors))
+	}
+
+	return htmlReportTemplate.Execute(w, page)
+}
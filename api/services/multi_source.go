@@ -0,0 +1,616 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math/bits"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"ness-to-odoo-golang-validation-api-tool/config"
+	"ness-to-odoo-golang-validation-api-tool/utils"
+)
+
+// Limits on the number of sources of an N-way comparison. The upper bound keeps the
+// number of Venn combinations (2^N - 1) and report columns manageable.
+const (
+	MinSources = 2
+	MaxSources = 10
+)
+
+// Source is a labelled input file of an N-way comparison
+type Source struct {
+	Label string `json:"label"`
+	Path  string `json:"-"`
+	// FileName is the name shown in reports, such as the original upload name. Defaults to the base name of Path.
+	FileName string         `json:"fileName"`
+	Options  ExtractOptions `json:"options"`
+}
+
+// MultiSourceOptions controls how an N-way comparison compares its sources and writes its report
+type MultiSourceOptions struct {
+	// OutputFormat is the report format, one of ReportFormats()
+	OutputFormat string `json:"outputFormat"`
+	// OutputPath overrides the report location. By default a timestamped file is created in the temp directory.
+	OutputPath string `json:"outputPath,omitempty"`
+	// Comparison is the key on which the emails are compared, one of ComparisonStrategies().
+	// Defaults to ComparisonNormalized.
+	Comparison ComparisonStrategy `json:"comparison,omitempty"`
+	// OdooMapping is the column mapping of the odoo-csv and odoo-xml formats, which export the
+	// emails missing in the last source. Defaults to DefaultOdooMapping().
+	OdooMapping OdooMapping `json:"odooMapping,omitempty"`
+	// CallbackURL receives a signed webhook when the comparison completes or fails
+	CallbackURL string `json:"callbackUrl,omitempty"`
+}
+
+// MembershipRow records which sources contain a comparison key
+type MembershipRow struct {
+	// Key is the value the sources were compared on: the normalized email by default, or the
+	// domain with the domain-level strategies
+	Key             string `json:"key"`
+	NormalizedEmail string `json:"normalizedEmail"`
+	// Email is the address as first seen in the sources, in source order
+	Email        string `json:"email"`
+	IsValid      bool   `json:"isValid"`
+	IsDisposable bool   `json:"isDisposable"`
+	Reason       string `json:"reason,omitempty"`
+	// Present has one flag per source, in the order of MultiSourceResult.Sources
+	Present []bool `json:"present"`
+	// Occurrences has the number of times the email appears in each source
+	Occurrences []int `json:"occurrences"`
+	// Locations has where the email first appears in each source, or nil when the source does not contain it
+	Locations []*SourceLocation `json:"locations"`
+}
+
+// PresentIn returns the labels of the sources that contain the email
+func (r MembershipRow) PresentIn(labels []string) []string {
+	present := make([]string, 0, len(labels))
+	for i, label := range labels {
+		if r.Present[i] {
+			present = append(present, label)
+		}
+	}
+	return present
+}
+
+// CombinationCount is the number of emails present in exactly the listed sources
+type CombinationCount struct {
+	Sources []string `json:"sources"`
+	Count   int      `json:"count"`
+}
+
+// SourceSummary contains statistics for one source of an N-way comparison
+type SourceSummary struct {
+	Label        string `json:"label"`
+	FileName     string `json:"fileName"`
+	TotalEmails  int    `json:"totalEmails"`
+	ValidEmails  int    `json:"validEmails"`
+	UniqueEmails int    `json:"uniqueEmails"`
+	// OnlyInSource is the number of emails no other source contains
+	OnlyInSource int `json:"onlyInSource"`
+	// SkippedRows counts the leading rows that were not read as data: rows skipped with skipRows and the header
+	SkippedRows int `json:"skippedRows"`
+	// ParseErrors counts the malformed rows skipped by lenient parsing
+	ParseErrors int `json:"parseErrors"`
+}
+
+// MultiSourceSummary contains summary statistics of an N-way comparison
+type MultiSourceSummary struct {
+	Sources []SourceSummary `json:"sources"`
+	// ComparisonStrategy is the key the sources were compared on, e.g. "normalized"
+	ComparisonStrategy    ComparisonStrategy `json:"comparisonStrategy"`
+	UniqueEmails          int                `json:"uniqueEmails"`
+	InAllSources          int                `json:"inAllSources"`
+	Combinations          []CombinationCount `json:"combinations"`
+	ProcessingTimeSeconds float64            `json:"processingTimeSeconds"`
+}
+
+// MultiSourceResult represents the result of an N-way comparison
+type MultiSourceResult struct {
+	Sources       []string           `json:"sources"`
+	Matrix        []MembershipRow    `json:"matrix"`
+	OutputFileURL string             `json:"outputFileURL,omitempty"`
+	FileName      string             `json:"fileName"`
+	RunID         string             `json:"runId,omitempty"`
+	Summary       MultiSourceSummary `json:"summary"`
+	// Inputs describes how the file of each source was read, with the source label as role
+	Inputs []InputFile `json:"inputs"`
+	// ParseErrors are the malformed rows skipped by lenient parsing, with the source label as role
+	ParseErrors []ParseError `json:"parseErrors"`
+}
+
+// CompareSources extracts, validates and compares the emails of N labelled sources.
+// The result is a membership matrix showing which sources contain each comparison key,
+// with counts for every combination of sources. The report is written by the reporter of
+// opts.OutputFormat, and the run is recorded and notified like a two-file validation.
+func CompareSources(ctx context.Context, sources []Source, opts MultiSourceOptions) (*MultiSourceResult, error) {
+	logger := utils.GetLogger()
+	if len(sources) < MinSources || len(sources) > MaxSources {
+		return nil, fmt.Errorf("between %d and %d sources are required, got %d", MinSources, MaxSources, len(sources))
+	}
+
+	labels := make([]string, len(sources))
+	seenLabels := make(map[string]bool, len(sources))
+	for i, source := range sources {
+		if source.Label == "" {
+			return nil, fmt.Errorf("source %d has no label", i+1)
+		}
+		if seenLabels[source.Label] {
+			return nil, fmt.Errorf("duplicate source label %q", source.Label)
+		}
+		seenLabels[source.Label] = true
+		labels[i] = source.Label
+	}
+
+	reporter, err := GetReporter(opts.OutputFormat)
+	if err != nil {
+		return nil, err
+	}
+	// The Odoo import files carry the mapped columns of every source but the last, which is
+	// the Odoo export the missing emails are created in
+	sources = append([]Source(nil), sources...)
+	if isOdooFormat(opts.OutputFormat) {
+		if opts.OdooMapping == nil {
+			if opts.OdooMapping, err = DefaultOdooMapping(); err != nil {
+				return nil, err
+			}
+		}
+		for i := range sources[:len(sources)-1] {
+			sources[i].Options.Fields = opts.OdooMapping.Columns()
+		}
+	}
+	strategy := opts.Comparison.orDefault()
+
+	logger.Info("Starting %d-way comparison of sources: %s", len(sources), strings.Join(labels, ", "))
+	defer trackJob()()
+	startTime := time.Now()
+	progress := newValidationProgress("extracting")
+
+	inputPaths := make([]string, len(sources))
+	runInputs := make([]RunInput, len(sources))
+	for i, source := range sources {
+		if source.FileName == "" {
+			sources[i].FileName = filepath.Base(source.Path)
+		}
+		inputPaths[i] = source.Path
+		runInputs[i] = RunInput{Role: source.Label, FileName: sources[i].FileName}
+	}
+	run := newRun(startTime, ValidationOptions{
+		OutputFormat: opts.OutputFormat,
+		OutputPath:   opts.OutputPath,
+		OdooMapping:  opts.OdooMapping,
+		Comparison:   strategy,
+		CallbackURL:  opts.CallbackURL,
+	}, runInputs...)
+
+	// Record failed runs, and log how far the run got if it ends because the context was cancelled
+	var runErr error
+	defer func() {
+		if runErr == nil {
+			return
+		}
+		if ctx.Err() != nil {
+			logger.Warn("Source comparison cancelled after %s (%s): %v",
+				utils.FormatDuration(time.Since(startTime)), progress, ctx.Err())
+		}
+		saveFailedRun(run, inputPaths, runErr, ctx.Err() != nil)
+		notifyRun(run, "")
+	}()
+
+	tempDir := config.Get().TempDir
+	if err := os.MkdirAll(tempDir, os.ModePerm); err != nil {
+		runErr = fmt.Errorf("failed to create temp directory: %w", err)
+		return nil, runErr
+	}
+
+	// Extract and validate every source concurrently
+	entriesBySource := make([][]EmailEntry, len(sources))
+	skippedBySource := make([]int, len(sources))
+	inputsBySource := make([][]InputFile, len(sources))
+	parseErrorsBySource := make([][]ParseError, len(sources))
+	errs := make([]error, len(sources))
+	wg := sync.WaitGroup{}
+	for i, source := range sources {
+		wg.Add(1)
+		go func(i int, source Source) {
+			defer wg.Done()
+			extraction, err := ExtractEmails(ctx, source.Path, source.Options)
+			if err != nil {
+				errs[i] = fmt.Errorf("failed to extract emails from %s: %w", source.Label, withFileName(err, source.FileName))
+				return
+			}
+			emails := extraction.Emails
+			inputs := extraction.inputs(source.Label)
+			parseErrors := extraction.parseErrors(source.Label)
+			// Report locations under the name the file was given, not its temporary name,
+			// keeping the entry names of ZIP archives
+			tempName := filepath.Base(source.Path)
+			for j := range emails {
+				emails[j].Location.File = source.FileName + strings.TrimPrefix(emails[j].Location.File, tempName)
+			}
+			for j := range inputs {
+				inputs[j].File = source.FileName + strings.TrimPrefix(inputs[j].File, tempName)
+			}
+			for j := range parseErrors {
+				parseErrors[j].File = source.FileName + strings.TrimPrefix(parseErrors[j].File, tempName)
+			}
+			inputsBySource[i] = inputs
+			parseErrorsBySource[i] = parseErrors
+			skippedBySource[i] = extraction.skippedRows()
+			progress.set("extracted from "+source.Label, len(emails))
+
+			entries, err := validateEmailList(ctx, emails, source.Label)
+			if err != nil {
+				errs[i] = fmt.Errorf("failed to validate emails from %s: %w", source.Label, err)
+				return
+			}
+			progress.set("validated from "+source.Label, len(entries))
+			entriesBySource[i] = entries
+		}(i, source)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			runErr = err
+			return nil, runErr
+		}
+	}
+	inputs := concatInputs(inputsBySource)
+	run.setInputFiles(inputs)
+
+	progress.setStage("comparing")
+	membership, err := compareSources(ctx, labels, entriesBySource, strategy)
+	if err != nil {
+		runErr = fmt.Errorf("failed to compare sources: %w", err)
+		return nil, runErr
+	}
+	summary := membership.summary
+	for i, source := range sources {
+		summary.Sources[i].FileName = source.FileName
+		summary.Sources[i].SkippedRows = skippedBySource[i]
+		summary.Sources[i].ParseErrors = len(parseErrorsBySource[i])
+	}
+	parseErrors := concatParseErrors(parseErrorsBySource)
+	summary.ProcessingTimeSeconds = time.Since(startTime).Seconds()
+
+	outputFilePath, outputFileName, outputFileURL := outputLocation("comparison_result", opts.OutputFormat, opts.OutputPath)
+
+	progress.setStage("generating report")
+	logger.Info("Generating output file: %s", outputFilePath)
+	matrix := membership.matrix()
+	report := &ReportData{
+		Membership: &MembershipReport{
+			Labels:        labels,
+			Matrix:        matrix,
+			Summary:       summary,
+			missingInLast: membership.missingIn(len(labels) - 1),
+		},
+		Summary:     ValidationSummary{ComparisonStrategy: strategy, ProcessingTimeSeconds: summary.ProcessingTimeSeconds},
+		OdooMapping: opts.OdooMapping,
+		Inputs:      inputs,
+		ParseErrors: parseErrors,
+	}
+	if err := writeReportFile(ctx, outputFilePath, reporter, report); err != nil {
+		logger.Error("Failed to generate output file: %v", err)
+		// Do not leave a partially written report behind
+		if removeErr := os.Remove(outputFilePath); removeErr != nil && !os.IsNotExist(removeErr) {
+			logger.Warn("Failed to remove partial output file %s: %v", outputFilePath, removeErr)
+		}
+		runErr = fmt.Errorf("failed to generate output file: %w", err)
+		return nil, runErr
+	}
+
+	result := &MultiSourceResult{
+		Sources:       labels,
+		Matrix:        matrix,
+		OutputFileURL: outputFileURL,
+		FileName:      outputFileName,
+		Summary:       summary,
+		Inputs:        inputs,
+		ParseErrors:   parseErrors,
+	}
+
+	run.Sources = &summary
+	if saveCompletedRun(run, inputPaths, report.Summary, outputFilePath, membershipRunEntries(labels, matrix), nil) {
+		result.RunID = run.ID
+	}
+	notifyRun(run, outputFileURL)
+
+	logger.Info("Source comparison completed in %s: %d unique keys, %d in all sources",
+		utils.FormatDuration(time.Since(startTime)), summary.UniqueEmails, summary.InAllSources)
+	return result, nil
+}
+
+// sourceMembership is the result of the comparison core shared by two-file validations and
+// N-way comparisons: which sources contain each key of the comparison strategy
+type sourceMembership struct {
+	strategy ComparisonStrategy
+	labels   []string
+	entries  [][]EmailEntry
+	// rows are in the order their key was first seen, visiting the sources in order
+	rows []MembershipRow
+	// rowOf holds the row of every entry of each source
+	rowOf   [][]int
+	summary MultiSourceSummary
+}
+
+// compareSources builds the membership of N sources keyed by the comparison strategy,
+// along with per-source and per-combination counts. Two-file validations are the case of
+// two sources, see compareEmailEntries.
+func compareSources(ctx context.Context, labels []string, entriesBySource [][]EmailEntry, strategy ComparisonStrategy) (*sourceMembership, error) {
+	logger := utils.GetLogger()
+	defer utils.LogExecutionTime("compareSources")()
+
+	n := len(labels)
+	strategy = strategy.orDefault()
+	m := &sourceMembership{
+		strategy: strategy,
+		labels:   labels,
+		entries:  entriesBySource,
+		rows:     make([]MembershipRow, 0),
+		rowOf:    make([][]int, n),
+		summary: MultiSourceSummary{
+			Sources:            make([]SourceSummary, n),
+			ComparisonStrategy: strategy,
+		},
+	}
+
+	// Index rows by key, visiting sources in order so the first-seen spelling wins
+	rowIndex := make(map[string]int)
+	for s, entries := range entriesBySource {
+		m.summary.Sources[s].Label = labels[s]
+		m.summary.Sources[s].TotalEmails = len(entries)
+		m.rowOf[s] = make([]int, len(entries))
+
+		for i, entry := range entries {
+			if err := checkCancelled(ctx, i); err != nil {
+				return nil, err
+			}
+
+			if entry.IsValid {
+				m.summary.Sources[s].ValidEmails++
+			}
+
+			key := strategy.key(entry)
+			idx, exists := rowIndex[key]
+			if !exists {
+				idx = len(m.rows)
+				rowIndex[key] = idx
+				m.rows = append(m.rows, MembershipRow{
+					Key:             key,
+					NormalizedEmail: entry.NormalizedEmail,
+					Email:           entry.Email,
+					IsValid:         entry.IsValid,
+					IsDisposable:    entry.IsDisposable,
+					Reason:          entry.Reason,
+					Present:         make([]bool, n),
+					Occurrences:     make([]int, n),
+					Locations:       make([]*SourceLocation, n),
+				})
+			}
+			m.rowOf[s][i] = idx
+
+			row := &m.rows[idx]
+			if !row.Present[s] {
+				row.Present[s] = true
+				location := entry.Location
+				row.Locations[s] = &location
+				m.summary.Sources[s].UniqueEmails++
+			}
+			row.Occurrences[s]++
+		}
+	}
+
+	// Count every combination of sources by bitmask
+	combinationCounts := make([]int, 1<<n)
+	allSources := (1 << n) - 1
+	for i, row := range m.rows {
+		if err := checkCancelled(ctx, i); err != nil {
+			return nil, err
+		}
+
+		mask := 0
+		for s, present := range row.Present {
+			if present {
+				mask |= 1 << s
+			}
+		}
+		combinationCounts[mask]++
+
+		if mask == allSources {
+			m.summary.InAllSources++
+		}
+		if bits.OnesCount(uint(mask)) == 1 {
+			m.summary.Sources[bits.TrailingZeros(uint(mask))].OnlyInSource++
+		}
+	}
+
+	m.summary.UniqueEmails = len(m.rows)
+	m.summary.Combinations = combinations(labels, combinationCounts)
+
+	logger.Info("Source comparison found %d unique keys, %d present in all %d sources (%s)",
+		m.summary.UniqueEmails, m.summary.InAllSources, n, strategy)
+	return m, nil
+}
+
+// matrix returns the membership rows sorted by key
+func (m *sourceMembership) matrix() []MembershipRow {
+	matrix := append([]MembershipRow(nil), m.rows...)
+	sort.Slice(matrix, func(i, j int) bool {
+		return matrix[i].Key < matrix[j].Key
+	})
+	return matrix
+}
+
+// missingIn returns the first entry of every key that source s does not contain, in the
+// order the keys were first seen
+func (m *sourceMembership) missingIn(s int) []EmailEntry {
+	missing := make([]EmailEntry, 0)
+	seen := make([]bool, len(m.rows))
+	for other, entries := range m.entries {
+		for i, entry := range entries {
+			row := m.rowOf[other][i]
+			if !seen[row] && !m.rows[row].Present[s] {
+				missing = append(missing, entry)
+			}
+			seen[row] = true
+		}
+	}
+	return missing
+}
+
+// twoWay splits the membership of two sources into the emails of both and the emails missing in
+// either. Matches keep the last entry of the key in the first source with the location in the
+// second; domain-level strategies report every domain of the second source once.
+func (m *sourceMembership) twoWay(ctx context.Context) (matching, missingInFirst, missingInSecond []EmailEntry, summary ValidationSummary, err error) {
+	firstEntries, secondEntries := m.entries[0], m.entries[1]
+	summary = ValidationSummary{
+		TotalEmailsFirstFile:  len(firstEntries),
+		TotalEmailsSecondFile: len(secondEntries),
+		ComparisonStrategy:    m.strategy,
+	}
+
+	// Last entry of every key in the first source
+	lastInFirst := make([]int, len(m.rows))
+	for i, entry := range firstEntries {
+		if err := checkCancelled(ctx, i); err != nil {
+			return nil, nil, nil, summary, err
+		}
+		if entry.IsValid {
+			summary.ValidEmailsFirstFile++
+		}
+		if entry.IsDisposable {
+			summary.DisposableEmailsCount++
+		}
+		lastInFirst[m.rowOf[0][i]] = i
+	}
+
+	matching = make([]EmailEntry, 0, min(len(firstEntries), len(secondEntries))/2)
+	missingInFirst = make([]EmailEntry, 0, len(secondEntries)/4)
+	seenInSecond := make([]bool, len(m.rows))
+	for i, entry := range secondEntries {
+		if err := checkCancelled(ctx, i); err != nil {
+			return nil, nil, nil, summary, err
+		}
+		if entry.IsValid {
+			summary.ValidEmailsSecondFile++
+		}
+		if entry.IsDisposable {
+			summary.DisposableEmailsCount++
+		}
+
+		row := m.rowOf[1][i]
+		if seenInSecond[row] && m.strategy.domainLevel() {
+			continue
+		}
+		seenInSecond[row] = true
+
+		if m.rows[row].Present[0] {
+			// It's a match; keep where it was found in both files
+			firstEntry := firstEntries[lastInFirst[row]]
+			secondLocation := entry.Location
+			firstEntry.MatchedLocation = &secondLocation
+			matching = append(matching, firstEntry)
+		} else {
+			missingInFirst = append(missingInFirst, entry)
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, nil, nil, summary, err
+	}
+	missingInSecond = make([]EmailEntry, 0, len(firstEntries)/4)
+	for row, membership := range m.rows {
+		if membership.Present[0] && !membership.Present[1] {
+			missingInSecond = append(missingInSecond, firstEntries[lastInFirst[row]])
+		}
+	}
+
+	summary.MatchingCount = len(matching)
+	summary.MissingInFirstCount = len(missingInFirst)
+	summary.MissingInSecondCount = len(missingInSecond)
+	return matching, missingInFirst, missingInSecond, summary, nil
+}
+
+// combinations lists the count of every non-empty combination of sources, Venn-style.
+// Combinations with more sources come first; ties keep the source order.
+func combinations(labels []string, counts []int) []CombinationCount {
+	masks := make([]int, 0, len(counts)-1)
+	for mask := 1; mask < len(counts); mask++ {
+		masks = append(masks, mask)
+	}
+	sort.SliceStable(masks, func(i, j int) bool {
+		return bits.OnesCount(uint(masks[i])) > bits.OnesCount(uint(masks[j]))
+	})
+
+	result := make([]CombinationCount, 0, len(masks))
+	for _, mask := range masks {
+		combination := CombinationCount{Count: counts[mask]}
+		for s, label := range labels {
+			if mask&(1<<s) != 0 {
+				combination.Sources = append(combination.Sources, label)
+			}
+		}
+		result = append(result, combination)
+	}
+	return result
+}
+
+// membershipCategory returns the category of a membership row in the run history, e.g.
+// "In crm & odoo", or "In All Sources"
+func membershipCategory(labels []string, row MembershipRow) string {
+	present := row.PresentIn(labels)
+	if len(present) == len(labels) {
+		return "In All Sources"
+	}
+	return "In " + strings.Join(present, " & ")
+}
+
+// membershipRunEntries converts the membership matrix to run entries, with the sources that
+// contain the email as source and category
+func membershipRunEntries(labels []string, matrix []MembershipRow) []RunEntry {
+	entries := make([]RunEntry, len(matrix))
+	for i, row := range matrix {
+		var location *SourceLocation
+		for _, l := range row.Locations {
+			if l != nil {
+				location = l
+				break
+			}
+		}
+		entries[i] = RunEntry{
+			Email:           row.Email,
+			NormalizedEmail: row.NormalizedEmail,
+			Source:          strings.Join(row.PresentIn(labels), ", "),
+			Category:        membershipCategory(labels, row),
+			IsValid:         row.IsValid,
+			IsDisposable:    row.IsDisposable,
+			Reason:          row.Reason,
+			Location:        location,
+		}
+	}
+	return entries
+}
+
+// concatParseErrors joins the parse errors of every source, in source order
+func concatParseErrors(parseErrorsBySource [][]ParseError) []ParseError {
+	parseErrors := make([]ParseError, 0)
+	for _, sourceErrors := range parseErrorsBySource {
+		parseErrors = append(parseErrors, sourceErrors...)
+	}
+	return parseErrors
+}
+
+// concatInputs joins the input files of every source, in source order
+func concatInputs(inputsBySource [][]InputFile) []InputFile {
+	inputs := make([]InputFile, 0, len(inputsBySource))
+	for _, files := range inputsBySource {
+		inputs = append(inputs, files...)
+	}
+	return inputs
+}
@@ -0,0 +1,256 @@
+package services
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"ness-to-odoo-golang-validation-api-tool/config"
+)
+
+// odooModel is the Odoo model the import files create records of
+const odooModel = "res.partner"
+
+// odooExternalIDPrefix prefixes the external IDs of the imported partners. Odoo files records
+// imported without a module under "__import__", so re-importing a file updates the same partners.
+const odooExternalIDPrefix = "__import__.ness_"
+
+// OdooField maps a res.partner field to the header of the source column its value is read from
+type OdooField struct {
+	Field  string `json:"field"`
+	Column string `json:"column"`
+}
+
+// OdooMapping is the column mapping of the Odoo import files, in column order
+type OdooMapping []OdooField
+
+// odooFieldName matches technical Odoo field names
+var odooFieldName = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+// ParseOdooMapping parses a column mapping such as "name=Full Name,phone=Mobile,company_name=Company".
+// The id and email fields are always filled by the export and cannot be mapped.
+func ParseOdooMapping(spec string) (OdooMapping, error) {
+	var mapping OdooMapping
+	seen := make(map[string]bool)
+	for _, pair := range strings.Split(spec, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		field, column, ok := strings.Cut(pair, "=")
+		field, column = strings.TrimSpace(field), strings.TrimSpace(column)
+		if !ok || field == "" || column == "" {
+			return nil, fmt.Errorf("invalid Odoo mapping %q: expected field=column", pair)
+		}
+		if !odooFieldName.MatchString(field) {
+			return nil, fmt.Errorf("invalid Odoo field name %q", field)
+		}
+		if field == "id" || field == "email" {
+			return nil, fmt.Errorf("Odoo field %q is filled by the export and cannot be mapped", field)
+		}
+		if seen[field] {
+			return nil, fmt.Errorf("Odoo field %q is mapped more than once", field)
+		}
+		seen[field] = true
+		mapping = append(mapping, OdooField{Field: field, Column: column})
+	}
+	return mapping, nil
+}
+
+// DefaultOdooMapping returns the column mapping configured with ODOO_FIELD_MAPPING
+func DefaultOdooMapping() (OdooMapping, error) {
+	mapping, err := ParseOdooMapping(config.Get().OdooFieldMapping)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ODOO_FIELD_MAPPING: %w", err)
+	}
+	return mapping, nil
+}
+
+// Columns returns the source columns read by the mapping
+func (m OdooMapping) Columns() []string {
+	columns := make([]string, len(m))
+	for i, field := range m {
+		columns[i] = field.Column
+	}
+	return columns
+}
+
+// isOdooFormat reports whether a report format is one of the Odoo import files
+func isOdooFormat(format string) bool {
+	return strings.HasPrefix(format, "odoo-")
+}
+
+// odooPartner is a partner record of the Odoo import files
+type odooPartner struct {
+	ID string
+	// Fields are the field values, in column order
+	Fields []odooValue
+}
+
+// odooValue is the value of a partner field
+type odooValue struct {
+	Field string
+	Value string
+}
+
+// odooPartners builds the partners to create in Odoo from the valid emails missing in the
+// second file, in the order of the first file, or missing in the last source of an N-way
+// comparison. Partners get the email as written in the file, since the normalized form may be
+// another address, e.g. without the +tag of a Gmail address; it only keys the external ID. The
+// partner name falls back to the display name given with the address, then to the email.
+func odooPartners(ctx context.Context, report *ReportData) ([]string, []odooPartner, error) {
+	headers := []string{"id", "name", "email"}
+	for _, field := range report.OdooMapping {
+		if field.Field != "name" {
+			headers = append(headers, field.Field)
+		}
+	}
+
+	// N-way comparisons create the emails missing in the last source, already in source order
+	missing := report.MissingInSecond
+	if report.Membership != nil {
+		missing = report.Membership.missingInLast
+	}
+	entries := make([]EmailEntry, 0, len(missing))
+	for _, entry := range missing {
+		if entry.IsValid {
+			entries = append(entries, entry)
+		}
+	}
+	if report.Membership == nil {
+		sort.SliceStable(entries, func(i, j int) bool {
+			return entries[i].Location.Row < entries[j].Location.Row
+		})
+	}
+
+	partners := make([]odooPartner, len(entries))
+	for i, entry := range entries {
+		if err := checkCancelled(ctx, i); err != nil {
+			return nil, nil, err
+		}
+
+		email := strings.TrimSpace(entry.Email)
+		values := map[string]string{"email": email}
+		for _, field := range report.OdooMapping {
+			values[field.Field] = strings.TrimSpace(entry.Fields[field.Column])
+		}
+		if values["name"] == "" {
+			values["name"] = strings.TrimSpace(entry.DisplayName)
+		}
+		if values["name"] == "" {
+			values["name"] = email
+		}
+
+		partner := odooPartner{ID: odooExternalIDPrefix + odooKey(entry.NormalizedEmail)}
+		for _, header := range headers[1:] {
+			partner.Fields = append(partner.Fields, odooValue{Field: header, Value: values[header]})
+		}
+		partners[i] = partner
+	}
+	return headers, partners, nil
+}
+
+// odooKeyInvalidChars matches the characters that are not allowed in an external ID
+var odooKeyInvalidChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// odooKey derives the external ID key of a partner from its normalized email alone, so that
+// every export gives an email the same ID. Replacing characters is lossy ("john.doe@x.com"
+// and "john_doe@x.com" give the same text), so a key that differs from the lower-cased email
+// gets a hash of the email appended, e.g. "john.doe@example.com" becomes
+// "john_doe_example_com_<hash>".
+func odooKey(email string) string {
+	email = strings.ToLower(email)
+	key := strings.Trim(odooKeyInvalidChars.ReplaceAllString(email, "_"), "_")
+	if key != email {
+		sum := sha1.Sum([]byte(email))
+		key += "_" + hex.EncodeToString(sum[:4])
+	}
+	return key
+}
+
+// writeOdooCSVReport writes a res.partner import CSV of the emails missing in the second file
+func writeOdooCSVReport(ctx context.Context, w io.Writer, report *ReportData) error {
+	headers, partners, err := odooPartners(ctx, report)
+	if err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(headers); err != nil {
+		return err
+	}
+	record := make([]string, len(headers))
+	for i, partner := range partners {
+		if err := checkCancelled(ctx, i); err != nil {
+			return err
+		}
+		record[0] = partner.ID
+		for j, field := range partner.Fields {
+			record[j+1] = field.Value
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// odooXMLDocument is an Odoo XML data file
+type odooXMLDocument struct {
+	XMLName xml.Name       `xml:"odoo"`
+	Data    odooXMLRecords `xml:"data"`
+}
+
+type odooXMLRecords struct {
+	Records []odooXMLRecord `xml:"record"`
+}
+
+type odooXMLRecord struct {
+	ID     string         `xml:"id,attr"`
+	Model  string         `xml:"model,attr"`
+	Fields []odooXMLField `xml:"field"`
+}
+
+type odooXMLField struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:",chardata"`
+}
+
+// writeOdooXMLReport writes an Odoo XML data file with a res.partner record per email missing
+// in the second file. Empty values are left out so they do not overwrite existing data. The
+// records are updatable, so loading a later export updates the partners it created before.
+func writeOdooXMLReport(ctx context.Context, w io.Writer, report *ReportData) error {
+	_, partners, err := odooPartners(ctx, report)
+	if err != nil {
+		return err
+	}
+
+	document := odooXMLDocument{Data: odooXMLRecords{Records: make([]odooXMLRecord, len(partners))}}
+	for i, partner := range partners {
+		record := odooXMLRecord{ID: partner.ID, Model: odooModel}
+		for _, field := range partner.Fields {
+			if field.Value != "" {
+				record.Fields = append(record.Fields, odooXMLField{Name: field.Field, Value: field.Value})
+			}
+		}
+		document.Data.Records[i] = record
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(document); err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestOdooKey(t *testing.T) {
+	tests := []struct {
+		email string
+		want  string
+	}{
+		{email: "john.doe@example.com", want: "john_doe_example_com_73ec53c4"},
+		{email: "John.Doe@Example.com", want: "john_doe_example_com_73ec53c4"},
+		{email: "john_doe@example.com", want: "john_doe_example_com_ff8a3a68"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.email, func(t *testing.T) {
+			if got := odooKey(tt.email); got != tt.want {
+				t.Errorf("odooKey(%q) = %q, want %q", tt.email, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOdooPartners(t *testing.T) {
+	report := &ReportData{
+		MissingInSecond: []EmailEntry{
+			{Email: " John.Doe+crm@gmail.com ", NormalizedEmail: "johndoe@gmail.com", IsValid: true,
+				Fields: map[string]string{"Phone": "+32 123"}, Location: SourceLocation{Row: 3}},
+			{Email: "Ann@x.vn", NormalizedEmail: "ann@x.vn", IsValid: true, DisplayName: "Ann Tran", Location: SourceLocation{Row: 2}},
+			{Email: "bad@", NormalizedEmail: "bad@", Location: SourceLocation{Row: 1}},
+		},
+		OdooMapping: OdooMapping{{Field: "name", Column: "Name"}, {Field: "phone", Column: "Phone"}},
+	}
+
+	headers, partners, err := odooPartners(context.Background(), report)
+	if err != nil {
+		t.Fatalf("odooPartners() error = %v", err)
+	}
+	if want := []string{"id", "name", "email", "phone"}; !reflect.DeepEqual(headers, want) {
+		t.Errorf("headers = %v, want %v", headers, want)
+	}
+	want := []odooPartner{
+		{ID: odooExternalIDPrefix + odooKey("ann@x.vn"), Fields: []odooValue{
+			{Field: "name", Value: "Ann Tran"}, {Field: "email", Value: "Ann@x.vn"}, {Field: "phone"}}},
+		{ID: odooExternalIDPrefix + odooKey("johndoe@gmail.com"), Fields: []odooValue{
+			{Field: "name", Value: "John.Doe+crm@gmail.com"}, {Field: "email", Value: "John.Doe+crm@gmail.com"}, {Field: "phone", Value: "+32 123"}}},
+	}
+	if !reflect.DeepEqual(partners, want) {
+		t.Errorf("partners = %+v, want %+v", partners, want)
+	}
+
+	var xml bytes.Buffer
+	if err := writeOdooXMLReport(context.Background(), &xml, report); err != nil {
+		t.Fatalf("writeOdooXMLReport() error = %v", err)
+	}
+	if strings.Contains(xml.String(), "noupdate") {
+		t.Errorf("XML report marks its records noupdate, so importing it again would not update them:\n%s", xml.String())
+	}
+}
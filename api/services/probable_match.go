@@ -0,0 +1,338 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/publicsuffix"
+	"ness-to-odoo-golang-validation-api-tool/config"
+	"ness-to-odoo-golang-validation-api-tool/utils"
+)
+
+// ProbableMatch pairs an email missing in the second file with a near-identical email missing
+// in the first file, such as "nguyen.vana@corp.vn" and "nguyenvana@corp.vn"
+type ProbableMatch struct {
+	First  EmailEntry `json:"first"`
+	Second EmailEntry `json:"second"`
+	// Similarity is 1 minus the edit distance of the local parts divided by the length of the
+	// longer one, so 1 means the local parts are equal and only the subdomains differ
+	Similarity float64 `json:"similarity"`
+}
+
+// entry returns the first-file email of the match, with the email, location and similarity of
+// the second-file email, as listed in the reports
+func (m ProbableMatch) entry() EmailEntry {
+	entry := m.First
+	secondLocation := m.Second.Location
+	entry.MatchedLocation = &secondLocation
+	entry.MatchedEmail = m.Second.Email
+	entry.Similarity = m.Similarity
+	return entry
+}
+
+// ParseProbableMatchThreshold parses a probable match threshold option: empty for the server
+// default, or a similarity between 0 and 1, where 0 disables probable matching
+func ParseProbableMatchThreshold(value string) (*float64, error) {
+	if value == "" {
+		return nil, nil
+	}
+	threshold, err := strconv.ParseFloat(value, 64)
+	if err != nil || threshold < 0 || threshold > 1 {
+		return nil, fmt.Errorf("probable match threshold must be a number between 0 and 1, got %q", value)
+	}
+	return &threshold, nil
+}
+
+// probableMatchThreshold returns the threshold of a run: the option, else the configured default
+func probableMatchThreshold(option *float64) float64 {
+	if option != nil {
+		return *option
+	}
+	return config.Get().ProbableMatchThreshold
+}
+
+// probableMatchingApplies reports whether probable matching can run with a comparison
+// strategy. It pairs normalized local parts, so it would undo the raw and case-insensitive
+// strategies, and the domain strategies compare no local parts at all.
+func probableMatchingApplies(strategy ComparisonStrategy) bool {
+	return strategy.orDefault() == ComparisonNormalized
+}
+
+// matchKey is a distinct normalized email of one side of the probable matching pass
+type matchKey struct {
+	normalized string
+	local      []rune
+	// entry is the first entry with this normalized email
+	entry EmailEntry
+}
+
+// matchCandidate is a pair of keys whose similarity reaches the threshold
+type matchCandidate struct {
+	first, second int
+	similarity    float64
+}
+
+// probableEntries returns the report entries of probable matches
+func probableEntries(matches []ProbableMatch) []EmailEntry {
+	entries := make([]EmailEntry, len(matches))
+	for i, match := range matches {
+		entries[i] = match.entry()
+	}
+	return entries
+}
+
+// findProbableMatches pairs the valid emails of missingInSecond (read from the first file) with
+// those of missingInFirst (read from the second file) whose local parts are within the
+// threshold similarity, in the same registrable domain. Every email is paired at most once,
+// best pairs first. The paired emails are removed from the missing lists. The emails are
+// compared normalized, so callers only run it with ComparisonNormalized; see
+// probableMatchingApplies.
+func findProbableMatches(ctx context.Context, missingInFirst, missingInSecond []EmailEntry, threshold float64) (matches []ProbableMatch, remainingInFirst, remainingInSecond []EmailEntry, err error) {
+	matches = make([]ProbableMatch, 0)
+	if threshold <= 0 || len(missingInFirst) == 0 || len(missingInSecond) == 0 {
+		return matches, missingInFirst, missingInSecond, nil
+	}
+	logger := utils.GetLogger()
+	defer utils.LogExecutionTime("findProbableMatches")()
+
+	// Candidates are only compared within a block: the emails of one registrable domain
+	firstKeys, firstBlocks := matchKeys(missingInSecond)
+	secondKeys, secondBlocks := matchKeys(missingInFirst)
+
+	var candidates []matchCandidate
+	compared := 0
+	for domain, firstIDs := range firstBlocks {
+		secondIDs, ok := secondBlocks[domain]
+		if !ok {
+			continue
+		}
+		index := newLocalPartIndex(secondKeys, secondIDs)
+		for i, id := range firstIDs {
+			if err := checkCancelled(ctx, i); err != nil {
+				return nil, nil, nil, err
+			}
+			for _, candidate := range index.search(firstKeys[id].local, threshold) {
+				compared++
+				candidate.first = id
+				candidates = append(candidates, candidate)
+			}
+		}
+	}
+
+	// Pair the most similar emails first, breaking ties by email so runs are repeatable
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.similarity != b.similarity {
+			return a.similarity > b.similarity
+		}
+		if firstKeys[a.first].normalized != firstKeys[b.first].normalized {
+			return firstKeys[a.first].normalized < firstKeys[b.first].normalized
+		}
+		return secondKeys[a.second].normalized < secondKeys[b.second].normalized
+	})
+	pairedFirst := make(map[string]bool)
+	pairedSecond := make(map[string]bool)
+	for _, candidate := range candidates {
+		first, second := firstKeys[candidate.first], secondKeys[candidate.second]
+		if pairedFirst[first.normalized] || pairedSecond[second.normalized] {
+			continue
+		}
+		pairedFirst[first.normalized] = true
+		pairedSecond[second.normalized] = true
+		matches = append(matches, ProbableMatch{First: first.entry, Second: second.entry, Similarity: candidate.similarity})
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].First.NormalizedEmail < matches[j].First.NormalizedEmail
+	})
+
+	remainingInFirst = withoutEmails(missingInFirst, pairedSecond, ComparisonNormalized)
+	remainingInSecond = withoutEmails(missingInSecond, pairedFirst, ComparisonNormalized)
+
+	logger.Info("Probable matching paired %d emails at similarity %.2f or more (%d candidate pairs)",
+		len(matches), threshold, compared)
+	return matches, remainingInFirst, remainingInSecond, nil
+}
+
+// matchKeys returns the distinct valid normalized emails of entries, and their indexes grouped
+// by registrable domain
+func matchKeys(entries []EmailEntry) ([]matchKey, map[string][]int) {
+	keys := make([]matchKey, 0, len(entries))
+	blocks := make(map[string][]int)
+	seen := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		if !entry.IsValid || seen[entry.NormalizedEmail] {
+			continue
+		}
+		at := strings.LastIndex(entry.NormalizedEmail, "@")
+		if at <= 0 || at == len(entry.NormalizedEmail)-1 {
+			continue
+		}
+		seen[entry.NormalizedEmail] = true
+		domain := registrableDomain(entry.NormalizedEmail[at+1:])
+		blocks[domain] = append(blocks[domain], len(keys))
+		keys = append(keys, matchKey{
+			normalized: entry.NormalizedEmail,
+			local:      []rune(entry.NormalizedEmail[:at]),
+			entry:      entry,
+		})
+	}
+	return keys, blocks
+}
+
+// registrableDomain returns the domain under a public suffix, e.g. "corp.vn" for
+// "mail.corp.vn", or the domain itself when it has none
+func registrableDomain(domain string) string {
+	domain = strings.TrimSuffix(strings.ToLower(domain), ".")
+	if registrable, err := publicsuffix.EffectiveTLDPlusOne(domain); err == nil {
+		return registrable
+	}
+	return domain
+}
+
+// withoutEmails returns the entries whose key in the comparison strategy is not in emails
+func withoutEmails(entries []EmailEntry, emails map[string]bool, strategy ComparisonStrategy) []EmailEntry {
+	remaining := make([]EmailEntry, 0, len(entries))
+	for _, entry := range entries {
+		if !emails[strategy.key(entry)] {
+			remaining = append(remaining, entry)
+		}
+	}
+	return remaining
+}
+
+// localPartIndex indexes the local parts of a block by length and, within a length, by bigram,
+// so that a search only computes the edit distance to local parts that can reach the threshold
+type localPartIndex struct {
+	keys     []matchKey
+	byLength map[int]*lengthBucket
+	// shared counts the bigrams shared with the searched local part, by position in a bucket
+	shared []int
+}
+
+// lengthBucket holds the indexed local parts of one length
+type lengthBucket struct {
+	ids []int
+	// byGram lists the positions in ids of the local parts with a bigram
+	byGram map[string][]int
+}
+
+// newLocalPartIndex indexes the keys with the given indexes
+func newLocalPartIndex(keys []matchKey, ids []int) *localPartIndex {
+	index := &localPartIndex{keys: keys, byLength: make(map[int]*lengthBucket)}
+	for _, id := range ids {
+		local := keys[id].local
+		bucket := index.byLength[len(local)]
+		if bucket == nil {
+			bucket = &lengthBucket{byGram: make(map[string][]int)}
+			index.byLength[len(local)] = bucket
+		}
+		for gram := range bigrams(local) {
+			bucket.byGram[gram] = append(bucket.byGram[gram], len(bucket.ids))
+		}
+		bucket.ids = append(bucket.ids, id)
+		if len(bucket.ids) > len(index.shared) {
+			index.shared = append(index.shared, 0)
+		}
+	}
+	return index
+}
+
+// search returns the indexed keys whose local part has at least the threshold similarity to local
+func (x *localPartIndex) search(local []rune, threshold float64) []matchCandidate {
+	grams := bigrams(local)
+
+	// Similar local parts have similar lengths: |a| - |b| <= distance <= (1 - threshold) * max(|a|, |b|)
+	minLength := int(math.Ceil(threshold*float64(len(local)) - 1e-9))
+	maxLength := int(math.Floor(float64(len(local))/threshold + 1e-9))
+	var candidates []matchCandidate
+	for length := minLength; length <= maxLength; length++ {
+		bucket := x.byLength[length]
+		if bucket == nil {
+			continue
+		}
+		longest := max(length, len(local))
+		maxDistance := int(math.Floor((1-threshold)*float64(longest) + 1e-9))
+
+		// An edit removes at most two bigrams, so similar local parts share most of them.
+		// When that bound is not positive, every local part of this length is compared.
+		ids := bucket.ids
+		if minShared := len(grams) - 2*maxDistance; minShared > 0 {
+			shared := x.shared[:len(bucket.ids)]
+			for gram := range grams {
+				for _, position := range bucket.byGram[gram] {
+					shared[position]++
+				}
+			}
+			ids = nil
+			for position, count := range shared {
+				if count >= minShared {
+					ids = append(ids, bucket.ids[position])
+				}
+				shared[position] = 0
+			}
+		}
+
+		for _, id := range ids {
+			distance := editDistance(local, x.keys[id].local, maxDistance)
+			if distance > maxDistance {
+				continue
+			}
+			similarity := 1 - float64(distance)/float64(longest)
+			candidates = append(candidates, matchCandidate{second: id, similarity: math.Round(similarity*1000) / 1000})
+		}
+	}
+	return candidates
+}
+
+// bigrams returns the distinct bigrams of s, padded so that its first and last characters
+// form bigrams too
+func bigrams(s []rune) map[string]struct{} {
+	padded := make([]rune, 0, len(s)+2)
+	padded = append(padded, '^')
+	padded = append(padded, s...)
+	padded = append(padded, '$')
+	grams := make(map[string]struct{}, len(padded)-1)
+	for i := 0; i+1 < len(padded); i++ {
+		grams[string(padded[i:i+2])] = struct{}{}
+	}
+	return grams
+}
+
+// editDistance returns the Levenshtein distance of a and b, or maxDistance+1 as soon as the
+// distance is known to exceed maxDistance
+func editDistance(a, b []rune, maxDistance int) int {
+	if len(a) < len(b) {
+		a, b = b, a
+	}
+	if len(a)-len(b) > maxDistance {
+		return maxDistance + 1
+	}
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		rowMin := current[0]
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(min(previous[j]+1, current[j-1]+1), previous[j-1]+cost)
+			if current[j] < rowMin {
+				rowMin = current[j]
+			}
+		}
+		if rowMin > maxDistance {
+			return maxDistance + 1
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
@@ -0,0 +1,233 @@
+package services
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b        string
+		maxDistance int
+		want        int
+	}{
+		{a: "", b: "", maxDistance: 3, want: 0},
+		{a: "abc", b: "", maxDistance: 3, want: 3},
+		{a: "nguyenvana", b: "nguyenvana", maxDistance: 2, want: 0},
+		{a: "nguyen.vana", b: "nguyenvana", maxDistance: 2, want: 1},
+		{a: "jonh", b: "john", maxDistance: 2, want: 2},
+		{a: "kitten", b: "sitting", maxDistance: 5, want: 3},
+		{a: "đức", b: "duc", maxDistance: 3, want: 2},
+		{a: "kitten", b: "sitting", maxDistance: 2, want: 3},
+		{a: "a", b: "abcdef", maxDistance: 2, want: 3},
+		{a: "abcdef", b: "uvwxyz", maxDistance: 1, want: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"/"+tt.b, func(t *testing.T) {
+			if got := editDistance([]rune(tt.a), []rune(tt.b), tt.maxDistance); got != tt.want {
+				t.Errorf("editDistance(%q, %q, %d) = %d, want %d", tt.a, tt.b, tt.maxDistance, got, tt.want)
+			}
+			if got := editDistance([]rune(tt.b), []rune(tt.a), tt.maxDistance); got != tt.want {
+				t.Errorf("editDistance(%q, %q, %d) = %d, want %d", tt.b, tt.a, tt.maxDistance, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLocalPartIndexSearch(t *testing.T) {
+	locals := []string{"nguyenvana", "nguyen.vana", "nguyenvanb", "anavneyugn", "vana", "nguyen.van.a", "tran.thib"}
+	keys := make([]matchKey, len(locals))
+	ids := make([]int, len(locals))
+	for i, local := range locals {
+		keys[i] = matchKey{normalized: local + "@corp.vn", local: []rune(local)}
+		ids[i] = i
+	}
+	index := newLocalPartIndex(keys, ids)
+
+	tests := []struct {
+		name      string
+		local     string
+		threshold float64
+		want      map[string]float64
+	}{
+		{
+			name:      "one edit",
+			local:     "nguyenvana",
+			threshold: 0.85,
+			want:      map[string]float64{"nguyenvana": 1, "nguyen.vana": 0.909, "nguyenvanb": 0.9},
+		},
+		{
+			name:      "threshold is inclusive",
+			local:     "nguyenvana",
+			threshold: 0.9,
+			want:      map[string]float64{"nguyenvana": 1, "nguyen.vana": 0.909, "nguyenvanb": 0.9},
+		},
+		{
+			name:      "two edits",
+			local:     "nguyenvana",
+			threshold: 0.8,
+			want:      map[string]float64{"nguyenvana": 1, "nguyen.vana": 0.909, "nguyenvanb": 0.9, "nguyen.van.a": 0.833},
+		},
+		{
+			name:      "no candidate",
+			local:     "le.minh",
+			threshold: 0.85,
+			want:      map[string]float64{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make(map[string]float64)
+			for _, candidate := range index.search([]rune(tt.local), tt.threshold) {
+				got[locals[candidate.second]] = candidate.similarity
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("search(%q, %.2f) = %v, want %v", tt.local, tt.threshold, got, tt.want)
+			}
+		})
+	}
+
+	// The length and bigram filters must not drop any local part that reaches the threshold
+	for _, threshold := range []float64{0.5, 0.7, 0.8, 0.85, 0.9, 1} {
+		for _, local := range locals {
+			var want []int
+			for id, other := range locals {
+				longest := max(len([]rune(local)), len([]rune(other)))
+				distance := editDistance([]rune(local), []rune(other), longest)
+				if 1-float64(distance)/float64(longest) >= threshold-1e-9 {
+					want = append(want, id)
+				}
+			}
+			var got []int
+			for _, candidate := range index.search([]rune(local), threshold) {
+				got = append(got, candidate.second)
+			}
+			sort.Ints(got)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("search(%q, %.2f) found %v, want %v as a full scan", local, threshold, got, want)
+			}
+		}
+	}
+}
+
+func TestFindProbableMatches(t *testing.T) {
+	entry := func(email string, valid bool) EmailEntry {
+		return EmailEntry{Email: email, NormalizedEmail: email, IsValid: valid}
+	}
+	emails := func(entries []EmailEntry) []string {
+		result := make([]string, len(entries))
+		for i, entry := range entries {
+			result[i] = entry.NormalizedEmail
+		}
+		return result
+	}
+
+	tests := []struct {
+		name string
+		// missingInSecond is read from the first file, missingInFirst from the second
+		missingInSecond []EmailEntry
+		missingInFirst  []EmailEntry
+		threshold       float64
+		wantPairs       map[string]string
+		wantInSecond    []string
+		wantInFirst     []string
+	}{
+		{
+			name:            "pairs across subdomains",
+			missingInSecond: []EmailEntry{entry("nguyen.vana@corp.vn", true)},
+			missingInFirst:  []EmailEntry{entry("nguyenvana@mail.corp.vn", true)},
+			threshold:       0.85,
+			wantPairs:       map[string]string{"nguyen.vana@corp.vn": "nguyenvana@mail.corp.vn"},
+			wantInSecond:    []string{},
+			wantInFirst:     []string{},
+		},
+		{
+			name:            "disabled",
+			missingInSecond: []EmailEntry{entry("nguyen.vana@corp.vn", true)},
+			missingInFirst:  []EmailEntry{entry("nguyenvana@corp.vn", true)},
+			threshold:       0,
+			wantPairs:       map[string]string{},
+			wantInSecond:    []string{"nguyen.vana@corp.vn"},
+			wantInFirst:     []string{"nguyenvana@corp.vn"},
+		},
+		{
+			name:            "other domains are not compared",
+			missingInSecond: []EmailEntry{entry("nguyenvana@corp.vn", true)},
+			missingInFirst:  []EmailEntry{entry("nguyenvana@corp.com", true)},
+			threshold:       0.85,
+			wantPairs:       map[string]string{},
+			wantInSecond:    []string{"nguyenvana@corp.vn"},
+			wantInFirst:     []string{"nguyenvana@corp.com"},
+		},
+		{
+			name:            "invalid emails are not paired",
+			missingInSecond: []EmailEntry{entry("nguyen.vana@corp.vn", false)},
+			missingInFirst:  []EmailEntry{entry("nguyenvana@corp.vn", true)},
+			threshold:       0.85,
+			wantPairs:       map[string]string{},
+			wantInSecond:    []string{"nguyen.vana@corp.vn"},
+			wantInFirst:     []string{"nguyenvana@corp.vn"},
+		},
+		{
+			name: "best pair first, each email once",
+			missingInSecond: []EmailEntry{
+				entry("nguyenvan@corp.vn", true),
+				entry("nguyenvana@corp.vn", true),
+			},
+			missingInFirst: []EmailEntry{entry("nguyenvana1@corp.vn", true)},
+			threshold:      0.8,
+			wantPairs:      map[string]string{"nguyenvana@corp.vn": "nguyenvana1@corp.vn"},
+			wantInSecond:   []string{"nguyenvan@corp.vn"},
+			wantInFirst:    []string{},
+		},
+		{
+			name:            "below the threshold",
+			missingInSecond: []EmailEntry{entry("an@corp.vn", true)},
+			missingInFirst:  []EmailEntry{entry("anh@corp.vn", true)},
+			threshold:       0.85,
+			wantPairs:       map[string]string{},
+			wantInSecond:    []string{"an@corp.vn"},
+			wantInFirst:     []string{"anh@corp.vn"},
+		},
+		{
+			name:            "repeated emails are removed together",
+			missingInSecond: []EmailEntry{entry("nguyen.vana@corp.vn", true), entry("nguyen.vana@corp.vn", true)},
+			missingInFirst:  []EmailEntry{entry("nguyenvana@corp.vn", true)},
+			threshold:       0.85,
+			wantPairs:       map[string]string{"nguyen.vana@corp.vn": "nguyenvana@corp.vn"},
+			wantInSecond:    []string{},
+			wantInFirst:     []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches, inFirst, inSecond, err := findProbableMatches(context.Background(), tt.missingInFirst, tt.missingInSecond, tt.threshold)
+			if err != nil {
+				t.Fatalf("findProbableMatches() error = %v", err)
+			}
+			pairs := make(map[string]string, len(matches))
+			for _, match := range matches {
+				pairs[match.First.NormalizedEmail] = match.Second.NormalizedEmail
+				if match.Similarity < tt.threshold || match.Similarity > 1 || math.IsNaN(match.Similarity) {
+					t.Errorf("similarity of %s and %s = %v, want between %v and 1",
+						match.First.NormalizedEmail, match.Second.NormalizedEmail, match.Similarity, tt.threshold)
+				}
+			}
+			if !reflect.DeepEqual(pairs, tt.wantPairs) {
+				t.Errorf("pairs = %v, want %v", pairs, tt.wantPairs)
+			}
+			if got := emails(inSecond); !reflect.DeepEqual(got, tt.wantInSecond) {
+				t.Errorf("remaining missing in second = %v, want %v", got, tt.wantInSecond)
+			}
+			if got := emails(inFirst); !reflect.DeepEqual(got, tt.wantInFirst) {
+				t.Errorf("remaining missing in first = %v, want %v", got, tt.wantInFirst)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// validationProgress records how far a validation run got so that a cancelled
+// run can report the stage it was in and the work it had already completed
+type validationProgress struct {
+	mu     sync.Mutex
+	stage  string
+	keys   []string
+	counts map[string]int
+}
+
+// newValidationProgress creates a progress tracker starting in the given stage
+func newValidationProgress(stage string) *validationProgress {
+	return &validationProgress{
+		stage:  stage,
+		counts: make(map[string]int),
+	}
+}
+
+// setStage records the stage the run has entered
+func (p *validationProgress) setStage(stage string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.stage = stage
+}
+
+// set records a counter value, keeping counters in the order they were first set
+func (p *validationProgress) set(key string, value int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, exists := p.counts[key]; !exists {
+		p.keys = append(p.keys, key)
+	}
+	p.counts[key] = value
+}
+
+// String formats the progress for log messages
+func (p *validationProgress) String() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("stage: %s", p.stage))
+	for _, key := range p.keys {
+		sb.WriteString(fmt.Sprintf(", %s: %d", key, p.counts[key]))
+	}
+	return sb.String()
+}
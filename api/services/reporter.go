@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ReportData holds the results written to a validation report. Reports of N-way comparisons
+// have Membership set and the two-file fields empty.
+type ReportData struct {
+	// FirstEntries and SecondEntries are all the emails read from each file, in file order
+	FirstEntries    []EmailEntry
+	SecondEntries   []EmailEntry
+	Matching        []EmailEntry
+	MissingInFirst  []EmailEntry
+	MissingInSecond []EmailEntry
+	// KeyMatches pairs the emails missing in either file whose records have the same match key
+	// values; they are not part of MissingInFirst and MissingInSecond
+	KeyMatches []KeyMatch
+	// ProbableMatches pairs near-identical emails missing in either file; they are not part of
+	// MissingInFirst and MissingInSecond
+	ProbableMatches []ProbableMatch
+	Summary         ValidationSummary
+	// Domains counts the emails by registrable domain, largest domains first
+	Domains []DomainStats
+	// OdooMapping is the column mapping of the Odoo import files
+	OdooMapping OdooMapping
+	// Inputs describes how each input file was read
+	Inputs []InputFile
+	// ParseErrors are the malformed rows skipped by lenient parsing
+	ParseErrors []ParseError
+	// Membership holds the results of an N-way comparison
+	Membership *MembershipReport
+}
+
+// MembershipReport holds the results of an N-way comparison written to a report
+type MembershipReport struct {
+	// Labels are the source labels, in the order of the Present flags of the matrix
+	Labels  []string
+	Matrix  []MembershipRow
+	Summary MultiSourceSummary
+	// missingInLast are the first entries of the keys the last source does not contain, which
+	// the Odoo import files create
+	missingInLast []EmailEntry
+}
+
+// Reporter writes validation reports in one format
+type Reporter interface {
+	// Extension returns the file extension of the reports, including the dot
+	Extension() string
+	// ContentType returns the MIME type of the reports
+	ContentType() string
+	// Write writes the report to w
+	Write(ctx context.Context, w io.Writer, report *ReportData) error
+}
+
+// reporters maps report formats, as accepted in outputFormat, to their writers
+var reporters = map[string]Reporter{}
+
+// RegisterReporter makes a report format available under the given name
+func RegisterReporter(format string, reporter Reporter) {
+	reporters[format] = reporter
+}
+
+// GetReporter returns the reporter of a report format
+func GetReporter(format string) (Reporter, error) {
+	reporter, ok := reporters[format]
+	if !ok {
+		return nil, fmt.Errorf("unsupported report format %q; use one of: %s", format, strings.Join(ReportFormats(), ", "))
+	}
+	return reporter, nil
+}
+
+// ReportFormats returns the names of the registered report formats, sorted
+func ReportFormats() []string {
+	formats := make([]string, 0, len(reporters))
+	for format := range reporters {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// ReportFormatForExtension returns the report format whose files have the given extension.
+// When several formats share an extension, the first in sorted order wins, so ".csv" is "csv".
+func ReportFormatForExtension(ext string) (string, bool) {
+	ext = strings.ToLower(ext)
+	if ext == ".xls" {
+		ext = ".xlsx"
+	}
+	for _, format := range ReportFormats() {
+		if reporters[format].Extension() == ext {
+			return format, true
+		}
+	}
+	return "", false
+}
+
+// ReportContentType returns the MIME type of a report file based on its extension
+func ReportContentType(filename string) string {
+	if format, ok := ReportFormatForExtension(filepath.Ext(filename)); ok {
+		return reporters[format].ContentType()
+	}
+	return "application/octet-stream"
+}
+
+// reportExtension returns the file extension of a report format
+func reportExtension(format string) string {
+	if reporter, ok := reporters[format]; ok {
+		return reporter.Extension()
+	}
+	return "." + format
+}
+
+// writeReportFile writes a report to outputPath with the given reporter
+func writeReportFile(ctx context.Context, outputPath string, reporter Reporter, report *ReportData) error {
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return err
+	}
+
+	if err := reporter.Write(ctx, file, report); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// reportWriter writes a report to w
+type reportWriter func(ctx context.Context, w io.Writer, report *ReportData) error
+
+// reporterFunc adapts write functions to the Reporter interface: write for two-file
+// validations and writeMembership for N-way comparisons
+type reporterFunc struct {
+	extension       string
+	contentType     string
+	write           reportWriter
+	writeMembership reportWriter
+}
+
+func (r reporterFunc) Extension() string   { return r.extension }
+func (r reporterFunc) ContentType() string { return r.contentType }
+
+func (r reporterFunc) Write(ctx context.Context, w io.Writer, report *ReportData) error {
+	if report.Membership != nil {
+		return r.writeMembership(ctx, w, report)
+	}
+	return r.write(ctx, w, report)
+}
+
+func init() {
+	RegisterReporter("csv", reporterFunc{".csv", "text/csv", writeCSVReport, writeMembershipCSVReport})
+	RegisterReporter("excel", reporterFunc{".xlsx", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", writeExcelReport, writeMembershipExcelReport})
+	RegisterReporter("json", reporterFunc{".json", "application/json", writeJSONReport, writeMembershipJSONReport})
+	RegisterReporter("ndjson", reporterFunc{".ndjson", "application/x-ndjson", writeNDJSONReport, writeMembershipNDJSONReport})
+	RegisterReporter("markdown", reporterFunc{".md", "text/markdown; charset=utf-8", writeMarkdownReport, writeMembershipMarkdownReport})
+	RegisterReporter("odoo-csv", reporterFunc{".csv", "text/csv", writeOdooCSVReport, writeOdooCSVReport})
+	RegisterReporter("odoo-xml", reporterFunc{".xml", "application/xml", writeOdooXMLReport, writeOdooXMLReport})
+	RegisterReporter("html", reporterFunc{".html", "text/html; charset=utf-8", writeHTMLReport, writeMembershipHTMLReport})
+}
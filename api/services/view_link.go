@@ -0,0 +1,82 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"ness-to-odoo-golang-validation-api-tool/config"
+	"ness-to-odoo-golang-validation-api-tool/utils"
+)
+
+// Query parameters of a signed view link
+const (
+	ViewLinkExpiresParam   = "expires"
+	ViewLinkSignatureParam = "signature"
+)
+
+// ViewLink is a link that opens an HTML report in the browser without an API key
+type ViewLink struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+var (
+	viewLinkKey     []byte
+	viewLinkKeyOnce sync.Once
+)
+
+// viewLinkSecret returns the key of view link signatures: VIEW_LINK_SECRET, or a random key
+// that lasts until the server restarts
+func viewLinkSecret() []byte {
+	viewLinkKeyOnce.Do(func() {
+		if secret := config.Get().ViewLinkSecret; secret != "" {
+			viewLinkKey = []byte(secret)
+			return
+		}
+		viewLinkKey = make([]byte, 32)
+		if _, err := rand.Read(viewLinkKey); err != nil {
+			panic(fmt.Sprintf("generating the view link key: %v", err))
+		}
+		utils.GetLogger().Info("VIEW_LINK_SECRET is not set; view links stop working when the server restarts")
+	})
+	return viewLinkKey
+}
+
+// signViewLink returns the signature of a view link of filename that expires at expires
+func signViewLink(filename string, expires int64) string {
+	mac := hmac.New(sha256.New, viewLinkSecret())
+	mac.Write([]byte(strconv.FormatInt(expires, 10) + "." + filename))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// NewViewLink returns a signed link to view filename that expires after VIEW_LINK_TTL
+func NewViewLink(filename string) ViewLink {
+	expiresAt := time.Now().Add(config.Get().ViewLinkTTL).Truncate(time.Second)
+	expires := expiresAt.Unix()
+	query := url.Values{}
+	query.Set(ViewLinkExpiresParam, strconv.FormatInt(expires, 10))
+	query.Set(ViewLinkSignatureParam, signViewLink(filename, expires))
+	return ViewLink{
+		URL:       "/api/v1/view/" + url.PathEscape(filename) + "?" + query.Encode(),
+		ExpiresAt: expiresAt.UTC(),
+	}
+}
+
+// ValidViewLink reports whether signature signs a view link of filename that has not expired
+func ValidViewLink(filename, expires, signature string) bool {
+	if signature == "" {
+		return false
+	}
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > expiresAt {
+		return false
+	}
+	return hmac.Equal([]byte(signature), []byte(signViewLink(filename, expiresAt)))
+}
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidViewLink(t *testing.T) {
+	link := NewViewLink("validation_result_20240101_120000.html")
+	parsed, err := url.Parse(link.URL)
+	if err != nil {
+		t.Fatalf("NewViewLink() URL %q: %v", link.URL, err)
+	}
+	if !strings.HasPrefix(parsed.Path, "/api/v1/view/") {
+		t.Fatalf("NewViewLink() URL = %q, want a /api/v1/view/ link", link.URL)
+	}
+	expires := parsed.Query().Get(ViewLinkExpiresParam)
+	signature := parsed.Query().Get(ViewLinkSignatureParam)
+	if expires != strconv.FormatInt(link.ExpiresAt.Unix(), 10) {
+		t.Errorf("expires = %s, want %d", expires, link.ExpiresAt.Unix())
+	}
+	past := strconv.FormatInt(time.Now().Add(-time.Minute).Unix(), 10)
+
+	tests := []struct {
+		name      string
+		filename  string
+		expires   string
+		signature string
+		want      bool
+	}{
+		{name: "signed", filename: "validation_result_20240101_120000.html", expires: expires, signature: signature, want: true},
+		{name: "other file", filename: "comparison_result_20240101_120000.html", expires: expires, signature: signature},
+		{name: "extended expiry", filename: "validation_result_20240101_120000.html", expires: expires + "0", signature: signature},
+		{name: "no signature", filename: "validation_result_20240101_120000.html", expires: expires},
+		{name: "no expiry", filename: "validation_result_20240101_120000.html", signature: signature},
+		{
+			name:      "expired",
+			filename:  "validation_result_20240101_120000.html",
+			expires:   past,
+			signature: signViewLink("validation_result_20240101_120000.html", mustParseInt(t, past)),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ValidViewLink(tt.filename, tt.expires, tt.signature); got != tt.want {
+				t.Errorf("ValidViewLink(%q, %q, %q) = %t, want %t", tt.filename, tt.expires, tt.signature, got, tt.want)
+			}
+		})
+	}
+}
+
+func mustParseInt(t *testing.T, value string) int64 {
+	t.Helper()
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
@@ -0,0 +1,417 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"ness-to-odoo-golang-validation-api-tool/config"
+	"ness-to-odoo-golang-validation-api-tool/store"
+	"ness-to-odoo-golang-validation-api-tool/utils"
+)
+
+// runWebhooksBucket keeps the webhook delivery log of every run
+const runWebhooksBucket = "run_webhooks"
+
+// Webhook events
+const (
+	WebhookEventCompleted = "validation.completed"
+	WebhookEventFailed    = "validation.failed"
+)
+
+// Webhook delivery statuses
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+// Webhook request headers
+const (
+	WebhookSignatureHeader = "X-Webhook-Signature"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookDeliveryHeader  = "X-Webhook-Delivery"
+)
+
+// WebhookPayload is the JSON body posted to the callback URL when a run finishes or fails
+type WebhookPayload struct {
+	Event      string             `json:"event"`
+	RunID      string             `json:"runId"`
+	Status     string             `json:"status"`
+	StartedAt  time.Time          `json:"startedAt"`
+	FinishedAt time.Time          `json:"finishedAt"`
+	Summary    *ValidationSummary `json:"summary,omitempty"`
+	// Sources is the summary of an N-way comparison, sent instead of Summary
+	Sources *MultiSourceSummary `json:"sources,omitempty"`
+	// DownloadURL is where the report can be downloaded, absolute when PUBLIC_BASE_URL is set
+	DownloadURL string `json:"downloadUrl,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// WebhookDelivery is the log of a webhook sent for a run
+type WebhookDelivery struct {
+	ID        string           `json:"id"`
+	Event     string           `json:"event"`
+	URL       string           `json:"url"`
+	Status    string           `json:"status"`
+	CreatedAt time.Time        `json:"createdAt"`
+	Attempts  []WebhookAttempt `json:"attempts"`
+}
+
+// WebhookAttempt is a single request of a webhook delivery
+type WebhookAttempt struct {
+	At         time.Time `json:"at"`
+	StatusCode int       `json:"statusCode,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	DurationMs int64     `json:"durationMs"`
+}
+
+var (
+	// pendingWebhooks tracks the deliveries still being sent, so shutdown can wait for them
+	pendingWebhooks sync.WaitGroup
+	// webhookLogMu serializes the updates of the delivery logs
+	webhookLogMu sync.Mutex
+	// webhookClient sends the webhook requests; each request has its own deadline. It connects
+	// directly, never through a proxy, so that the address checks apply to the callback itself.
+	webhookClient = &http.Client{Transport: &http.Transport{
+		DialContext:         dialWebhook,
+		ForceAttemptHTTP2:   true,
+		MaxIdleConns:        10,
+		IdleConnTimeout:     90 * time.Second,
+		TLSHandshakeTimeout: 10 * time.Second,
+	}}
+)
+
+// ParseCallbackURL checks a callback URL option: empty for none, or an absolute http(s) URL.
+// Callbacks are refused when WEBHOOK_SECRET is not set, since receivers could not verify
+// them, and so are hosts outside WEBHOOK_ALLOWED_HOSTS, or local and private hosts when no
+// allowlist is set.
+func ParseCallbackURL(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", nil
+	}
+	parsed, err := url.Parse(value)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return "", fmt.Errorf("callback URL must be an absolute http or https URL, got %q", value)
+	}
+	if config.Get().WebhookSecret == "" {
+		return "", errors.New("callback URLs are disabled: the server has no WEBHOOK_SECRET to sign webhooks with")
+	}
+	if err := checkWebhookHost(parsed.Hostname()); err != nil {
+		return "", fmt.Errorf("callback URL %q is not allowed: %w", value, err)
+	}
+	return value, nil
+}
+
+// webhookHostAllowed reports whether a host is in WEBHOOK_ALLOWED_HOSTS, where "*.corp.vn"
+// matches the subdomains of corp.vn. Without an allowlist every host is allowed.
+func webhookHostAllowed(host string) (allowed, listed bool) {
+	allowlist := strings.TrimSpace(config.Get().WebhookAllowedHosts)
+	if allowlist == "" {
+		return true, false
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, pattern := range strings.Split(allowlist, ",") {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if pattern == host || strings.HasPrefix(pattern, "*.") && strings.HasSuffix(host, pattern[1:]) {
+			return true, true
+		}
+	}
+	return false, false
+}
+
+// checkWebhookHost refuses hosts outside the allowlist, and local hosts and private addresses
+// unless they are listed. Host names are checked again on their resolved addresses when the
+// webhook is sent.
+func checkWebhookHost(host string) error {
+	allowed, listed := webhookHostAllowed(host)
+	if !allowed {
+		return fmt.Errorf("host %s is not in WEBHOOK_ALLOWED_HOSTS", host)
+	}
+	if listed {
+		return nil
+	}
+	lower := strings.ToLower(strings.TrimSuffix(host, "."))
+	if lower == "localhost" || strings.HasSuffix(lower, ".localhost") {
+		return fmt.Errorf("host %s is local", host)
+	}
+	if ip := net.ParseIP(host); ip != nil && privateWebhookAddress(ip) {
+		return fmt.Errorf("address %s is private", host)
+	}
+	return nil
+}
+
+// cgnatRange is the shared address space of carrier-grade NAT, 100.64.0.0/10
+var cgnatRange = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// privateWebhookAddress reports whether webhooks to an address could reach the server itself
+// or its private network: loopback, private, link-local (including cloud metadata services),
+// unspecified, multicast and carrier-grade NAT addresses
+func privateWebhookAddress(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() || cgnatRange.Contains(ip)
+}
+
+// dialWebhook connects to a callback host, refusing private addresses unless the host is in
+// WEBHOOK_ALLOWED_HOSTS. The check is made on the address actually dialed, so a public name
+// that resolves to a private address, or a redirect to one, is refused too.
+func dialWebhook(ctx context.Context, network, address string) (net.Conn, error) {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	if allowed, listed := webhookHostAllowed(host); !allowed {
+		return nil, fmt.Errorf("webhook host %s is not in WEBHOOK_ALLOWED_HOSTS", host)
+	} else if !listed {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || privateWebhookAddress(ip) {
+				return fmt.Errorf("webhook address %s is private", host)
+			}
+			return nil
+		}
+	}
+	return dialer.DialContext(ctx, network, address)
+}
+
+// CallbackURLForAPIKey returns the callback URL configured for an API key in API_KEY_WEBHOOKS,
+// or an empty string. Configured callbacks are ignored when WEBHOOK_SECRET is not set.
+func CallbackURLForAPIKey(apiKey string) string {
+	if apiKey == "" {
+		return ""
+	}
+	if config.Get().WebhookSecret == "" {
+		if config.Get().APIKeyWebhooks != "" {
+			utils.GetLogger().Warn("Ignoring API_KEY_WEBHOOKS: webhooks need a WEBHOOK_SECRET")
+		}
+		return ""
+	}
+	for _, pair := range strings.Split(config.Get().APIKeyWebhooks, ",") {
+		key, callbackURL, ok := strings.Cut(pair, "=")
+		if ok && strings.TrimSpace(key) == apiKey {
+			return strings.TrimSpace(callbackURL)
+		}
+	}
+	return ""
+}
+
+// notifyRun sends the webhook of a finished run in the background. downloadURL is the report
+// URL of a completed run that was not kept in the history.
+func notifyRun(run *Run, downloadURL string) {
+	callbackURL := run.Options.CallbackURL
+	if callbackURL == "" || config.Get().WebhookSecret == "" {
+		return
+	}
+
+	payload := WebhookPayload{
+		Event:      WebhookEventCompleted,
+		RunID:      run.ID,
+		Status:     run.Status,
+		StartedAt:  run.StartedAt,
+		FinishedAt: run.FinishedAt,
+	}
+	if run.Status == RunStatusCompleted {
+		if run.Sources != nil {
+			payload.Sources = run.Sources
+		} else {
+			summary := run.Summary
+			payload.Summary = &summary
+		}
+		if run.ReportURL != "" {
+			downloadURL = run.ReportURL
+		}
+		if downloadURL != "" {
+			payload.DownloadURL = strings.TrimSuffix(config.Get().PublicBaseURL, "/") + downloadURL
+		}
+	} else {
+		payload.Event = WebhookEventFailed
+		payload.Error = run.Error
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		utils.GetLogger().Error("Failed to encode webhook of run %s: %v", run.ID, err)
+		return
+	}
+	delivery := WebhookDelivery{
+		ID:        newDeliveryID(),
+		Event:     payload.Event,
+		URL:       callbackURL,
+		Status:    DeliveryPending,
+		CreatedAt: time.Now(),
+		Attempts:  make([]WebhookAttempt, 0),
+	}
+	saveWebhookDelivery(run.ID, delivery)
+
+	pendingWebhooks.Add(1)
+	go func() {
+		defer pendingWebhooks.Done()
+		deliverWebhook(run.ID, delivery, body)
+	}()
+}
+
+// deliverWebhook posts a payload until the callback answers with a 2xx status or the attempts
+// run out, waiting WEBHOOK_RETRY_BACKOFF before the first retry and twice as long before each
+// following one. Every attempt is added to the delivery log.
+func deliverWebhook(runID string, delivery WebhookDelivery, body []byte) {
+	logger := utils.GetLogger()
+	cfg := config.Get()
+	maxAttempts := max(cfg.WebhookMaxAttempts, 1)
+	backoff := cfg.WebhookRetryBackoff
+
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		result := sendWebhook(delivery, body)
+		delivery.Attempts = append(delivery.Attempts, result)
+		if result.Error == "" {
+			delivery.Status = DeliveryDelivered
+			saveWebhookDelivery(runID, delivery)
+			logger.Info("Delivered %s webhook of run %s to %s (attempt %d)", delivery.Event, runID, delivery.URL, attempt)
+			return
+		}
+
+		logger.Warn("Webhook of run %s to %s failed (attempt %d of %d): %s", runID, delivery.URL, attempt, maxAttempts, result.Error)
+		if attempt == maxAttempts {
+			break
+		}
+		saveWebhookDelivery(runID, delivery)
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+
+	delivery.Status = DeliveryFailed
+	saveWebhookDelivery(runID, delivery)
+	logger.Error("Giving up on %s webhook of run %s to %s after %d attempts", delivery.Event, runID, delivery.URL, maxAttempts)
+}
+
+// sendWebhook makes a single signed webhook request
+func sendWebhook(delivery WebhookDelivery, body []byte) (attempt WebhookAttempt) {
+	ctx, cancel := context.WithTimeout(context.Background(), config.Get().WebhookTimeout)
+	defer cancel()
+
+	attempt.At = time.Now()
+	defer func() { attempt.DurationMs = time.Since(attempt.At).Milliseconds() }()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(body))
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	// Every attempt is signed with its own timestamp, so receivers can refuse replayed requests
+	timestamp := strconv.FormatInt(attempt.At.Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, delivery.Event)
+	req.Header.Set(WebhookDeliveryHeader, delivery.ID)
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(config.Get().WebhookSecret, timestamp, body))
+
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	defer resp.Body.Close()
+	// Drain a little of the body so the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	attempt.StatusCode = resp.StatusCode
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		attempt.Error = fmt.Sprintf("unexpected status %s", resp.Status)
+	}
+	return attempt
+}
+
+// SignWebhookPayload returns the signature header value of a payload: "sha256=" followed by
+// the hex-encoded HMAC-SHA256 of the timestamp header value, a dot and the body, keyed with
+// the secret
+func SignWebhookPayload(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// newDeliveryID returns a random delivery ID
+func newDeliveryID() string {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return fmt.Sprintf("%016x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(id)
+}
+
+// saveWebhookDelivery adds or replaces a delivery in the log of a run
+func saveWebhookDelivery(runID string, delivery WebhookDelivery) {
+	db := store.Get()
+	if db == nil {
+		return
+	}
+	webhookLogMu.Lock()
+	defer webhookLogMu.Unlock()
+
+	deliveries := make([]WebhookDelivery, 0, 1)
+	if err := db.Load(runWebhooksBucket, runID, &deliveries); err != nil && !errors.Is(err, store.ErrNotFound) {
+		utils.GetLogger().Warn("Failed to load webhook log of run %s: %v", runID, err)
+		return
+	}
+	replaced := false
+	for i := range deliveries {
+		if deliveries[i].ID == delivery.ID {
+			deliveries[i] = delivery
+			replaced = true
+		}
+	}
+	if !replaced {
+		deliveries = append(deliveries, delivery)
+	}
+	if err := db.Put(store.Record{Bucket: runWebhooksBucket, Key: runID, Value: deliveries}); err != nil {
+		utils.GetLogger().Warn("Failed to save webhook log of run %s: %v", runID, err)
+	}
+}
+
+// GetRunWebhooks returns the webhook delivery log of a stored run
+func GetRunWebhooks(id string) ([]WebhookDelivery, error) {
+	if _, err := GetRun(id); err != nil {
+		return nil, err
+	}
+	deliveries := make([]WebhookDelivery, 0)
+	if err := store.Get().Load(runWebhooksBucket, id, &deliveries); err != nil && !errors.Is(err, store.ErrNotFound) {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// WaitForWebhooks blocks until the pending webhook deliveries are done or ctx is done
+func WaitForWebhooks(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		pendingWebhooks.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"net"
+	"testing"
+
+	"ness-to-odoo-golang-validation-api-tool/config"
+)
+
+// withWebhookConfig sets the webhook secret and allowlist for the duration of a test
+func withWebhookConfig(t *testing.T, secret, allowedHosts string) {
+	t.Helper()
+	cfg := config.Get()
+	previousSecret, previousHosts := cfg.WebhookSecret, cfg.WebhookAllowedHosts
+	cfg.WebhookSecret, cfg.WebhookAllowedHosts = secret, allowedHosts
+	t.Cleanup(func() { cfg.WebhookSecret, cfg.WebhookAllowedHosts = previousSecret, previousHosts })
+}
+
+func TestParseCallbackURL(t *testing.T) {
+	tests := []struct {
+		name         string
+		secret       string
+		allowedHosts string
+		url          string
+		wantErr      bool
+	}{
+		{name: "none", url: ""},
+		{name: "public host", secret: "s", url: "https://hooks.example.com/done"},
+		{name: "no secret", url: "https://hooks.example.com/done", wantErr: true},
+		{name: "not http", secret: "s", url: "ftp://hooks.example.com/done", wantErr: true},
+		{name: "relative", secret: "s", url: "/done", wantErr: true},
+		{name: "localhost", secret: "s", url: "http://localhost:8080/done", wantErr: true},
+		{name: "loopback", secret: "s", url: "http://127.0.0.1/done", wantErr: true},
+		{name: "private", secret: "s", url: "http://10.1.2.3/done", wantErr: true},
+		{name: "cloud metadata", secret: "s", url: "http://169.254.169.254/latest/meta-data", wantErr: true},
+		{name: "ipv6 loopback", secret: "s", url: "http://[::1]/done", wantErr: true},
+		{name: "ipv4-mapped private", secret: "s", url: "http://[::ffff:192.168.1.1]/done", wantErr: true},
+		{name: "allowlisted host", secret: "s", allowedHosts: "hooks.example.com", url: "https://hooks.example.com/done"},
+		{name: "allowlisted subdomain", secret: "s", allowedHosts: "*.corp.vn", url: "https://erp.corp.vn/done"},
+		{name: "wildcard does not match the domain", secret: "s", allowedHosts: "*.corp.vn", url: "https://corp.vn/done", wantErr: true},
+		{name: "host outside the allowlist", secret: "s", allowedHosts: "hooks.example.com", url: "https://evil.example.net/done", wantErr: true},
+		{name: "allowlisted private address", secret: "s", allowedHosts: "10.1.2.3", url: "http://10.1.2.3/done"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withWebhookConfig(t, tt.secret, tt.allowedHosts)
+			got, err := ParseCallbackURL(tt.url)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseCallbackURL(%q) error = %v, wantErr %v", tt.url, err, tt.wantErr)
+			}
+			if err == nil && got != tt.url {
+				t.Errorf("ParseCallbackURL(%q) = %q", tt.url, got)
+			}
+		})
+	}
+}
+
+func TestPrivateWebhookAddress(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{ip: "93.184.216.34", want: false},
+		{ip: "2606:2800:220:1::248", want: false},
+		{ip: "127.0.0.1", want: true},
+		{ip: "0.0.0.0", want: true},
+		{ip: "172.16.0.1", want: true},
+		{ip: "192.168.0.1", want: true},
+		{ip: "100.64.0.1", want: true},
+		{ip: "169.254.169.254", want: true},
+		{ip: "fe80::1", want: true},
+		{ip: "fd00::1", want: true},
+		{ip: "224.0.0.1", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			if got := privateWebhookAddress(net.ParseIP(tt.ip)); got != tt.want {
+				t.Errorf("privateWebhookAddress(%s) = %t, want %t", tt.ip, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSignWebhookPayload(t *testing.T) {
+	body := []byte(`{"event":"validation.completed"}`)
+	signature := SignWebhookPayload("secret", "1700000000", body)
+	if signature != SignWebhookPayload("secret", "1700000000", body) {
+		t.Error("SignWebhookPayload() is not deterministic")
+	}
+	if signature == SignWebhookPayload("secret", "1700000001", body) {
+		t.Error("SignWebhookPayload() does not cover the timestamp")
+	}
+	if signature == SignWebhookPayload("other", "1700000000", body) {
+		t.Error("SignWebhookPayload() does not depend on the secret")
+	}
+}
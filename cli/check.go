@@ -0,0 +1,140 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"ness-to-odoo-golang-validation-api-tool/api/services"
+)
+
+// checkOutput is the JSON summary printed by the check command
+type checkOutput struct {
+	File               string                `json:"file"`
+	Inputs             []services.InputFile  `json:"inputs"`
+	ParseErrors        []services.ParseError `json:"parseErrors"`
+	Summary            services.CheckSummary `json:"summary"`
+	InvalidEntries     []services.EmailEntry `json:"invalidEntries,omitempty"`
+	ThresholdsExceeded []string              `json:"thresholdsExceeded"`
+}
+
+// runCheck implements the check command
+func runCheck(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("check", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: check [flags] <file>")
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "Validates the emails of a single input file.")
+		fmt.Fprintln(stderr)
+		fs.PrintDefaults()
+	}
+
+	var common commonFlags
+	common.register(fs)
+
+	var extract extractFlags
+	extract.register(fs, "", "")
+	var lenient lenientFlags
+	lenient.register(fs)
+
+	var listInvalid bool
+	fs.BoolVar(&listInvalid, "list-invalid", false, "Include the invalid emails in the output")
+
+	var maxInvalid, maxDuplicates, maxDisposable int
+	var maxInvalidRate float64
+	fs.IntVar(&maxInvalid, "max-invalid", disabled, "Fail when more emails than this are invalid (-1 disables)")
+	fs.Float64Var(&maxInvalidRate, "max-invalid-rate", disabled, "Fail when the percentage of invalid emails exceeds this (-1 disables)")
+	fs.IntVar(&maxDuplicates, "max-duplicates", disabled, "Fail when more emails than this are duplicates (-1 disables)")
+	fs.IntVar(&maxDisposable, "max-disposable", disabled, "Fail when more emails than this are disposable (-1 disables)")
+
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		if err == flag.ErrHelp {
+			return ExitOK
+		}
+		return ExitError
+	}
+	if len(positional) != 1 {
+		fmt.Fprintln(stderr, "check requires exactly one input file")
+		fs.Usage()
+		return ExitError
+	}
+	if err := common.validate(); err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitError
+	}
+	opts, err := extract.options(extractFlags{})
+	if err == nil {
+		err = lenient.apply(&opts)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitError
+	}
+
+	ctx, cancel := common.setup(stderr)
+	defer cancel()
+
+	result, err := services.CheckFile(ctx, positional[0], opts)
+	if err != nil {
+		fmt.Fprintf(stderr, "check failed: %v\n", err)
+		return ExitError
+	}
+
+	summary := result.Summary
+	invalidRate := 0.0
+	if summary.TotalEmails > 0 {
+		invalidRate = float64(summary.InvalidEmails) * 100 / float64(summary.TotalEmails)
+	}
+
+	exceeded := violations([]threshold{
+		{"invalid emails", float64(summary.InvalidEmails), float64(maxInvalid)},
+		{"invalid email rate (%)", invalidRate, maxInvalidRate},
+		{"duplicate emails", float64(summary.DuplicateEmails), float64(maxDuplicates)},
+		{"disposable emails", float64(summary.DisposableEmails), float64(maxDisposable)},
+	})
+
+	if common.summaryFormat == "json" {
+		output := checkOutput{
+			File:               positional[0],
+			Inputs:             result.Inputs,
+			ParseErrors:        result.ParseErrors,
+			Summary:            summary,
+			ThresholdsExceeded: exceeded,
+		}
+		if output.ThresholdsExceeded == nil {
+			output.ThresholdsExceeded = []string{}
+		}
+		if listInvalid {
+			output.InvalidEntries = result.InvalidEntries
+		}
+		if err := writeJSON(stdout, output); err != nil {
+			fmt.Fprintf(stderr, "failed to write summary: %v\n", err)
+			return ExitError
+		}
+		return exitCode(exceeded)
+	}
+
+	rows := append([]summaryRow{{"File", positional[0]}}, dialectRows(result.Inputs)...)
+	rows = append(rows,
+		summaryRow{"Total emails", summary.TotalEmails},
+		summaryRow{"Valid emails", summary.ValidEmails},
+		summaryRow{"Invalid emails", fmt.Sprintf("%d (%.2f%%)", summary.InvalidEmails, invalidRate)},
+		summaryRow{"Duplicate emails", summary.DuplicateEmails},
+		summaryRow{"Disposable emails", summary.DisposableEmails},
+		summaryRow{"Skipped rows", summary.SkippedRows},
+		summaryRow{"Parse errors", summary.ParseErrors},
+		summaryRow{"Processing time", fmt.Sprintf("%.2f s", summary.ProcessingTimeSeconds)},
+	)
+	writeTextSummary(stdout, "Check summary", rows, exceeded)
+	writeParseErrors(stdout, result.ParseErrors)
+
+	if listInvalid && len(result.InvalidEntries) > 0 {
+		fmt.Fprintln(stdout, "Invalid emails:")
+		for _, entry := range result.InvalidEntries {
+			fmt.Fprintf(stdout, "  %s\t%s\t(%s)\n", entry.Email, entry.Reason, entry.Location)
+		}
+	}
+	return exitCode(exceeded)
+}
@@ -0,0 +1,317 @@
+// Package cli implements the command-line mode used to run validations from scripts
+// and cron jobs without starting the HTTP server.
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"ness-to-odoo-golang-validation-api-tool/api/services"
+	"ness-to-odoo-golang-validation-api-tool/config"
+	"ness-to-odoo-golang-validation-api-tool/utils"
+)
+
+// Exit codes returned by Run
+const (
+	ExitOK                = 0 // Command succeeded and all thresholds were met
+	ExitThresholdExceeded = 1 // Command succeeded but at least one threshold was exceeded
+	ExitError             = 2 // Invalid usage or the command failed
+)
+
+// disabled is the threshold value that turns a threshold check off
+const disabled = -1
+
+// historyLockTimeout is how long a command waits for the history database lock
+const historyLockTimeout = time.Second
+
+// command describes a subcommand
+type command struct {
+	name        string
+	description string
+	run         func(args []string, stdout, stderr io.Writer) int
+}
+
+var commands = []command{
+	{"validate", "Compare the emails of two files and write a report", runValidate},
+	{"check", "Validate the emails of a single file", runCheck},
+}
+
+// Run executes the subcommand named by args[0] and returns the process exit code
+func Run(args []string) int {
+	return run(args, os.Stdout, os.Stderr)
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		printUsage(stderr)
+		if len(args) == 0 {
+			return ExitError
+		}
+		return ExitOK
+	}
+
+	for _, cmd := range commands {
+		if cmd.name == args[0] {
+			return cmd.run(args[1:], stdout, stderr)
+		}
+	}
+
+	fmt.Fprintf(stderr, "unknown command %q\n\n", args[0])
+	printUsage(stderr)
+	return ExitError
+}
+
+// printUsage prints the list of subcommands
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage:")
+	fmt.Fprintln(w, "  ness-to-odoo-golang-validation-api-tool [serve]          Start the HTTP server")
+	fmt.Fprintln(w, "  ness-to-odoo-golang-validation-api-tool <command> [flags] Run a command")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-10s %s\n", cmd.name, cmd.description)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run '<command> -h' for the flags of a command.")
+	fmt.Fprintln(w, "Exit codes: 0 success, 1 threshold exceeded, 2 error.")
+}
+
+// commonFlags holds the flags shared by all subcommands
+type commonFlags struct {
+	summaryFormat string
+	timeout       time.Duration
+	verbose       bool
+	history       bool
+}
+
+// register adds the shared flags to fs
+func (f *commonFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.summaryFormat, "summary", "text", "Summary output format: text or json")
+	fs.DurationVar(&f.timeout, "timeout", 0, "Deadline for the whole command, e.g. 10m (0 disables it)")
+	fs.BoolVar(&f.verbose, "v", false, "Log progress to stderr")
+	fs.BoolVar(&f.history, "history", true, "Record the run in the validation history")
+}
+
+// validate checks the shared flag values
+func (f *commonFlags) validate() error {
+	if f.summaryFormat != "text" && f.summaryFormat != "json" {
+		return fmt.Errorf("-summary must be 'text' or 'json'")
+	}
+	return nil
+}
+
+// setup initializes logging and returns the command context, which is cancelled on
+// SIGINT/SIGTERM and when the timeout expires
+func (f *commonFlags) setup(stderr io.Writer) (context.Context, context.CancelFunc) {
+	level := utils.WARN
+	if f.verbose {
+		level = utils.DEBUG
+	}
+	utils.InitConsoleLogger(level, stderr, "2006-01-02 15:04:05.000")
+
+	// Record runs in the history when the database is not locked by a running server
+	if f.history && config.Get().HistoryEnabled {
+		if err := services.OpenHistory(services.HistoryPath(), historyLockTimeout); err != nil {
+			utils.GetLogger().Warn("Run will not be recorded in the history: %v", err)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	cleanup := func() {
+		stop()
+		services.CloseHistory()
+	}
+	if f.timeout <= 0 {
+		return ctx, cleanup
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, f.timeout)
+	return ctx, func() {
+		cancel()
+		cleanup()
+	}
+}
+
+// extractFlags holds the flags that select where emails are read from in an input file
+type extractFlags struct {
+	column    string
+	sheet     string
+	jsonPath  string
+	delimiter string
+	quote     string
+	encoding  string
+	hasHeader string
+	skipRows  string
+}
+
+// register adds the flags to fs. target names the files they apply to, e.g. " of both files".
+// With a prefix such as "first-" the flags apply to one file and override the unprefixed ones.
+func (f *extractFlags) register(fs *flag.FlagSet, prefix, target string) {
+	usage := func(name, what, values string) string {
+		if prefix == "" {
+			return what + target + ": " + values
+		}
+		return what + target + " (overrides -" + name + ")"
+	}
+	fs.StringVar(&f.column, prefix+"column", "", usage("column", "Email column", "header name, letter or 1-based index"))
+	fs.StringVar(&f.sheet, prefix+"sheet", "", usage("sheet", "Worksheet", "name or 1-based index (Excel files)"))
+	fs.StringVar(&f.jsonPath, prefix+"json-path", "", usage("json-path", "JSON path of the emails", "e.g. $.contacts[*].email (JSON and NDJSON files)"))
+	fs.StringVar(&f.delimiter, prefix+"delimiter", "", usage("delimiter", "Field delimiter", "a character or comma, semicolon, tab or pipe (default: detected)"))
+	fs.StringVar(&f.quote, prefix+"quote", "", usage("quote", "Quote style", `" or none (default: detected)`))
+	fs.StringVar(&f.encoding, prefix+"encoding", "", usage("encoding", "Character encoding", "e.g. utf-8, utf-16le or windows-1258 (default: detected)"))
+	fs.StringVar(&f.hasHeader, prefix+"has-header", "", usage("has-header", "Whether the first row is a header", "true, false or auto (default: auto, a header unless the row has an email)"))
+	fs.StringVar(&f.skipRows, prefix+"skip-rows", "", usage("skip-rows", "Leading rows to ignore before the header", "a number (CSV, TSV and Excel files)"))
+}
+
+// options returns the extract options of the flags, falling back to defaults for unset flags
+func (f *extractFlags) options(defaults extractFlags) (services.ExtractOptions, error) {
+	opts := services.ExtractOptions{
+		Column:    firstNonEmpty(f.column, defaults.column),
+		Sheet:     firstNonEmpty(f.sheet, defaults.sheet),
+		JSONPath:  firstNonEmpty(f.jsonPath, defaults.jsonPath),
+		Delimiter: firstNonEmpty(f.delimiter, defaults.delimiter),
+		Quote:     firstNonEmpty(f.quote, defaults.quote),
+		Encoding:  firstNonEmpty(f.encoding, defaults.encoding),
+	}
+	var err error
+	if opts.HasHeader, err = services.ParseHasHeader(firstNonEmpty(f.hasHeader, defaults.hasHeader)); err != nil {
+		return opts, err
+	}
+	if skipRows := firstNonEmpty(f.skipRows, defaults.skipRows); skipRows != "" {
+		if opts.SkipRows, err = strconv.Atoi(skipRows); err != nil {
+			return opts, fmt.Errorf("invalid skip-rows %q: must be a number", skipRows)
+		}
+	}
+	if err := services.ValidateTableOptions(opts); err != nil {
+		return opts, err
+	}
+	if err := services.ValidateDialectOptions(opts); err != nil {
+		return opts, err
+	}
+	return opts, services.ValidateJSONPath(opts.JSONPath)
+}
+
+// lenientFlags holds the flags of lenient parsing, which apply to every input file
+type lenientFlags struct {
+	lenient        bool
+	maxParseErrors int
+}
+
+// register adds the flags to fs
+func (f *lenientFlags) register(fs *flag.FlagSet) {
+	fs.BoolVar(&f.lenient, "lenient", false, "Skip malformed rows of CSV and TSV files and report them as parse errors instead of failing")
+	fs.IntVar(&f.maxParseErrors, "max-parse-errors", 0, "Malformed rows allowed per file with -lenient before failing (default: MAX_PARSE_ERRORS)")
+}
+
+// apply sets the lenient parsing options of opts
+func (f *lenientFlags) apply(opts *services.ExtractOptions) error {
+	opts.Lenient = f.lenient
+	opts.MaxParseErrors = f.maxParseErrors
+	return services.ValidateTableOptions(*opts)
+}
+
+// writeParseErrors lists the malformed rows skipped by lenient parsing
+func writeParseErrors(w io.Writer, parseErrors []services.ParseError) {
+	if len(parseErrors) == 0 {
+		return
+	}
+	fmt.Fprintln(w, "Parse errors:")
+	for _, parseError := range parseErrors {
+		fmt.Fprintf(w, "  %s, line %d\t%s\n", parseError.File, parseError.Line, parseError.Message)
+	}
+}
+
+// dialectRows returns a summary row with the dialect of every delimited input file
+func dialectRows(inputs []services.InputFile) []summaryRow {
+	var rows []summaryRow
+	for _, input := range inputs {
+		if input.Dialect == nil {
+			continue
+		}
+		label := "Dialect of " + input.File
+		if input.Role != "" {
+			label = "Dialect of " + input.Role + " file " + input.File
+		}
+		rows = append(rows, summaryRow{label, input.Dialect.String()})
+	}
+	return rows
+}
+
+// parseInterspersed parses flags that may appear before, between or after positional
+// arguments and returns the positional arguments
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+// threshold is a limit on a summary value
+type threshold struct {
+	name  string
+	value float64
+	limit float64
+}
+
+// violations returns a message for every enabled threshold whose value exceeds its limit
+func violations(thresholds []threshold) []string {
+	var messages []string
+	for _, t := range thresholds {
+		if t.limit == disabled || t.value <= t.limit {
+			continue
+		}
+		messages = append(messages, fmt.Sprintf("%s: %g exceeds the limit of %g", t.name, t.value, t.limit))
+	}
+	return messages
+}
+
+// summaryRow is a labelled value in the text summary
+type summaryRow struct {
+	label string
+	value interface{}
+}
+
+// writeTextSummary prints rows as an aligned table followed by any threshold violations
+func writeTextSummary(w io.Writer, title string, rows []summaryRow, exceeded []string) {
+	fmt.Fprintln(w, title)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, row := range rows {
+		fmt.Fprintf(tw, "  %s\t%v\n", row.label, row.value)
+	}
+	tw.Flush()
+
+	for _, message := range exceeded {
+		fmt.Fprintf(w, "THRESHOLD EXCEEDED: %s\n", message)
+	}
+}
+
+// writeJSON prints v as indented JSON
+func writeJSON(w io.Writer, v interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// exitCode returns the exit code for a successful command with the given violations
+func exitCode(exceeded []string) int {
+	if len(exceeded) > 0 {
+		return ExitThresholdExceeded
+	}
+	return ExitOK
+}
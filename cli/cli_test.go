@@ -0,0 +1,196 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// firstInput has 5 emails: 1 invalid, 1 duplicate and 1 disposable
+const firstInput = "email\na@example.com\nb@example.com\na@b@example.com\nx@mailinator.com\na@example.com\n"
+
+// secondInput has 1 email missing in firstInput; 3 emails of firstInput are missing in it
+const secondInput = "email\na@example.com\nc@example.com\n"
+
+// writeFixture writes content to a file named name in dir and returns its path
+func writeFixture(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// runCommand runs args with history recording off and returns the exit code and output
+func runCommand(t *testing.T, args ...string) (int, string, string) {
+	t.Helper()
+	if len(args) > 0 && (args[0] == "check" || args[0] == "validate") {
+		args = append([]string{args[0], "-history=false"}, args[1:]...)
+	}
+	var stdout, stderr bytes.Buffer
+	code := run(args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRunArguments(t *testing.T) {
+	dir := t.TempDir()
+	first := writeFixture(t, dir, "first.csv", firstInput)
+	second := writeFixture(t, dir, "second.csv", secondInput)
+
+	tests := []struct {
+		name       string
+		args       []string
+		want       int
+		wantStderr string
+	}{
+		{name: "no command", args: nil, want: ExitError, wantStderr: "Usage:"},
+		{name: "help", args: []string{"help"}, want: ExitOK, wantStderr: "Usage:"},
+		{name: "unknown command", args: []string{"compare"}, want: ExitError, wantStderr: `unknown command "compare"`},
+		{name: "check help", args: []string{"check", "-h"}, want: ExitOK, wantStderr: "Usage: check"},
+		{name: "check without file", args: []string{"check"}, want: ExitError, wantStderr: "check requires exactly one input file"},
+		{name: "check with two files", args: []string{"check", first, second}, want: ExitError, wantStderr: "check requires exactly one input file"},
+		{name: "check unknown flag", args: []string{"check", "-max-bounces", "1", first}, want: ExitError, wantStderr: "flag provided but not defined"},
+		{name: "check invalid threshold", args: []string{"check", "-max-invalid", "some", first}, want: ExitError, wantStderr: "invalid value"},
+		{name: "check invalid summary", args: []string{"check", "-summary", "xml", first}, want: ExitError, wantStderr: "-summary must be 'text' or 'json'"},
+		{name: "check missing file", args: []string{"check", filepath.Join(dir, "missing.csv")}, want: ExitError, wantStderr: "check failed"},
+		{name: "validate with one file", args: []string{"validate", first}, want: ExitError, wantStderr: "validate requires exactly two input files"},
+		{name: "validate invalid comparison", args: []string{"validate", "-comparison", "fuzzy", first, second}, want: ExitError, wantStderr: "fuzzy"},
+		{name: "validate unknown format", args: []string{"validate", "-output", filepath.Join(dir, "report.pdf"), first, second}, want: ExitError, wantStderr: "cannot infer the report format"},
+		{name: "validate two previous runs", args: []string{"validate", "-previous-run", "previous", "-previous-report", first, first, second}, want: ExitError, wantStderr: "cannot be used together"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _, stderr := runCommand(t, tt.args...)
+			if code != tt.want {
+				t.Errorf("run(%q) = %d, want %d; stderr: %s", tt.args, code, tt.want, stderr)
+			}
+			if !strings.Contains(stderr, tt.wantStderr) {
+				t.Errorf("run(%q) stderr = %q, want it to contain %q", tt.args, stderr, tt.wantStderr)
+			}
+		})
+	}
+}
+
+func TestCheckThresholds(t *testing.T) {
+	file := writeFixture(t, t.TempDir(), "first.csv", firstInput)
+
+	tests := []struct {
+		name         string
+		args         []string
+		want         int
+		wantExceeded []string
+	}{
+		{name: "no thresholds", want: ExitOK},
+		{name: "all disabled", args: []string{"-max-invalid", "-1", "-max-invalid-rate", "-1", "-max-duplicates", "-1", "-max-disposable", "-1"}, want: ExitOK},
+		{name: "invalid at the limit", args: []string{"-max-invalid", "1"}, want: ExitOK},
+		{name: "invalid over the limit", args: []string{"-max-invalid", "0"}, want: ExitThresholdExceeded,
+			wantExceeded: []string{"invalid emails: 1 exceeds the limit of 0"}},
+		{name: "invalid rate at the limit", args: []string{"-max-invalid-rate", "20"}, want: ExitOK},
+		{name: "invalid rate over the limit", args: []string{"-max-invalid-rate", "19.5"}, want: ExitThresholdExceeded,
+			wantExceeded: []string{"invalid email rate (%): 20 exceeds the limit of 19.5"}},
+		{name: "duplicates over the limit", args: []string{"-max-duplicates", "0"}, want: ExitThresholdExceeded,
+			wantExceeded: []string{"duplicate emails: 1 exceeds the limit of 0"}},
+		{name: "disposable over the limit", args: []string{"-max-disposable", "0"}, want: ExitThresholdExceeded,
+			wantExceeded: []string{"disposable emails: 1 exceeds the limit of 0"}},
+		{name: "several over the limit", args: []string{"-max-invalid", "0", "-max-duplicates", "-1", "-max-disposable", "0"}, want: ExitThresholdExceeded,
+			wantExceeded: []string{"invalid emails: 1 exceeds the limit of 0", "disposable emails: 1 exceeds the limit of 0"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append([]string{"check", "-summary", "json"}, tt.args...)
+			code, stdout, stderr := runCommand(t, append(args, file)...)
+			if code != tt.want {
+				t.Fatalf("check %q = %d, want %d; stderr: %s", tt.args, code, tt.want, stderr)
+			}
+
+			var output checkOutput
+			if err := json.Unmarshal([]byte(stdout), &output); err != nil {
+				t.Fatalf("check %q printed invalid JSON: %v\n%s", tt.args, err, stdout)
+			}
+			want := tt.wantExceeded
+			if want == nil {
+				want = []string{}
+			}
+			if !reflect.DeepEqual(output.ThresholdsExceeded, want) {
+				t.Errorf("check %q thresholdsExceeded = %q, want %q", tt.args, output.ThresholdsExceeded, want)
+			}
+			if output.Summary.TotalEmails != 5 || output.Summary.InvalidEmails != 1 {
+				t.Errorf("check %q summary = %+v, want 5 emails with 1 invalid", tt.args, output.Summary)
+			}
+		})
+	}
+}
+
+func TestValidateThresholds(t *testing.T) {
+	dir := t.TempDir()
+	first := writeFixture(t, dir, "first.csv", firstInput)
+	second := writeFixture(t, dir, "second.csv", secondInput)
+
+	tests := []struct {
+		name         string
+		args         []string
+		want         int
+		wantExceeded []string
+	}{
+		{name: "no thresholds", want: ExitOK},
+		{name: "all disabled", args: []string{"-max-missing-first", "-1", "-max-missing-second", "-1", "-max-invalid", "-1"}, want: ExitOK},
+		{name: "missing at the limit", args: []string{"-max-missing-first", "1", "-max-missing-second", "3"}, want: ExitOK},
+		{name: "missing in first over the limit", args: []string{"-max-missing-first", "0"}, want: ExitThresholdExceeded,
+			wantExceeded: []string{"emails missing in first file: 1 exceeds the limit of 0"}},
+		{name: "missing in second over the limit", args: []string{"-max-missing-second", "1"}, want: ExitThresholdExceeded,
+			wantExceeded: []string{"emails missing in second file: 3 exceeds the limit of 1"}},
+		{name: "invalid over the limit", args: []string{"-max-invalid", "0"}, want: ExitThresholdExceeded,
+			wantExceeded: []string{"invalid emails: 1 exceeds the limit of 0"}},
+		{name: "delta thresholds without a previous run", args: []string{"-max-no-longer-matching", "0", "-max-became-invalid", "0"}, want: ExitOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := filepath.Join(dir, strings.ReplaceAll(tt.name, " ", "_")+".csv")
+			args := append([]string{"validate", "-summary", "json", "-output", report}, tt.args...)
+			code, stdout, stderr := runCommand(t, append(args, first, second)...)
+			if code != tt.want {
+				t.Fatalf("validate %q = %d, want %d; stderr: %s", tt.args, code, tt.want, stderr)
+			}
+
+			var output validateOutput
+			if err := json.Unmarshal([]byte(stdout), &output); err != nil {
+				t.Fatalf("validate %q printed invalid JSON: %v\n%s", tt.args, err, stdout)
+			}
+			want := tt.wantExceeded
+			if want == nil {
+				want = []string{}
+			}
+			if !reflect.DeepEqual(output.ThresholdsExceeded, want) {
+				t.Errorf("validate %q thresholdsExceeded = %q, want %q", tt.args, output.ThresholdsExceeded, want)
+			}
+			if output.OutputFile != report {
+				t.Errorf("validate %q outputFile = %q, want %q", tt.args, output.OutputFile, report)
+			}
+			if _, err := os.Stat(report); err != nil {
+				t.Errorf("validate %q did not write the report: %v", tt.args, err)
+			}
+		})
+	}
+}
+
+func TestCheckTextSummary(t *testing.T) {
+	file := writeFixture(t, t.TempDir(), "first.csv", firstInput)
+
+	code, stdout, stderr := runCommand(t, "check", "-list-invalid", "-max-invalid", "0", file)
+	if code != ExitThresholdExceeded {
+		t.Fatalf("check = %d, want %d; stderr: %s", code, ExitThresholdExceeded, stderr)
+	}
+	for _, want := range []string{"Check summary", "THRESHOLD EXCEEDED: invalid emails: 1 exceeds the limit of 0", "Invalid emails:", "a@b@example.com"} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("check output does not contain %q:\n%s", want, stdout)
+		}
+	}
+}
@@ -0,0 +1,346 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"ness-to-odoo-golang-validation-api-tool/api/services"
+	"ness-to-odoo-golang-validation-api-tool/config"
+)
+
+// validateOutput is the JSON summary printed by the validate command
+type validateOutput struct {
+	FirstFile          string                     `json:"firstFile"`
+	SecondFile         string                     `json:"secondFile"`
+	OutputFile         string                     `json:"outputFile"`
+	RunID              string                     `json:"runId,omitempty"`
+	Inputs             []services.InputFile       `json:"inputs"`
+	ParseErrors        []services.ParseError      `json:"parseErrors"`
+	Summary            services.ValidationSummary `json:"summary"`
+	Delta              *deltaOutput               `json:"delta,omitempty"`
+	ThresholdsExceeded []string                   `json:"thresholdsExceeded"`
+}
+
+// deltaOutput is the delta part of the validate JSON summary
+type deltaOutput struct {
+	Previous   string                `json:"previous"`
+	OutputFile string                `json:"outputFile"`
+	Summary    services.DeltaSummary `json:"summary"`
+}
+
+// runValidate implements the validate command
+func runValidate(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("validate", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: validate [flags] <first-file> <second-file>")
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "Compares the emails of two input files and writes a report. Inputs can be CSV, TSV,")
+		fmt.Fprintln(stderr, "Excel, JSON, NDJSON or text files, or ZIP archives of those.")
+		fmt.Fprintln(stderr)
+		fs.PrintDefaults()
+	}
+
+	var common commonFlags
+	common.register(fs)
+
+	var both, first, second extractFlags
+	both.register(fs, "", " of both files")
+	first.register(fs, "first-", " of the first file")
+	second.register(fs, "second-", " of the second file")
+	var lenient lenientFlags
+	lenient.register(fs)
+
+	var outputFormat, outputPath string
+	fs.StringVar(&outputFormat, "format", "", "Report format: "+strings.Join(services.ReportFormats(), ", ")+" (default: from -output, else csv)")
+	fs.StringVar(&outputPath, "output", "", "Report path (default: a timestamped file in the temp directory)")
+	var odooMapping, comparison, matchKeysSpec, probableThreshold string
+	fs.StringVar(&comparison, "comparison", "", "Key the emails are compared on: "+comparisonNames()+" (default: normalized)")
+	fs.StringVar(&matchKeysSpec, "match-keys", "", "Secondary keys that pair records whose email changed, e.g. 'Full Name+Phone;Customer Code=ref'")
+	fs.StringVar(&probableThreshold, "probable-match-threshold", "", "Similarity from 0 to 1 from which unmatched emails are paired as probable matches; 0 disables (default: PROBABLE_MATCH_THRESHOLD)")
+	fs.StringVar(&odooMapping, "odoo-mapping", "", "Column mapping of the odoo-csv and odoo-xml formats, e.g. name=Name,phone=Phone (default: ODOO_FIELD_MAPPING)")
+
+	var previousRun, previousReport, deltaPath string
+	fs.StringVar(&previousRun, "previous-run", "", "Compare with this recorded run ID, or 'previous' for the most recent completed run of the same kind and comparison strategy")
+	fs.StringVar(&previousReport, "previous-report", "", "Compare with this previous CSV/Excel validation report")
+	fs.StringVar(&deltaPath, "delta-output", "", "Delta report path; .json, .csv or .xlsx (default: a timestamped JSON file in the temp directory)")
+
+	var maxMissingFirst, maxMissingSecond, maxInvalid, maxNoLongerMatching, maxBecameInvalid int
+	fs.IntVar(&maxMissingFirst, "max-missing-first", disabled, "Fail when more emails than this are missing in the first file (-1 disables)")
+	fs.IntVar(&maxMissingSecond, "max-missing-second", disabled, "Fail when more emails than this are missing in the second file (-1 disables)")
+	fs.IntVar(&maxInvalid, "max-invalid", disabled, "Fail when more emails than this are invalid across both files (-1 disables)")
+	fs.IntVar(&maxNoLongerMatching, "max-no-longer-matching", disabled, "Fail when more emails than this stopped matching since the previous run (-1 disables)")
+	fs.IntVar(&maxBecameInvalid, "max-became-invalid", disabled, "Fail when more emails than this became invalid since the previous run (-1 disables)")
+
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		if err == flag.ErrHelp {
+			return ExitOK
+		}
+		return ExitError
+	}
+	if len(positional) != 2 {
+		fmt.Fprintln(stderr, "validate requires exactly two input files")
+		fs.Usage()
+		return ExitError
+	}
+	if err := common.validate(); err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitError
+	}
+
+	outputFormat, err = resolveOutputFormat(outputFormat, outputPath)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitError
+	}
+	var mapping services.OdooMapping
+	if odooMapping != "" {
+		if mapping, err = services.ParseOdooMapping(odooMapping); err != nil {
+			fmt.Fprintln(stderr, err)
+			return ExitError
+		}
+	}
+	comparisonStrategy, err := services.ParseComparisonStrategy(comparison)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitError
+	}
+	matchKeys, err := services.ParseMatchKeys(matchKeysSpec)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitError
+	}
+	probableMatchThreshold, err := services.ParseProbableMatchThreshold(probableThreshold)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitError
+	}
+	if previousRun != "" && previousReport != "" {
+		fmt.Fprintln(stderr, "-previous-run and -previous-report cannot be used together")
+		return ExitError
+	}
+	deltaFormat, err := resolveDeltaFormat(deltaPath)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitError
+	}
+
+	firstOpts, err := first.options(both)
+	if err == nil {
+		err = lenient.apply(&firstOpts)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitError
+	}
+	secondOpts, err := second.options(both)
+	if err == nil {
+		err = lenient.apply(&secondOpts)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitError
+	}
+
+	ctx, cancel := common.setup(stderr)
+	defer cancel()
+
+	opts := services.ValidationOptions{
+		OutputFormat:           outputFormat,
+		OutputPath:             outputPath,
+		FirstFile:              firstOpts,
+		SecondFile:             secondOpts,
+		OdooMapping:            mapping,
+		Comparison:             comparisonStrategy,
+		MatchKeys:              matchKeys,
+		ProbableMatchThreshold: probableMatchThreshold,
+	}
+
+	result, err := services.ValidateEmails(ctx, positional[0], positional[1], opts)
+	if err != nil {
+		fmt.Fprintf(stderr, "validation failed: %v\n", err)
+		return ExitError
+	}
+
+	if outputPath == "" {
+		outputPath = filepath.Join(config.Get().TempDir, result.FileName)
+	}
+
+	// Compare with the previous run when requested
+	var delta *services.DeltaResult
+	if previousRun != "" || previousReport != "" {
+		var previous services.Snapshot
+		if previousReport != "" {
+			previous, err = services.ReportSnapshot(ctx, previousReport)
+		} else {
+			previous, err = services.PreviousRunSnapshot(result.RunID, previousRun)
+		}
+		if err == nil {
+			opts := services.DeltaOptions{OutputFormat: deltaFormat, OutputPath: deltaPath}
+			delta, err = services.CompareSnapshots(ctx, previous, services.ResultSnapshot(result), opts)
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "delta failed: %v\n", err)
+			return ExitError
+		}
+		if deltaPath == "" {
+			deltaPath = filepath.Join(config.Get().TempDir, delta.FileName)
+		}
+	}
+
+	summary := result.Summary
+	thresholds := []threshold{
+		{"emails missing in first file", float64(summary.MissingInFirstCount), float64(maxMissingFirst)},
+		{"emails missing in second file", float64(summary.MissingInSecondCount), float64(maxMissingSecond)},
+		{"invalid emails", float64(invalidCount(summary)), float64(maxInvalid)},
+	}
+	if delta != nil {
+		thresholds = append(thresholds,
+			threshold{"emails no longer matching", float64(delta.Summary.NoLongerMatching), float64(maxNoLongerMatching)},
+			threshold{"emails that became invalid", float64(delta.Summary.BecameInvalid), float64(maxBecameInvalid)},
+		)
+	}
+	exceeded := violations(thresholds)
+
+	if common.summaryFormat == "json" {
+		output := validateOutput{
+			FirstFile:          positional[0],
+			SecondFile:         positional[1],
+			OutputFile:         outputPath,
+			RunID:              result.RunID,
+			Inputs:             result.Inputs,
+			ParseErrors:        result.ParseErrors,
+			Summary:            summary,
+			ThresholdsExceeded: exceeded,
+		}
+		if delta != nil {
+			output.Delta = &deltaOutput{Previous: delta.Previous, OutputFile: deltaPath, Summary: delta.Summary}
+		}
+		if output.ThresholdsExceeded == nil {
+			output.ThresholdsExceeded = []string{}
+		}
+		if err := writeJSON(stdout, output); err != nil {
+			fmt.Fprintf(stderr, "failed to write summary: %v\n", err)
+			return ExitError
+		}
+		return exitCode(exceeded)
+	}
+
+	rows := []summaryRow{
+		{"First file", positional[0]},
+		{"Second file", positional[1]},
+	}
+	rows = append(rows, dialectRows(result.Inputs)...)
+	rows = append(rows, []summaryRow{
+		{"Total emails in first file", summary.TotalEmailsFirstFile},
+		{"Total emails in second file", summary.TotalEmailsSecondFile},
+		{"Valid emails in first file", summary.ValidEmailsFirstFile},
+		{"Valid emails in second file", summary.ValidEmailsSecondFile},
+		{"Matching emails", summary.MatchingCount},
+		{"Missing in first file", summary.MissingInFirstCount},
+		{"Missing in second file", summary.MissingInSecondCount},
+		{"Comparison strategy", summary.ComparisonStrategy},
+		{"Matched by other key", summary.KeyMatchCount},
+		{"Probable matches", summary.ProbableMatchCount},
+		{"Disposable emails", summary.DisposableEmailsCount},
+		{"Skipped rows in first file", summary.SkippedRowsFirstFile},
+		{"Skipped rows in second file", summary.SkippedRowsSecondFile},
+		{"Parse errors in first file", summary.ParseErrorsFirstFile},
+		{"Parse errors in second file", summary.ParseErrorsSecondFile},
+		{"Processing time", fmt.Sprintf("%.2f s", summary.ProcessingTimeSeconds)},
+		{"Report", outputPath},
+	}...)
+	if result.RunID != "" {
+		rows = append(rows, summaryRow{"Run ID", result.RunID})
+	}
+	if delta != nil {
+		rows = append(rows,
+			summaryRow{"Previous run", delta.Previous},
+			summaryRow{"Newly matching", delta.Summary.NewlyMatching},
+			summaryRow{"No longer matching", delta.Summary.NoLongerMatching},
+			summaryRow{"Became invalid", delta.Summary.BecameInvalid},
+			summaryRow{"Became valid", delta.Summary.BecameValid},
+			summaryRow{"Added since previous run", delta.Summary.Added},
+			summaryRow{"Removed since previous run", delta.Summary.Removed},
+			summaryRow{"Delta report", deltaPath},
+		)
+	}
+	writeTextSummary(stdout, "Validation summary", rows, exceeded)
+	writeParseErrors(stdout, result.ParseErrors)
+	return exitCode(exceeded)
+}
+
+// comparisonNames lists the comparison strategies for the flag help
+func comparisonNames() string {
+	var names []string
+	for _, strategy := range services.ComparisonStrategies() {
+		names = append(names, string(strategy))
+	}
+	return strings.Join(names, ", ")
+}
+
+// resolveOutputFormat returns the report format, inferring it from the output path when not given
+func resolveOutputFormat(format, outputPath string) (string, error) {
+	ext := filepath.Ext(outputPath)
+	if format == "" {
+		if ext == "" {
+			return "csv", nil
+		}
+		inferred, ok := services.ReportFormatForExtension(ext)
+		if !ok {
+			return "", fmt.Errorf("cannot infer the report format from %q; use -format", outputPath)
+		}
+		return inferred, nil
+	}
+
+	reporter, err := services.GetReporter(format)
+	if err != nil {
+		return "", fmt.Errorf("-format: %w", err)
+	}
+	// Compare through the inferred format, which also accepts .xls for Excel reports
+	if inferred, ok := services.ReportFormatForExtension(ext); ext != "" && (!ok || !sameExtension(inferred, reporter)) {
+		return "", fmt.Errorf("-output must have a %s extension for %s reports", reporter.Extension(), format)
+	}
+	return format, nil
+}
+
+// sameExtension reports whether the reports of format have the extension of reporter; formats
+// such as csv and odoo-csv share one
+func sameExtension(format string, reporter services.Reporter) bool {
+	other, err := services.GetReporter(format)
+	return err == nil && other.Extension() == reporter.Extension()
+}
+
+// resolveDeltaFormat returns the delta report format inferred from its path
+func resolveDeltaFormat(deltaPath string) (string, error) {
+	switch strings.ToLower(filepath.Ext(deltaPath)) {
+	case "", ".json":
+		return "json", nil
+	case ".csv":
+		return "csv", nil
+	case ".xlsx", ".xls":
+		return "excel", nil
+	default:
+		return "", fmt.Errorf("-delta-output must have a .json, .csv or .xlsx extension")
+	}
+}
+
+// invalidCount returns the number of invalid emails across both files
+func invalidCount(summary services.ValidationSummary) int {
+	return summary.TotalEmailsFirstFile - summary.ValidEmailsFirstFile +
+		summary.TotalEmailsSecondFile - summary.ValidEmailsSecondFile
+}
+
+// firstNonEmpty returns the first of values that is not empty
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,186 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Config holds the runtime settings of the API server
+type Config struct {
+	// Port is the address the HTTP server listens on, e.g. ":8080"
+	Port string
+	// GRPCPort is the address the gRPC server listens on, e.g. ":9090"; the server is off when empty
+	GRPCPort string
+	// APIKeys are the keys accepted in the X-API-Key header and x-api-key gRPC metadata,
+	// separated by commas; when empty the API does not require a key
+	APIKeys string
+	// TempDir holds uploaded files and generated reports
+	TempDir string
+	// LogDir holds the daily log files
+	LogDir string
+	// DataDir holds the history database and the reports of past runs
+	DataDir string
+	// HistoryEnabled turns on recording of validation runs in the history database
+	HistoryEnabled bool
+	// HistoryMaxAge is how long runs and their reports are kept (0 keeps them regardless of age)
+	HistoryMaxAge time.Duration
+	// HistoryMaxRuns is how many of the most recent runs are kept (0 keeps them regardless of count)
+	HistoryMaxRuns int
+	// ValidationTimeout is the server-wide deadline for a single validation request (0 disables it)
+	ValidationTimeout time.Duration
+	// MaxRequestTimeout caps the per-request deadline a client may ask for (0 means no cap)
+	MaxRequestTimeout time.Duration
+	// ShutdownTimeout is how long a graceful shutdown waits for running jobs before cancelling them
+	ShutdownTimeout time.Duration
+	// ShutdownDrainDelay is how long the server keeps serving after readiness starts failing, so
+	// that load balancers stop sending requests before the listener closes
+	ShutdownDrainDelay time.Duration
+	// MinFreeDiskMB is the free space the temp directory needs for the server to report ready
+	MinFreeDiskMB int
+	// DNSCheckEnabled turns on the resolver readiness check
+	DNSCheckEnabled bool
+	// DomainCheckEnabled makes validation resolve email domains, so unresolvable ones are invalid
+	DomainCheckEnabled bool
+	// DNSCheckHost is the host name resolved by the readiness check
+	DNSCheckHost string
+	// OdooFieldMapping is the default column mapping of the Odoo import files, e.g. "name=Name,phone=Phone"
+	OdooFieldMapping string
+	// MaxParseErrors is the default number of malformed rows a lenient CSV read skips before it fails
+	MaxParseErrors int
+	// ProbableMatchThreshold is the default similarity from which unmatched emails are reported
+	// as probable matches (0 disables probable matching)
+	ProbableMatchThreshold float64
+	// PublicBaseURL is the external address of the server, e.g. "https://validator.example.com",
+	// used to make the download URLs of webhook payloads absolute
+	PublicBaseURL string
+	// WebhookSecret is the key of the HMAC-SHA256 signature of webhook payloads
+	WebhookSecret string
+	// APIKeyWebhooks maps API keys to their default callback URL, e.g. "key1=https://a/hook,key2=https://b/hook"
+	APIKeyWebhooks string
+	// WebhookAllowedHosts restricts callback URLs to these hosts, e.g. "hooks.example.com,*.corp.vn".
+	// Listed hosts may also resolve to private addresses, which are refused otherwise.
+	WebhookAllowedHosts string
+	// WebhookMaxAttempts is how many times a webhook delivery is tried before it fails
+	WebhookMaxAttempts int
+	// WebhookRetryBackoff is the wait before the first retry of a webhook, doubled on every retry
+	WebhookRetryBackoff time.Duration
+	// WebhookTimeout is the deadline of a single webhook request
+	WebhookTimeout time.Duration
+	// ViewLinkSecret is the key of the signature of view links; a random key is used when empty
+	ViewLinkSecret string
+	// ViewLinkTTL is how long a signed view link stays valid
+	ViewLinkTTL time.Duration
+	// MaxBatchEmails is the largest number of emails a list validation request may contain
+	MaxBatchEmails int
+}
+
+var (
+	current  *Config
+	loadOnce sync.Once
+)
+
+// Load reads the configuration from environment variables, falling back to defaults
+func Load() *Config {
+	loadOnce.Do(func() {
+		current = &Config{
+			Port:                   getEnv("PORT", ":8080"),
+			GRPCPort:               getEnv("GRPC_PORT", ""),
+			APIKeys:                getEnv("API_KEYS", ""),
+			TempDir:                getEnv("TEMP_DIR", "./temp"),
+			LogDir:                 getEnv("LOG_DIR", "./logs"),
+			DataDir:                getEnv("DATA_DIR", "./data"),
+			HistoryEnabled:         getEnvBool("HISTORY_ENABLED", true),
+			HistoryMaxAge:          getEnvDuration("HISTORY_MAX_AGE", 90*24*time.Hour),
+			HistoryMaxRuns:         getEnvInt("HISTORY_MAX_RUNS", 1000),
+			ValidationTimeout:      getEnvDuration("VALIDATION_TIMEOUT", 10*time.Minute),
+			MaxRequestTimeout:      getEnvDuration("MAX_REQUEST_TIMEOUT", 30*time.Minute),
+			ShutdownTimeout:        getEnvDuration("SHUTDOWN_TIMEOUT", 30*time.Second),
+			ShutdownDrainDelay:     getEnvDuration("SHUTDOWN_DRAIN_DELAY", 5*time.Second),
+			MinFreeDiskMB:          getEnvInt("MIN_FREE_DISK_MB", 100),
+			DNSCheckEnabled:        getEnvBool("DNS_CHECK_ENABLED", false),
+			DNSCheckHost:           getEnv("DNS_CHECK_HOST", "example.com"),
+			DomainCheckEnabled:     getEnvBool("DOMAIN_CHECK_ENABLED", false),
+			OdooFieldMapping:       getEnv("ODOO_FIELD_MAPPING", "name=Name,phone=Phone,company_name=Company"),
+			MaxParseErrors:         getEnvInt("MAX_PARSE_ERRORS", 100),
+			ProbableMatchThreshold: getEnvFloat("PROBABLE_MATCH_THRESHOLD", 0),
+			PublicBaseURL:          getEnv("PUBLIC_BASE_URL", ""),
+			WebhookSecret:          getEnv("WEBHOOK_SECRET", ""),
+			APIKeyWebhooks:         getEnv("API_KEY_WEBHOOKS", ""),
+			WebhookAllowedHosts:    getEnv("WEBHOOK_ALLOWED_HOSTS", ""),
+			WebhookMaxAttempts:     getEnvInt("WEBHOOK_MAX_ATTEMPTS", 5),
+			WebhookRetryBackoff:    getEnvDuration("WEBHOOK_RETRY_BACKOFF", 2*time.Second),
+			WebhookTimeout:         getEnvDuration("WEBHOOK_TIMEOUT", 10*time.Second),
+			ViewLinkSecret:         getEnv("VIEW_LINK_SECRET", ""),
+			ViewLinkTTL:            getEnvDuration("VIEW_LINK_TTL", time.Hour),
+			MaxBatchEmails:         getEnvInt("MAX_BATCH_EMAILS", 10000),
+		}
+	})
+	return current
+}
+
+// Get returns the loaded configuration, loading it on first use
+func Get() *Config {
+	return Load()
+}
+
+// getEnv returns the value of an environment variable or a default value
+func getEnv(key, defaultValue string) string {
+	if value, ok := os.LookupEnv(key); ok && value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvDuration parses a duration environment variable such as "90s" or "5m".
+// A plain number is interpreted as seconds.
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return defaultValue
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return d
+	}
+	return defaultValue
+}
+
+// getEnvInt parses an integer environment variable
+func getEnvInt(key string, defaultValue int) int {
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return defaultValue
+	}
+	if n, err := strconv.Atoi(value); err == nil {
+		return n
+	}
+	return defaultValue
+}
+
+// getEnvFloat parses a floating-point environment variable such as "0.85"
+func getEnvFloat(key string, defaultValue float64) float64 {
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return defaultValue
+	}
+	if f, err := strconv.ParseFloat(value, 64); err == nil {
+		return f
+	}
+	return defaultValue
+}
+
+// getEnvBool parses a boolean environment variable such as "true", "1" or "false"
+func getEnvBool(key string, defaultValue bool) bool {
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return defaultValue
+	}
+	if b, err := strconv.ParseBool(value); err == nil {
+		return b
+	}
+	return defaultValue
+}
@@ -15,8 +15,194 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/compare-sources": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Upload two or more files and get a membership matrix showing which sources contain each email, compared on the key of the comparison strategy, with Venn-style counts for every combination of sources",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "emails"
+                ],
+                "summary": "Compare emails across N labelled sources",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "File containing emails (CSV, TSV, Excel, JSON, NDJSON, text or ZIP); repeat the field once per source",
+                        "name": "files",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Source label, repeated in the same order as files (default: file name without extension)",
+                        "name": "labels",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Email column per source, repeated in the same order as files: header name, letter or 1-based index",
+                        "name": "columns",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Worksheet per Excel source, repeated in the same order as files: name or 1-based index",
+                        "name": "sheets",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Path of the emails per JSON or NDJSON source, repeated in the same order as files, e.g. $.contacts[*].email",
+                        "name": "jsonPaths",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Field delimiter per CSV or TSV source, repeated in the same order as files: a character or comma, semicolon, tab or pipe (default: detected)",
+                        "name": "delimiters",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Quote style per CSV or TSV source, repeated in the same order as files: double or none (default: detected)",
+                        "name": "quotes",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Character encoding per CSV, TSV or text source, repeated in the same order as files, e.g. utf-8, utf-16le or windows-1258 (default: detected)",
+                        "name": "encodings",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Whether the first row of each CSV, TSV or Excel source (after skipped rows) is a header, repeated in the same order as files: true, false or auto (default: auto)",
+                        "name": "hasHeaders",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Leading rows of each CSV, TSV or Excel source to ignore before the header, repeated in the same order as files",
+                        "name": "skipRows",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Skip malformed rows of CSV and TSV sources and list them as parse errors instead of failing (default: false)",
+                        "name": "lenient",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Malformed rows allowed per source in lenient mode before the comparison fails (default: server configuration)",
+                        "name": "maxParseErrors",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Output format: csv, excel, json, ndjson, markdown, html, odoo-csv or odoo-xml (default: csv). The Odoo formats create the emails missing in the last source",
+                        "name": "outputFormat",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Column mapping of the odoo-csv and odoo-xml formats, read from every source but the last, e.g. name=Full Name,phone=Mobile (default: server configuration)",
+                        "name": "odooMapping",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Key the emails are compared on: raw, case-insensitive, normalized, domain or registrable-domain (default: normalized)",
+                        "name": "comparison",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Per-request deadline in seconds (capped by the server configuration)",
+                        "name": "timeoutSeconds",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "URL that receives a signed JSON webhook when the comparison completes or fails (default: the URL configured in API_KEY_WEBHOOKS for the X-API-Key header)",
+                        "name": "callbackUrl",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        },
+                        "headers": {
+                            "X-Run-ID": {
+                                "type": "string",
+                                "description": "ID of the recorded run, when history is enabled"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    },
+                    "499": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/download/{filename}": {
             "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
                 "description": "Download a file generated by the validation process",
                 "produces": [
                     "application/octet-stream"
@@ -44,29 +230,28 @@ const docTemplate = `{
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/middleware.ErrorResponse"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/middleware.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/validate-emails": {
+        "/emails/validate": {
             "post": {
-                "description": "Upload two CSV/Excel files containing emails and get validation results",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Validate a JSON array of emails without uploading a file, with the same checks as file validations. Returns the detailed result of each email, in order, and aggregate counts.",
                 "consumes": [
-                    "multipart/form-data"
+                    "application/json"
                 ],
                 "produces": [
                     "application/json"
@@ -74,56 +259,1852 @@ const docTemplate = `{
                 "tags": [
                     "emails"
                 ],
-                "summary": "Validate emails from two files",
+                "summary": "Validate a list of emails",
                 "parameters": [
                     {
-                        "type": "file",
-                        "description": "First CSV/Excel file containing emails",
-                        "name": "firstFile",
-                        "in": "formData",
-                        "required": true
+                        "description": "Emails to validate (at most MAX_BATCH_EMAILS)",
+                        "name": "emails",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "string"
+                            }
+                        }
                     },
                     {
-                        "type": "file",
-                        "description": "Second CSV/Excel file containing emails",
-                        "name": "secondFile",
-                        "in": "formData",
+                        "type": "integer",
+                        "description": "Per-request deadline in seconds (capped by the server configuration)",
+                        "name": "timeoutSeconds",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.EmailListResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/emails/{email}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Validate a single email with the same checks as file validations, and explain the result: the normalization rules applied, the checks performed with their outcomes, and likely fixes such as typos of common mail domains",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "emails"
+                ],
+                "summary": "Explain the validation of an email",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Email to look up, URL-encoded",
+                        "name": "email",
+                        "in": "path",
                         "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.EmailLookup"
+                        }
+                    }
+                }
+            }
+        },
+        "/runs": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List recorded validation runs, newest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "runs"
+                ],
+                "summary": "List past validation runs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only runs started at or after this time (RFC 3339 or YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Output format (csv or excel, default: csv)",
-                        "name": "outputFormat",
-                        "in": "formData"
+                        "description": "Only runs started at or before this time (RFC 3339 or YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only runs with this status (completed, failed or cancelled)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only runs with an input file whose name contains this text",
+                        "name": "fileName",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only runs with an input file with this SHA-256 hash",
+                        "name": "sha256",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of runs to return (default 50, max 500)",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "file"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/services.Run"
+                            }
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/middleware.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "503": {
+                        "description": "Service Unavailable",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/middleware.ErrorResponse"
                         }
                     }
                 }
             }
+        },
+        "/runs/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get a recorded validation run with its per-entry results",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "runs"
+                ],
+                "summary": "Get a past validation run",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Run ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entries of this category (Matching, Missing in First File or Missing in Second File)",
+                        "name": "category",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Include per-entry results (default true)",
+                        "name": "entries",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.RunDetails"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/runs/{id}/delta": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Compare a recorded run with a previous run or an uploaded previous report, listing the emails\nwhose category or validity changed, e.g. \"missing in second → matching\" or \"became invalid\"",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "runs"
+                ],
+                "summary": "Compare a run with a previous run",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Run ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the previous run (default: the most recent completed run before this one of the same kind and comparison strategy)",
+                        "name": "previous",
+                        "in": "query"
+                    },
+                    {
+                        "type": "file",
+                        "description": "Previous CSV/Excel validation report to compare with instead of a recorded run",
+                        "name": "previousReport",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "json (default, returned in the response), csv or excel (returned as a file)",
+                        "name": "outputFormat",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.DeltaResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Compare a recorded run with a previous run or an uploaded previous report, listing the emails\nwhose category or validity changed, e.g. \"missing in second → matching\" or \"became invalid\"",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "runs"
+                ],
+                "summary": "Compare a run with a previous run",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Run ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the previous run (default: the most recent completed run before this one of the same kind and comparison strategy)",
+                        "name": "previous",
+                        "in": "query"
+                    },
+                    {
+                        "type": "file",
+                        "description": "Previous CSV/Excel validation report to compare with instead of a recorded run",
+                        "name": "previousReport",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "json (default, returned in the response), csv or excel (returned as a file)",
+                        "name": "outputFormat",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.DeltaResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/runs/{id}/domains": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get the counts of a recorded run by registrable domain (per the Public Suffix List), e.g. to find\nwhich customer companies are missing from Odoo",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "runs"
+                ],
+                "summary": "Get the domain breakdown of a past run",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Run ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only domains that contain this text",
+                        "name": "domain",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only domains with emails missing in the first file, the second file or either (first, second or any)",
+                        "name": "missing",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort by total (default), valid, matching, missingInFirst, missingInSecond, keyMatches, probableMatches, duplicates or domain",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of domains to return",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/services.DomainStats"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/runs/{id}/report": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Download the report file kept for a recorded validation run",
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "runs"
+                ],
+                "summary": "Download the report of a past run",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Run ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/runs/{id}/webhooks": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get the log of the completion webhooks sent for a recorded run, with every attempt and its outcome",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "runs"
+                ],
+                "summary": "Get the webhook deliveries of a past run",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Run ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/services.WebhookDelivery"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/validate-emails": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Upload two CSV/Excel files containing emails and get validation results",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "emails"
+                ],
+                "summary": "Validate emails from two files",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "First file containing emails: CSV, TSV, Excel, JSON, NDJSON, text or a ZIP archive of those",
+                        "name": "firstFile",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Second file containing emails, in any of the formats of firstFile",
+                        "name": "secondFile",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Output format: csv, excel, json, ndjson, markdown, html, odoo-csv or odoo-xml (default: csv)",
+                        "name": "outputFormat",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Column mapping of the odoo-csv and odoo-xml formats, e.g. name=Full Name,phone=Mobile (default: server configuration)",
+                        "name": "odooMapping",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Email column of the first file: header name, letter or 1-based index (default: first column)",
+                        "name": "firstColumn",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Worksheet of the first file when it is an Excel file: name or 1-based index (default: first sheet)",
+                        "name": "firstSheet",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Email column of the second file: header name, letter or 1-based index (default: first column)",
+                        "name": "secondColumn",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Worksheet of the second file when it is an Excel file: name or 1-based index (default: first sheet)",
+                        "name": "secondSheet",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Path of the emails in the first file when it is a JSON or NDJSON file, e.g. $.contacts[*].email (default: the firstColumn key, else every string containing @)",
+                        "name": "firstJsonPath",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Path of the emails in the second file when it is a JSON or NDJSON file",
+                        "name": "secondJsonPath",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Field delimiter of the first file when it is a CSV or TSV file: a character or comma, semicolon, tab or pipe (default: detected)",
+                        "name": "firstDelimiter",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Quote style of the first file when it is a CSV or TSV file: double or none (default: detected)",
+                        "name": "firstQuote",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Character encoding of the first file when it is a CSV, TSV or text file, e.g. utf-8, utf-16le or windows-1258 (default: detected)",
+                        "name": "firstEncoding",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Field delimiter of the second file when it is a CSV or TSV file (default: detected)",
+                        "name": "secondDelimiter",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Quote style of the second file when it is a CSV or TSV file (default: detected)",
+                        "name": "secondQuote",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Character encoding of the second file when it is a CSV, TSV or text file (default: detected)",
+                        "name": "secondEncoding",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Whether the first row of the first file (after skipped rows) is a header: true, false or auto (default: auto, a header unless the row has an email)",
+                        "name": "firstHasHeader",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Leading rows of the first file to ignore before the header, e.g. a title banner (CSV, TSV and Excel files)",
+                        "name": "firstSkipRows",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Whether the first row of the second file (after skipped rows) is a header: true, false or auto",
+                        "name": "secondHasHeader",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Leading rows of the second file to ignore before the header",
+                        "name": "secondSkipRows",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Skip malformed rows of CSV and TSV files, such as stray quotes or a wrong number of fields, and list them as parse errors instead of failing (default: false)",
+                        "name": "lenient",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Malformed rows allowed per file in lenient mode before the run fails (default: server configuration)",
+                        "name": "maxParseErrors",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Key the emails are compared on: raw, case-insensitive, normalized, domain or registrable-domain (default: normalized)",
+                        "name": "comparison",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Secondary keys that pair records whose email changed, tried in order on the emails that did not match, e.g. Full Name+Phone;Customer Code=ref. Columns are joined with +, keys separated by ; and a column named differently in the second file is given as first=second",
+                        "name": "matchKeys",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Similarity from 0 to 1 from which emails missing in either file are paired as probable matches, comparing local parts within the same registrable domain; 0 disables (default: server configuration)",
+                        "name": "probableMatchThreshold",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Per-request deadline in seconds (capped by the server configuration)",
+                        "name": "timeoutSeconds",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "URL that receives a signed JSON webhook when the run completes or fails (default: the URL configured in API_KEY_WEBHOOKS for the X-API-Key header)",
+                        "name": "callbackUrl",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        },
+                        "headers": {
+                            "X-Run-ID": {
+                                "type": "string",
+                                "description": "ID of the recorded run, when history is enabled"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    },
+                    "499": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/view-links/{filename}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Create a signed link that opens an HTML report in the browser without the X-API-Key header until it expires",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "files"
+                ],
+                "summary": "Create a view link for an HTML report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "File name of an HTML report",
+                        "name": "filename",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.ViewLink"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/view/{filename}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Show an HTML report generated by the validation process in the browser instead of downloading it",
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "files"
+                ],
+                "summary": "View an HTML report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "File name of an HTML report",
+                        "name": "filename",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Expiry of a signed view link, in Unix seconds",
+                        "name": "expires",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Signature of a signed view link, which replaces the API key",
+                        "name": "signature",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "handlers.RunDetails": {
+            "type": "object",
+            "properties": {
+                "entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.RunEntry"
+                    }
+                },
+                "error": {
+                    "type": "string"
+                },
+                "finishedAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "inputs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.RunInput"
+                    }
+                },
+                "options": {
+                    "$ref": "#/definitions/services.ValidationOptions"
+                },
+                "reportFile": {
+                    "type": "string"
+                },
+                "reportURL": {
+                    "type": "string"
+                },
+                "sources": {
+                    "description": "Sources is the summary of an N-way comparison, whose inputs are the labelled sources",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/services.MultiSourceSummary"
+                        }
+                    ]
+                },
+                "startedAt": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "summary": {
+                    "$ref": "#/definitions/services.ValidationSummary"
+                }
+            }
+        },
+        "middleware.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Code is a stable identifier of the error, e.g. parse_error",
+                    "type": "string",
+                    "example": "parse_error"
+                },
+                "details": {
+                    "description": "Details help fixing the request, e.g. the file and location of a parse error",
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "message": {
+                    "type": "string",
+                    "example": "cannot parse contacts.csv on line 3, column 7: bare \" in non-quoted-field"
+                },
+                "requestId": {
+                    "description": "RequestID matches the X-Request-ID header, for finding the request in the logs",
+                    "type": "string",
+                    "example": "4f9c2a7e1b3d4c5a8e6f0b1c2d3e4f5a"
+                }
+            }
+        },
+        "services.CSVDialect": {
+            "type": "object",
+            "properties": {
+                "bom": {
+                    "description": "BOM reports whether the file starts with a byte order mark",
+                    "type": "boolean"
+                },
+                "delimiter": {
+                    "description": "Delimiter is the field separator, e.g. \",\", \";\" or \"\\t\"",
+                    "type": "string"
+                },
+                "detected": {
+                    "description": "Detected lists the settings that were detected rather than given as options",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "encoding": {
+                    "description": "Encoding is the character encoding, e.g. \"utf-8\", \"utf-16le\" or \"windows-1258\"",
+                    "type": "string"
+                },
+                "quote": {
+                    "description": "Quote is the quote style, QuoteDouble or QuoteNone",
+                    "type": "string"
+                }
+            }
+        },
+        "services.CombinationCount": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "sources": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "services.ComparisonStrategy": {
+            "type": "string",
+            "enum": [
+                "raw",
+                "case-insensitive",
+                "normalized",
+                "domain",
+                "registrable-domain"
+            ],
+            "x-enum-varnames": [
+                "ComparisonRaw",
+                "ComparisonCaseInsensitive",
+                "ComparisonNormalized",
+                "ComparisonDomain",
+                "ComparisonRegistrableDomain"
+            ]
+        },
+        "services.DeltaEntry": {
+            "type": "object",
+            "properties": {
+                "currentCategory": {
+                    "type": "string"
+                },
+                "currentValid": {
+                    "type": "boolean"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "normalizedEmail": {
+                    "type": "string"
+                },
+                "previousCategory": {
+                    "type": "string"
+                },
+                "previousValid": {
+                    "type": "boolean"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "transition": {
+                    "type": "string"
+                },
+                "validityChange": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.DeltaResult": {
+            "type": "object",
+            "properties": {
+                "current": {
+                    "type": "string"
+                },
+                "entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.DeltaEntry"
+                    }
+                },
+                "fileName": {
+                    "type": "string"
+                },
+                "outputFileURL": {
+                    "type": "string"
+                },
+                "previous": {
+                    "type": "string"
+                },
+                "summary": {
+                    "$ref": "#/definitions/services.DeltaSummary"
+                }
+            }
+        },
+        "services.DeltaSummary": {
+            "type": "object",
+            "properties": {
+                "added": {
+                    "type": "integer"
+                },
+                "becameInvalid": {
+                    "type": "integer"
+                },
+                "becameValid": {
+                    "type": "integer"
+                },
+                "currentTotal": {
+                    "type": "integer"
+                },
+                "newlyMatching": {
+                    "type": "integer"
+                },
+                "noLongerMatching": {
+                    "type": "integer"
+                },
+                "previousTotal": {
+                    "type": "integer"
+                },
+                "removed": {
+                    "type": "integer"
+                },
+                "transitions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.TransitionCount"
+                    }
+                },
+                "unchanged": {
+                    "type": "integer"
+                }
+            }
+        },
+        "services.DomainStats": {
+            "type": "object",
+            "properties": {
+                "domain": {
+                    "description": "Domain is the registrable domain per the Public Suffix List, e.g. \"corp.vn\" for both\n\"corp.vn\" and \"mail.corp.vn\", or \"(none)\" for emails without a domain",
+                    "type": "string"
+                },
+                "duplicates": {
+                    "description": "Duplicates counts the repeated occurrences of an email in the same file",
+                    "type": "integer"
+                },
+                "keyMatches": {
+                    "type": "integer"
+                },
+                "matching": {
+                    "type": "integer"
+                },
+                "missingInFirst": {
+                    "type": "integer"
+                },
+                "missingInSecond": {
+                    "type": "integer"
+                },
+                "probableMatches": {
+                    "type": "integer"
+                },
+                "total": {
+                    "description": "Total counts the compared emails of the domain, so it is the sum of Matching,\nMissingInFirst, MissingInSecond, KeyMatches and ProbableMatches; Valid counts the valid ones",
+                    "type": "integer"
+                },
+                "valid": {
+                    "type": "integer"
+                }
+            }
+        },
+        "services.EmailListResult": {
+            "type": "object",
+            "properties": {
+                "results": {
+                    "description": "Results are in the order of the list, one per email",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/utils.EmailValidationResult"
+                    }
+                },
+                "summary": {
+                    "$ref": "#/definitions/services.EmailListSummary"
+                }
+            }
+        },
+        "services.EmailListSummary": {
+            "type": "object",
+            "properties": {
+                "disposable": {
+                    "type": "integer"
+                },
+                "domainCheck": {
+                    "description": "DomainCheck reports whether email domains were resolved, as in file validations",
+                    "type": "boolean"
+                },
+                "invalid": {
+                    "type": "integer"
+                },
+                "processingTimeSeconds": {
+                    "type": "number"
+                },
+                "reasons": {
+                    "description": "Reasons counts the invalid emails by reason code",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "unique": {
+                    "description": "Unique is the number of distinct normalized emails",
+                    "type": "integer"
+                },
+                "valid": {
+                    "type": "integer"
+                }
+            }
+        },
+        "services.EmailLookup": {
+            "type": "object",
+            "properties": {
+                "checks": {
+                    "description": "Checks lists the checks performed, in order; validation stops at the first failed check,\nexcept CheckNotDisposable, which only flags the email",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/utils.EmailCheck"
+                    }
+                },
+                "normalization": {
+                    "description": "Normalization lists the normalization rules that changed the email, in the order applied",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/utils.NormalizationStep"
+                    }
+                },
+                "result": {
+                    "$ref": "#/definitions/utils.EmailValidationResult"
+                },
+                "suggestions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.EmailSuggestion"
+                    }
+                }
+            }
+        },
+        "services.EmailSuggestion": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "description": "Email is the corrected address, when the fix can be applied automatically",
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.ExtractOptions": {
+            "type": "object",
+            "properties": {
+                "column": {
+                    "description": "Column selects the email column by header name, letter (\"B\") or 1-based index.\nDefaults to the first column.",
+                    "type": "string"
+                },
+                "delimiter": {
+                    "description": "Delimiter, Quote and Encoding override the detected dialect of CSV and TSV files; Encoding\nalso applies to text files. See ParseDelimiter, QuoteDouble and QuoteNone, and\nlookupEncoding for the accepted values.",
+                    "type": "string"
+                },
+                "encoding": {
+                    "type": "string"
+                },
+                "hasHeader": {
+                    "description": "HasHeader tells whether the first row after the skipped rows of a CSV, TSV or Excel file\nis a header. When nil the row is a header unless it looks like data, i.e. has a cell with \"@\".",
+                    "type": "boolean"
+                },
+                "jsonPath": {
+                    "description": "JSONPath selects the emails of JSON and NDJSON files, e.g. \"$.contacts[*].email\".\nDefaults to the Column key of every element, or to every string that contains \"@\".",
+                    "type": "string"
+                },
+                "lenient": {
+                    "description": "Lenient reads CSV and TSV files with variable field counts and lazy quotes. Rows that\nare still malformed are recorded as parse errors and skipped instead of failing the read.",
+                    "type": "boolean"
+                },
+                "maxParseErrors": {
+                    "description": "MaxParseErrors is the number of malformed rows a lenient read skips before it fails.\nDefaults to the MAX_PARSE_ERRORS configuration.",
+                    "type": "integer"
+                },
+                "quote": {
+                    "type": "string"
+                },
+                "sheet": {
+                    "description": "Sheet selects the Excel worksheet by name or 1-based index. Defaults to the first sheet.\nIt is ignored for other formats.",
+                    "type": "string"
+                },
+                "skipRows": {
+                    "description": "SkipRows is the number of leading rows, such as title banners, to ignore before the header\nor the first data row of CSV, TSV and Excel files. Blank lines of CSV files do not count.",
+                    "type": "integer"
+                }
+            }
+        },
+        "services.InputFile": {
+            "type": "object",
+            "properties": {
+                "dialect": {
+                    "description": "Dialect is the detected or given dialect of CSV and TSV files",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/services.CSVDialect"
+                        }
+                    ]
+                },
+                "file": {
+                    "type": "string"
+                },
+                "format": {
+                    "type": "string"
+                },
+                "headerRow": {
+                    "description": "HeaderRow is the 1-based row of the header of CSV, TSV and Excel files, or 0 without header",
+                    "type": "integer"
+                },
+                "role": {
+                    "description": "Role is the part the file plays in a run, e.g. \"first\", \"second\" or a source label",
+                    "type": "string"
+                },
+                "skippedRows": {
+                    "description": "SkippedRows is the number of leading rows that were not read as data: the rows skipped\nwith SkipRows and the header row",
+                    "type": "integer"
+                }
+            }
+        },
+        "services.MatchKey": {
+            "type": "object",
+            "properties": {
+                "firstColumns": {
+                    "description": "FirstColumns and SecondColumns are the columns of the key in each file, in the same order",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "description": "Name is the key as given, e.g. \"Full Name+Phone\"",
+                    "type": "string"
+                },
+                "secondColumns": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "services.MultiSourceSummary": {
+            "type": "object",
+            "properties": {
+                "combinations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.CombinationCount"
+                    }
+                },
+                "comparisonStrategy": {
+                    "description": "ComparisonStrategy is the key the sources were compared on, e.g. \"normalized\"",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/services.ComparisonStrategy"
+                        }
+                    ]
+                },
+                "inAllSources": {
+                    "type": "integer"
+                },
+                "processingTimeSeconds": {
+                    "type": "number"
+                },
+                "sources": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.SourceSummary"
+                    }
+                },
+                "uniqueEmails": {
+                    "type": "integer"
+                }
+            }
+        },
+        "services.OdooField": {
+            "type": "object",
+            "properties": {
+                "column": {
+                    "type": "string"
+                },
+                "field": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.Run": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "finishedAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "inputs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.RunInput"
+                    }
+                },
+                "options": {
+                    "$ref": "#/definitions/services.ValidationOptions"
+                },
+                "reportFile": {
+                    "type": "string"
+                },
+                "reportURL": {
+                    "type": "string"
+                },
+                "sources": {
+                    "description": "Sources is the summary of an N-way comparison, whose inputs are the labelled sources",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/services.MultiSourceSummary"
+                        }
+                    ]
+                },
+                "startedAt": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "summary": {
+                    "$ref": "#/definitions/services.ValidationSummary"
+                }
+            }
+        },
+        "services.RunEntry": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "type": "string"
+                },
+                "displayName": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "isDisposable": {
+                    "type": "boolean"
+                },
+                "isValid": {
+                    "type": "boolean"
+                },
+                "location": {
+                    "description": "Location is where the email was read from; MatchedLocation is its location in the other file when it matched",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/services.SourceLocation"
+                        }
+                    ]
+                },
+                "matchedEmail": {
+                    "description": "MatchedEmail and Similarity are the other email of a probable match and how similar it is",
+                    "type": "string"
+                },
+                "matchedKey": {
+                    "description": "MatchedKey is the match key that paired the email with MatchedEmail when the email changed",
+                    "type": "string"
+                },
+                "matchedLocation": {
+                    "$ref": "#/definitions/services.SourceLocation"
+                },
+                "normalizedEmail": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "similarity": {
+                    "type": "number"
+                },
+                "source": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.RunInput": {
+            "type": "object",
+            "properties": {
+                "fileName": {
+                    "type": "string"
+                },
+                "files": {
+                    "description": "Files describes how the input was read, with one file per entry of a ZIP archive",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.InputFile"
+                    }
+                },
+                "role": {
+                    "type": "string"
+                },
+                "sha256": {
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer"
+                }
+            }
+        },
+        "services.SourceLocation": {
+            "type": "object",
+            "properties": {
+                "column": {
+                    "description": "Column is the column letter, e.g. \"B\"",
+                    "type": "string"
+                },
+                "file": {
+                    "type": "string"
+                },
+                "row": {
+                    "description": "Row is the 1-based row number, counting the header row",
+                    "type": "integer"
+                },
+                "sheet": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.SourceSummary": {
+            "type": "object",
+            "properties": {
+                "fileName": {
+                    "type": "string"
+                },
+                "label": {
+                    "type": "string"
+                },
+                "onlyInSource": {
+                    "description": "OnlyInSource is the number of emails no other source contains",
+                    "type": "integer"
+                },
+                "parseErrors": {
+                    "description": "ParseErrors counts the malformed rows skipped by lenient parsing",
+                    "type": "integer"
+                },
+                "skippedRows": {
+                    "description": "SkippedRows counts the leading rows that were not read as data: rows skipped with skipRows and the header",
+                    "type": "integer"
+                },
+                "totalEmails": {
+                    "type": "integer"
+                },
+                "uniqueEmails": {
+                    "type": "integer"
+                },
+                "validEmails": {
+                    "type": "integer"
+                }
+            }
+        },
+        "services.TransitionCount": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "transition": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.ValidationOptions": {
+            "type": "object",
+            "properties": {
+                "callbackUrl": {
+                    "description": "CallbackURL receives a signed webhook when the run completes or fails",
+                    "type": "string"
+                },
+                "comparison": {
+                    "description": "Comparison is the key on which the emails are compared, one of ComparisonStrategies().\nDefaults to ComparisonNormalized.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/services.ComparisonStrategy"
+                        }
+                    ]
+                },
+                "firstFile": {
+                    "description": "FirstFile and SecondFile select the email column and sheet of each input file",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/services.ExtractOptions"
+                        }
+                    ]
+                },
+                "matchKeys": {
+                    "description": "MatchKeys are the secondary keys, such as name and phone, that pair records whose email\nchanged. They are tried in order on the emails that did not match.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.MatchKey"
+                    }
+                },
+                "odooMapping": {
+                    "description": "OdooMapping is the column mapping of the odoo-csv and odoo-xml formats. Defaults to DefaultOdooMapping().",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.OdooField"
+                    }
+                },
+                "outputFormat": {
+                    "description": "OutputFormat is the report format, one of ReportFormats(), e.g. \"csv\", \"excel\" or \"odoo-csv\"",
+                    "type": "string"
+                },
+                "outputPath": {
+                    "description": "OutputPath overrides the report location. By default a timestamped file is created in the temp directory.",
+                    "type": "string"
+                },
+                "probableMatchThreshold": {
+                    "description": "ProbableMatchThreshold is the similarity from which emails missing in either file are paired\nas probable matches; 0 disables probable matching. Defaults to the server configuration.",
+                    "type": "number"
+                },
+                "secondFile": {
+                    "$ref": "#/definitions/services.ExtractOptions"
+                }
+            }
+        },
+        "services.ValidationSummary": {
+            "type": "object",
+            "properties": {
+                "comparisonStrategy": {
+                    "description": "ComparisonStrategy is the key the emails were compared on, e.g. \"normalized\"",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/services.ComparisonStrategy"
+                        }
+                    ]
+                },
+                "disposableEmailsCount": {
+                    "type": "integer"
+                },
+                "keyMatchCount": {
+                    "description": "KeyMatchCount is the number of emails matched by another key because they changed, which\nare not counted as missing",
+                    "type": "integer"
+                },
+                "matchingCount": {
+                    "type": "integer"
+                },
+                "missingInFirstCount": {
+                    "type": "integer"
+                },
+                "missingInSecondCount": {
+                    "type": "integer"
+                },
+                "parseErrorsFirstFile": {
+                    "description": "ParseErrorsFirstFile and ParseErrorsSecondFile count the malformed rows of each file\nskipped by lenient parsing",
+                    "type": "integer"
+                },
+                "parseErrorsSecondFile": {
+                    "type": "integer"
+                },
+                "probableMatchCount": {
+                    "description": "ProbableMatchCount is the number of probable matches, whose emails are not counted as\nmissing; ProbableMatchThreshold is the similarity they needed, 0 when disabled",
+                    "type": "integer"
+                },
+                "probableMatchThreshold": {
+                    "type": "number"
+                },
+                "processingTimeSeconds": {
+                    "type": "number"
+                },
+                "skippedRowsFirstFile": {
+                    "description": "SkippedRowsFirstFile and SkippedRowsSecondFile count the leading rows of each file that\nwere not read as data: rows skipped with skipRows and header rows",
+                    "type": "integer"
+                },
+                "skippedRowsSecondFile": {
+                    "type": "integer"
+                },
+                "totalEmailsFirstFile": {
+                    "type": "integer"
+                },
+                "totalEmailsSecondFile": {
+                    "type": "integer"
+                },
+                "validEmailsFirstFile": {
+                    "type": "integer"
+                },
+                "validEmailsSecondFile": {
+                    "type": "integer"
+                }
+            }
+        },
+        "services.ViewLink": {
+            "type": "object",
+            "properties": {
+                "expiresAt": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.WebhookAttempt": {
+            "type": "object",
+            "properties": {
+                "at": {
+                    "type": "string"
+                },
+                "durationMs": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "statusCode": {
+                    "type": "integer"
+                }
+            }
+        },
+        "services.WebhookDelivery": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.WebhookAttempt"
+                    }
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "event": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "utils.EmailCheck": {
+            "type": "object",
+            "properties": {
+                "detail": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "outcome": {
+                    "type": "string"
+                }
+            }
+        },
+        "utils.EmailValidationResult": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "isDisposable": {
+                    "type": "boolean"
+                },
+                "isValid": {
+                    "type": "boolean"
+                },
+                "normalizedEmail": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "reasonCode": {
+                    "description": "ReasonCode is the stable code of Reason, one of the Reason constants",
+                    "type": "string"
+                }
+            }
+        },
+        "utils.NormalizationStep": {
+            "type": "object",
+            "properties": {
+                "after": {
+                    "type": "string"
+                },
+                "before": {
+                    "type": "string"
+                },
+                "rule": {
+                    "type": "string"
+                }
+            }
+        }
+    },
+    "securityDefinitions": {
+        "ApiKeyAuth": {
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
         }
     }
 }`
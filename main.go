@@ -1,15 +1,26 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"google.golang.org/grpc"
 	"log"
+	"net"
+	"net/http"
 	"os"
-	"path/filepath"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"ness-to-odoo-golang-validation-api-tool/api/handlers"
 	"ness-to-odoo-golang-validation-api-tool/api/middleware"
+	"ness-to-odoo-golang-validation-api-tool/api/rpc"
+	"ness-to-odoo-golang-validation-api-tool/api/services"
+	"ness-to-odoo-golang-validation-api-tool/cli"
+	"ness-to-odoo-golang-validation-api-tool/config"
 	_ "ness-to-odoo-golang-validation-api-tool/docs" // Import generated swagger docs
 	"ness-to-odoo-golang-validation-api-tool/utils"
 )
@@ -19,9 +30,20 @@ import (
 // @description API for validating and comparing emails from two different sources
 // @host localhost:8080
 // @BasePath /api/v1
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
 func main() {
+	// Run a command-line subcommand instead of the server when one is given
+	if len(os.Args) > 1 && os.Args[1] != "serve" {
+		os.Exit(cli.Run(os.Args[1:]))
+	}
+
+	// Load configuration from the environment
+	cfg := config.Load()
+
 	// Initialize directories
-	dirs := []string{"./temp", "./logs"}
+	dirs := []string{cfg.TempDir, cfg.LogDir, cfg.DataDir}
 	for _, dir := range dirs {
 		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
 			log.Fatalf("Failed to create directory %s: %v", dir, err)
@@ -29,13 +51,20 @@ func main() {
 	}
 
 	// Initialize logger
-	logDir := filepath.Join(".", "logs")
-	if err := utils.InitLogger(utils.DEBUG, logDir, "2006-01-02 15:04:05.000"); err != nil {
+	if err := utils.InitLogger(utils.DEBUG, cfg.LogDir, "2006-01-02 15:04:05.000"); err != nil {
 		log.Fatalf("Failed to initialize logger: %v", err)
 	}
 
 	logger := utils.GetLogger()
 	logger.Info("Email Validation API starting up")
+	utils.SetDomainCheck(cfg.DomainCheckEnabled)
+
+	// Open the validation history database
+	if cfg.HistoryEnabled {
+		if err := services.OpenHistory(services.HistoryPath(), 5*time.Second); err != nil {
+			logger.Fatal("Failed to open validation history: %v", err)
+		}
+	}
 
 	// Set Gin to release mode in production
 	// gin.SetMode(gin.ReleaseMode)
@@ -43,27 +72,150 @@ func main() {
 	// Create a new Gin router with default middleware
 	r := gin.New()
 
+	// Give every request an ID for the logs and error responses
+	r.Use(middleware.RequestID())
+
 	// Add recovery middleware to handle panics
-	r.Use(gin.Recovery())
+	r.Use(middleware.Recovery())
 
 	// Add custom logger middleware
 	r.Use(middleware.Logger())
 
 	// API v1 routes
-	v1 := r.Group("/api/v1")
+	// Server-wide deadline for API requests; handlers may tighten it per request.
+	// Requests need an API key when API_KEYS is set.
+	v1 := r.Group("/api/v1", middleware.APIKeyAuth(), middleware.Deadline(cfg.ValidationTimeout))
 	{
 		v1.POST("/validate-emails", handlers.ValidateEmails)
+		v1.POST("/compare-sources", handlers.CompareSources)
+		v1.POST("/emails/validate", handlers.ValidateEmailList)
+		v1.GET("/emails/:email", handlers.LookupEmail)
 		v1.GET("/download/:filename", handlers.DownloadFile)
+		v1.GET("/view-links/:filename", handlers.CreateViewLink)
+		v1.GET("/runs", handlers.ListRuns)
+		v1.GET("/runs/:id", handlers.GetRun)
+		v1.GET("/runs/:id/report", handlers.DownloadRunReport)
+		v1.GET("/runs/:id/domains", handlers.RunDomains)
+		v1.GET("/runs/:id/webhooks", handlers.RunWebhooks)
+		v1.GET("/runs/:id/delta", handlers.RunDelta)
+		v1.POST("/runs/:id/delta", handlers.RunDelta)
 	}
 
+	// Browsers open HTML reports through signed view links, as they cannot send the API key header
+	r.GET("/api/v1/view/:filename", middleware.ViewLinkAuth(), middleware.Deadline(cfg.ValidationTimeout), handlers.ViewReport)
+
+	// Liveness and readiness probes
+	r.GET("/healthz", handlers.Healthz)
+	r.GET("/readyz", handlers.Readyz)
+
 	// Swagger documentation
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
-	port := ":8080"
+	port := cfg.Port
 	logger.Info("Server starting on %s", port)
 	logger.Info("Swagger documentation available at http://localhost%s/swagger/index.html", port)
 
-	if err := r.Run(port); err != nil {
-		logger.Fatal("Failed to start server: %v", err)
+	// Request contexts derive from baseCtx so running jobs can be cancelled when draining times out
+	baseCtx, cancelJobs := context.WithCancel(context.Background())
+	defer cancelJobs()
+
+	srv := &http.Server{
+		Addr:        port,
+		Handler:     r,
+		BaseContext: func(net.Listener) context.Context { return baseCtx },
+	}
+
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Fatal("Failed to start server: %v", err)
+		}
+	}()
+
+	// The gRPC server mirrors the REST API on its own port
+	var grpcSrv *grpc.Server
+	if cfg.GRPCPort != "" {
+		lis, err := net.Listen("tcp", cfg.GRPCPort)
+		if err != nil {
+			logger.Fatal("Failed to listen for gRPC on %s: %v", cfg.GRPCPort, err)
+		}
+		grpcSrv = rpc.NewServer()
+		logger.Info("gRPC server starting on %s", cfg.GRPCPort)
+		go func() {
+			if err := grpcSrv.Serve(lis); err != nil {
+				logger.Fatal("Failed to start gRPC server: %v", err)
+			}
+		}()
+	}
+
+	// Wait for an interrupt or termination signal
+	signalCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	<-signalCtx.Done()
+	stop()
+
+	shutdown(srv, grpcSrv, cfg.ShutdownDrainDelay, cfg.ShutdownTimeout, cancelJobs)
+}
+
+// shutdown fails readiness and keeps serving for drainDelay, so load balancers take the server
+// out of rotation, then stops accepting new work, drains running jobs for up to timeout, cancels
+// whatever is still running after that and finally closes the logger
+func shutdown(srv *http.Server, grpcSrv *grpc.Server, drainDelay, timeout time.Duration, cancelJobs context.CancelFunc) {
+	logger := utils.GetLogger()
+	handlers.MarkShuttingDown()
+	if drainDelay > 0 {
+		logger.Info("Shutdown signal received, failing readiness for %s before closing the listeners",
+			utils.FormatDuration(drainDelay))
+		time.Sleep(drainDelay)
+	}
+	logger.Info("Draining %d running job(s) for up to %s", services.ActiveJobs(), utils.FormatDuration(timeout))
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	// gRPC calls drain alongside HTTP requests; the ones still running at the deadline are cancelled
+	grpcStopped := make(chan struct{})
+	if grpcSrv != nil {
+		go func() {
+			grpcSrv.GracefulStop()
+			close(grpcStopped)
+		}()
+	} else {
+		close(grpcStopped)
+	}
+
+	err := srv.Shutdown(ctx)
+	if err == nil {
+		select {
+		case <-grpcStopped:
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
 	}
+	if err != nil {
+		logger.Warn("Graceful shutdown timed out, cancelling %d running job(s): %v", services.ActiveJobs(), err)
+		cancelJobs()
+		if grpcSrv != nil {
+			grpcSrv.Stop()
+		}
+
+		// Give cancelled jobs a moment to remove their partial files
+		cleanupCtx, cleanupCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cleanupCancel()
+		if err := services.WaitForJobs(cleanupCtx); err != nil {
+			logger.Error("%d job(s) still running after cancellation", services.ActiveJobs())
+		}
+	}
+
+	// Webhooks of the last runs are still being delivered in the background
+	webhookCtx, webhookCancel := context.WithTimeout(context.Background(), timeout)
+	defer webhookCancel()
+	if err := services.WaitForWebhooks(webhookCtx); err != nil {
+		logger.Warn("Stopping with webhook deliveries still pending: %v", err)
+	}
+
+	if err := services.CloseHistory(); err != nil {
+		logger.Warn("Failed to close validation history: %v", err)
+	}
+
+	logger.Info("Server stopped")
+	logger.Close()
 }
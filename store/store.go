@@ -0,0 +1,174 @@
+// Package store persists application records in an embedded bbolt database.
+// Values are stored as JSON so that callers keep their own record types.
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// ErrNotFound is returned when a key does not exist
+var ErrNotFound = errors.New("record not found")
+
+// DB is an embedded key-value database organised in buckets
+type DB struct {
+	db *bolt.DB
+}
+
+// Record is a value to be written to a bucket under a key
+type Record struct {
+	Bucket string
+	Key    string
+	Value  interface{}
+}
+
+var (
+	defaultDB *DB
+	mu        sync.RWMutex
+)
+
+// Open opens or creates the database file at path and makes sure the given buckets exist.
+// It waits up to timeout for the file lock held by another process.
+func Open(path string, timeout time.Duration, buckets ...string) (*DB, error) {
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return nil, fmt.Errorf("failed to create database directory: %w", err)
+	}
+
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: timeout})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database %s: %w", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, bucket := range buckets {
+			if _, err := tx.CreateBucketIfNotExists([]byte(bucket)); err != nil {
+				return fmt.Errorf("failed to create bucket %s: %w", bucket, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &DB{db: db}, nil
+}
+
+// Init opens the default database used by the application
+func Init(path string, timeout time.Duration, buckets ...string) error {
+	db, err := Open(path, timeout, buckets...)
+	if err != nil {
+		return err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	defaultDB = db
+	return nil
+}
+
+// Get returns the default database, or nil when it has not been initialized
+func Get() *DB {
+	mu.RLock()
+	defer mu.RUnlock()
+	return defaultDB
+}
+
+// Close closes the default database
+func Close() error {
+	mu.Lock()
+	defer mu.Unlock()
+	if defaultDB == nil {
+		return nil
+	}
+	err := defaultDB.db.Close()
+	defaultDB = nil
+	return err
+}
+
+// Put writes all records in a single transaction
+func (d *DB) Put(records ...Record) error {
+	return d.db.Update(func(tx *bolt.Tx) error {
+		for _, record := range records {
+			bucket := tx.Bucket([]byte(record.Bucket))
+			if bucket == nil {
+				return fmt.Errorf("bucket %s does not exist", record.Bucket)
+			}
+
+			data, err := json.Marshal(record.Value)
+			if err != nil {
+				return fmt.Errorf("failed to encode %s/%s: %w", record.Bucket, record.Key, err)
+			}
+			if err := bucket.Put([]byte(record.Key), data); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Load decodes the value stored under key into value. It returns ErrNotFound when the key does not exist.
+func (d *DB) Load(bucketName, key string, value interface{}) error {
+	return d.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(bucketName))
+		if bucket == nil {
+			return fmt.Errorf("bucket %s does not exist", bucketName)
+		}
+
+		data := bucket.Get([]byte(key))
+		if data == nil {
+			return ErrNotFound
+		}
+		return json.Unmarshal(data, value)
+	})
+}
+
+// ForEachReverse calls fn for every record of the bucket in descending key order until fn returns false.
+// The data passed to fn is only valid during the call.
+func (d *DB) ForEachReverse(bucketName string, fn func(key string, data []byte) (bool, error)) error {
+	return d.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(bucketName))
+		if bucket == nil {
+			return fmt.Errorf("bucket %s does not exist", bucketName)
+		}
+
+		cursor := bucket.Cursor()
+		for k, v := cursor.Last(); k != nil; k, v = cursor.Prev() {
+			next, err := fn(string(k), v)
+			if err != nil {
+				return err
+			}
+			if !next {
+				return nil
+			}
+		}
+		return nil
+	})
+}
+
+// Delete removes the given keys from every listed bucket in a single transaction. Keys that do
+// not exist are ignored.
+func (d *DB) Delete(keys []string, buckets ...string) error {
+	return d.db.Update(func(tx *bolt.Tx) error {
+		for _, bucketName := range buckets {
+			bucket := tx.Bucket([]byte(bucketName))
+			if bucket == nil {
+				return fmt.Errorf("bucket %s does not exist", bucketName)
+			}
+			for _, key := range keys {
+				if err := bucket.Delete([]byte(key)); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
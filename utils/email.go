@@ -1,10 +1,13 @@
 package utils
 
 import (
+	"context"
+	"fmt"
 	"net"
 	"regexp"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -38,6 +41,7 @@ var (
 	domainCache         = NewCache()
 	domainCacheTTL      = 24 * time.Hour // Cache domain validation results for 24 hours
 	emailValidationPool sync.Pool
+	domainCheckEnabled  atomic.Bool // Resolve email domains during validation when set
 )
 
 // EmailValidationResult contains detailed validation results for an email
@@ -47,6 +51,97 @@ type EmailValidationResult struct {
 	IsDisposable    bool   `json:"isDisposable"`
 	NormalizedEmail string `json:"normalizedEmail"`
 	Reason          string `json:"reason,omitempty"`
+	// ReasonCode is the stable code of Reason, one of the Reason constants
+	ReasonCode string `json:"reasonCode,omitempty"`
+}
+
+// Reason codes of invalid emails. ReasonDisposable is never set by ValidateEmailDetailed; the
+// list validation and lookup responses use it for valid emails of a disposable provider.
+const (
+	ReasonEmpty            = "empty"
+	ReasonAtSign           = "at_sign"
+	ReasonDomainUnresolved = "domain_unresolved"
+	ReasonDisposable       = "disposable"
+)
+
+// EmailTrace records how ValidateEmailDetailed reached the result of an email
+type EmailTrace struct {
+	// Normalization lists the normalization rules that changed the email, in the order applied
+	Normalization []NormalizationStep `json:"normalization"`
+	// Checks lists the checks performed, in order; validation stops at the first failed check,
+	// except CheckNotDisposable, which only flags the email
+	Checks []EmailCheck `json:"checks"`
+}
+
+// NormalizationStep is a normalization rule applied to an email
+type NormalizationStep struct {
+	Rule   string `json:"rule"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// EmailCheck is a check performed on an email and its outcome
+type EmailCheck struct {
+	Name    string `json:"name"`
+	Outcome string `json:"outcome"`
+	Detail  string `json:"detail,omitempty"`
+}
+
+// Normalization rules
+const (
+	RuleTrimSpaces   = "trim_spaces"
+	RuleLowercase    = "lowercase"
+	RuleGmailDots    = "gmail_remove_dots"
+	RuleGmailPlusTag = "gmail_remove_plus_tag"
+)
+
+// Email checks and their outcomes
+const (
+	CheckNotEmpty       = "not_empty"
+	CheckSingleAtSign   = "single_at_sign"
+	CheckNotDisposable  = "not_disposable"
+	CheckDomainResolves = "domain_resolves"
+
+	CheckPassed  = "passed"
+	CheckFailed  = "failed"
+	CheckSkipped = "skipped"
+)
+
+// normalized records a normalization rule when it changed the email; a nil trace records nothing
+func (t *EmailTrace) normalized(rule, before, after string) {
+	if t != nil && before != after {
+		t.Normalization = append(t.Normalization, NormalizationStep{Rule: rule, Before: before, After: after})
+	}
+}
+
+// checked records a check; a nil trace records nothing
+func (t *EmailTrace) checked(name string, passed bool, detail string) {
+	if t == nil {
+		return
+	}
+	outcome := CheckFailed
+	if passed {
+		outcome = CheckPassed
+	}
+	t.Checks = append(t.Checks, EmailCheck{Name: name, Outcome: outcome, Detail: detail})
+}
+
+// skipped records a check that was not performed; a nil trace records nothing
+func (t *EmailTrace) skipped(name, detail string) {
+	if t != nil {
+		t.Checks = append(t.Checks, EmailCheck{Name: name, Outcome: CheckSkipped, Detail: detail})
+	}
+}
+
+// SetDomainCheck enables or disables domain lookups in ValidateEmailDetailed
+func SetDomainCheck(enabled bool) {
+	domainCheckEnabled.Store(enabled)
+	GetLogger().Info("Domain check enabled: %t", enabled)
+}
+
+// DomainCheckEnabled reports whether ValidateEmailDetailed resolves email domains
+func DomainCheckEnabled() bool {
+	return domainCheckEnabled.Load()
 }
 
 // IsValidEmail checks if a string is a valid email address
@@ -67,7 +162,21 @@ func init() {
 // ValidateEmailDetailed performs a simplified validation of an email address
 // This version uses object pooling for better performance and skips MX record checks
 func ValidateEmailDetailed(email string) EmailValidationResult {
+	return validateEmailDetailed(email, nil)
+}
+
+// ExplainEmail validates an email like ValidateEmailDetailed and returns the normalization
+// rules applied and the checks performed
+func ExplainEmail(email string) (EmailValidationResult, EmailTrace) {
+	trace := EmailTrace{Normalization: make([]NormalizationStep, 0), Checks: make([]EmailCheck, 0)}
+	result := validateEmailDetailed(email, &trace)
+	return result, trace
+}
+
+// validateEmailDetailed validates an email, recording its steps in trace when it is not nil
+func validateEmailDetailed(email string, trace *EmailTrace) EmailValidationResult {
 	defer LogExecutionTime("ValidateEmailDetailed")()
+	normalizedEmail := normalizeEmail(email, trace)
 	email = strings.TrimSpace(email)
 
 	// Get a result object from the pool
@@ -79,7 +188,7 @@ func ValidateEmailDetailed(email string) EmailValidationResult {
 		Email:           email,
 		IsValid:         false,
 		IsDisposable:    false,
-		NormalizedEmail: NormalizeEmail(email),
+		NormalizedEmail: normalizedEmail,
 	}
 
 	result := *resultPtr // Work with a copy to avoid modifying the pooled object
@@ -87,24 +196,44 @@ func ValidateEmailDetailed(email string) EmailValidationResult {
 	// Fast path for empty emails
 	if email == "" {
 		result.Reason = "Email cannot be empty"
+		result.ReasonCode = ReasonEmpty
+		trace.checked(CheckNotEmpty, false, "")
 		return result
 	}
+	trace.checked(CheckNotEmpty, true, "")
 
 	// Basic check - just verify it contains @ symbol
 	parts := strings.Split(email, "@")
 	if len(parts) != 2 {
 		result.Reason = "Email must contain exactly one @ symbol"
+		result.ReasonCode = ReasonAtSign
+		trace.checked(CheckSingleAtSign, false, fmt.Sprintf("found %d @ symbols", len(parts)-1))
 		return result
 	}
+	trace.checked(CheckSingleAtSign, true, "")
+
+	// Check if domain is disposable - this is a fast map lookup. We don't set result.Reason
+	// here because disposable emails are still valid.
+	if isDisposableDomain(parts[1]) {
+		result.IsDisposable = true
+		trace.checked(CheckNotDisposable, false, fmt.Sprintf("%s is a disposable email provider; the email stays valid", parts[1]))
+		GetLogger().Debug("Email %s has disposable domain %s", email, parts[1])
+	} else {
+		trace.checked(CheckNotDisposable, true, "")
+	}
 
-	//domain := parts[1]
-
-	// Check if domain is disposable - this is a fast map lookup
-	//if isDisposableDomain(domain) {
-	//	result.IsDisposable = true
-	//	GetLogger().Debug("Email %s has disposable domain %s", email, domain)
-	//	// We don't set result.Reason here because disposable emails are still valid
-	//}
+	// Resolve the domain only when enabled, since it requires network access
+	if !domainCheckEnabled.Load() {
+		trace.skipped(CheckDomainResolves, "domain lookups are disabled (DOMAIN_CHECK_ENABLED)")
+	} else if !hasMXRecordCached(parts[1]) {
+		result.Reason = "Email domain does not resolve"
+		result.ReasonCode = ReasonDomainUnresolved
+		trace.checked(CheckDomainResolves, false, fmt.Sprintf("%s has no DNS records", parts[1]))
+		GetLogger().Debug("Email %s has an unresolvable domain", email)
+		return result
+	} else {
+		trace.checked(CheckDomainResolves, true, fmt.Sprintf("%s resolves", parts[1]))
+	}
 
 	// All emails are considered valid as long as they have an @ symbol
 	result.IsValid = true
@@ -112,13 +241,15 @@ func ValidateEmailDetailed(email string) EmailValidationResult {
 	return result
 }
 
-// ValidateEmailsBatch validates multiple emails concurrently for better performance
-func ValidateEmailsBatch(emails []string) []EmailValidationResult {
+// ValidateEmailsBatch validates multiple emails concurrently for better performance.
+// It stops handing out work as soon as ctx is cancelled and returns the context error.
+func ValidateEmailsBatch(ctx context.Context, emails []string) ([]EmailValidationResult, error) {
 	defer LogExecutionTime("ValidateEmailsBatch")()
 	logger := GetLogger()
 	logger.Info("Starting batch validation of %d emails", len(emails))
 
 	results := make([]EmailValidationResult, len(emails))
+	var processed int64
 
 	// Use a worker pool to process emails concurrently
 	workerCount := min(len(emails), 10) // Limit to 10 workers max
@@ -136,7 +267,12 @@ func ValidateEmailsBatch(emails []string) []EmailValidationResult {
 			processedCount := 0
 
 			for idx := range jobs {
+				// Drain remaining jobs without doing the work once cancelled
+				if ctx.Err() != nil {
+					continue
+				}
 				results[idx] = ValidateEmailDetailed(emails[idx])
+				atomic.AddInt64(&processed, 1)
 				processedCount++
 			}
 
@@ -146,14 +282,25 @@ func ValidateEmailsBatch(emails []string) []EmailValidationResult {
 
 	// Send jobs to workers
 	logger.Debug("Sending %d jobs to worker pool", len(emails))
+send:
 	for i := range emails {
-		jobs <- i
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break send
+		}
 	}
 	close(jobs)
 
 	// Wait for all workers to finish
 	wg.Wait()
 
+	if err := ctx.Err(); err != nil {
+		done := atomic.LoadInt64(&processed)
+		logger.Warn("Batch validation cancelled after %d/%d emails: %v", done, len(emails), err)
+		return nil, fmt.Errorf("batch validation stopped after %d/%d emails: %w", done, len(emails), err)
+	}
+
 	// Count validation results
 	validCount := 0
 	disposableCount := 0
@@ -169,21 +316,30 @@ func ValidateEmailsBatch(emails []string) []EmailValidationResult {
 	logger.Info("Batch validation completed: %d/%d valid, %d disposable",
 		validCount, len(emails), disposableCount)
 
-	return results
+	return results, nil
 }
 
 // NormalizeEmail normalizes an email address by trimming spaces and converting to lowercase
 func NormalizeEmail(email string) string {
-	email = strings.TrimSpace(email)
-	email = strings.ToLower(email)
+	return normalizeEmail(email, nil)
+}
+
+// normalizeEmail normalizes an email, recording the rules applied in trace when it is not nil
+func normalizeEmail(email string, trace *EmailTrace) string {
+	trimmed := strings.TrimSpace(email)
+	trace.normalized(RuleTrimSpaces, email, trimmed)
+	email = strings.ToLower(trimmed)
+	trace.normalized(RuleLowercase, trimmed, email)
 
 	// Handle Gmail's dot-ignoring feature
 	parts := strings.Split(email, "@")
 	if len(parts) == 2 && parts[1] == "gmail.com" {
 		// Remove dots from username part for Gmail
 		username := strings.Replace(parts[0], ".", "", -1)
+		trace.normalized(RuleGmailDots, email, username+"@gmail.com")
 		// Remove anything after + in username
 		if plusIndex := strings.Index(username, "+"); plusIndex > 0 {
+			trace.normalized(RuleGmailPlusTag, username+"@gmail.com", username[:plusIndex]+"@gmail.com")
 			username = username[:plusIndex]
 		}
 		return username + "@gmail.com"
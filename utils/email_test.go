@@ -0,0 +1,39 @@
+package utils
+
+import "testing"
+
+func TestExplainEmailDisposable(t *testing.T) {
+	tests := []struct {
+		email          string
+		wantValid      bool
+		wantDisposable bool
+		wantReasonCode string
+		wantOutcome    string
+	}{
+		{email: "ann@example.com", wantValid: true, wantOutcome: CheckPassed},
+		{email: "ann@Mailinator.com", wantValid: true, wantDisposable: true, wantOutcome: CheckFailed},
+		{email: "ann.example.com", wantReasonCode: ReasonAtSign},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.email, func(t *testing.T) {
+			result, trace := ExplainEmail(tt.email)
+			if result.IsValid && result.Reason != "" {
+				t.Errorf("ExplainEmail(%q) reason = %q, want none for a valid email", tt.email, result.Reason)
+			}
+			if result.IsValid != tt.wantValid || result.IsDisposable != tt.wantDisposable || result.ReasonCode != tt.wantReasonCode {
+				t.Errorf("ExplainEmail(%q) = valid %t, disposable %t, reason code %q; want %t, %t, %q", tt.email,
+					result.IsValid, result.IsDisposable, result.ReasonCode, tt.wantValid, tt.wantDisposable, tt.wantReasonCode)
+			}
+			outcome := ""
+			for _, check := range trace.Checks {
+				if check.Name == CheckNotDisposable {
+					outcome = check.Outcome
+				}
+			}
+			if outcome != tt.wantOutcome {
+				t.Errorf("%s outcome = %q, want %q", CheckNotDisposable, outcome, tt.wantOutcome)
+			}
+		})
+	}
+}
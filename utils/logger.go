@@ -59,6 +59,18 @@ func InitLogger(level LogLevel, logDir string, timeFormat string) error {
 	return err
 }
 
+// InitConsoleLogger initializes the default logger to write only to w, without a log file.
+// The command-line mode uses it so that log lines do not mix with command output on stdout.
+func InitConsoleLogger(level LogLevel, w io.Writer, timeFormat string) {
+	once.Do(func() {
+		defaultLogger = &Logger{
+			level:      level,
+			logger:     log.New(w, "", 0),
+			timeFormat: timeFormat,
+		}
+	})
+}
+
 // initDefaultLogger creates and initializes the default logger
 func initDefaultLogger(level LogLevel, logDir string, timeFormat string) error {
 	// Create log directory if it doesn't exist